@@ -0,0 +1,79 @@
+package main
+
+import (
+	"strings"
+	"time"
+)
+
+// countryTimezones places a recipient country's banking day. Countries
+// not listed are treated as UTC.
+var countryTimezones = map[string]string{
+	"US": "America/New_York",
+	"MX": "America/Mexico_City",
+	"GB": "Europe/London",
+	"DE": "Europe/Berlin",
+	"FR": "Europe/Paris",
+	"ES": "Europe/Madrid",
+	"IN": "Asia/Kolkata",
+	"PH": "Asia/Manila",
+	"JP": "Asia/Tokyo",
+	"KE": "Africa/Nairobi",
+	"GH": "Africa/Accra",
+}
+
+func countryLocation(country string) *time.Location {
+	if name, ok := countryTimezones[strings.ToUpper(country)]; ok {
+		if loc, err := time.LoadLocation(name); err == nil {
+			return loc
+		}
+	}
+	return time.UTC
+}
+
+// EstimateArrival turns a delivery window into a concrete "arrives by"
+// time in the recipient country's timezone. A window of a day or more is
+// counted in whole business days after now, skipping weekends and bank
+// holidays; a shorter window is added to now and, if that lands on a day
+// banks are closed, pushed to the start of the next business day. Business
+// days come from cal, or DefaultHolidayCalendar when cal is nil.
+func EstimateArrival(now time.Time, country string, window time.Duration, cal HolidayCalendar) time.Time {
+	if cal == nil {
+		cal = DefaultHolidayCalendar
+	}
+	local := now.In(countryLocation(country))
+
+	if window < dayDelivery {
+		arrival := local.Add(window)
+		if cal.IsBusinessDay(country, arrival) {
+			return arrival
+		}
+		return nextBusinessDay(cal, country, startOfDay(arrival))
+	}
+
+	arrival := local
+	for days := int((window + dayDelivery - 1) / dayDelivery); days > 0; days-- {
+		arrival = nextBusinessDay(cal, country, arrival)
+	}
+	return arrival
+}
+
+// nextBusinessDay returns the first business day after t, at t's time of day
+func nextBusinessDay(cal HolidayCalendar, country string, t time.Time) time.Time {
+	t = t.AddDate(0, 0, 1)
+	for !cal.IsBusinessDay(country, t) {
+		t = t.AddDate(0, 0, 1)
+	}
+	return t
+}
+
+func startOfDay(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+}
+
+// annotateArrival sets quote.EstimatedArrival from the upper end of its
+// delivery window. Quotes without a usable window are left zero.
+func annotateArrival(quote *RemittanceQuote, country string, now time.Time, cal HolidayCalendar) {
+	if _, max, ok := quote.DeliveryWindow(); ok {
+		quote.EstimatedArrival = EstimateArrival(now, country, max, cal)
+	}
+}
@@ -0,0 +1,144 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+)
+
+// maxBatchConcurrency bounds the SendMoney calls SendBatch runs at once for
+// providers without a bulk endpoint
+const maxBatchConcurrency = 8
+
+// BatchSender is implemented by providers with a bulk transfer endpoint.
+// Results line up with reqs; per-item failures are returned as *BatchError
+// alongside the successful responses.
+type BatchSender interface {
+	SendMoneyBatch(ctx context.Context, reqs []TransactionRequest) ([]*TransactionResponse, error)
+}
+
+// BatchItemError is one failed transfer in a batch
+type BatchItemError struct {
+	Index     int
+	Reference string
+	Err       error
+}
+
+func (e BatchItemError) Error() string {
+	return fmt.Sprintf("transfer %d (%s): %v", e.Index, e.Reference, e.Err)
+}
+
+func (e BatchItemError) Unwrap() error {
+	return e.Err
+}
+
+// BatchError lists the transfers in a batch that failed, by index
+type BatchError struct {
+	Total  int
+	Failed []BatchItemError
+}
+
+func (e *BatchError) Error() string {
+	return fmt.Sprintf("%d of %d transfers failed: %v", len(e.Failed), e.Total, e.Failed[0])
+}
+
+// Unwrap exposes each item's error to errors.Is and errors.As
+func (e *BatchError) Unwrap() []error {
+	errs := make([]error, len(e.Failed))
+	for i, f := range e.Failed {
+		errs[i] = f
+	}
+	return errs
+}
+
+// SendBatch sends many transfers through one provider, e.g. a payroll run.
+// Each request gets the same checks as SendMoneyWithProvider. The provider's
+// bulk endpoint is used when it has one; otherwise sends fan out, at most
+// maxBatchConcurrency at a time.
+//
+// The returned slice lines up with reqs: a nil entry means that transfer
+// was not made, and the *BatchError says why. Entries that are set were
+// accepted by the provider even when the error is non-nil.
+func (rh *RemittanceHub) SendBatch(ctx context.Context, providerName string, reqs []TransactionRequest) ([]*TransactionResponse, error) {
+	provider, err := rh.findProvider(providerName)
+	if err != nil {
+		return nil, err
+	}
+
+	responses := make([]*TransactionResponse, len(reqs))
+	var (
+		mu     sync.Mutex
+		failed []BatchItemError
+	)
+	fail := func(i int, err error) {
+		mu.Lock()
+		defer mu.Unlock()
+		failed = append(failed, BatchItemError{Index: i, Reference: reqs[i].Reference, Err: err})
+	}
+
+	// Checks run up front so a bad item never reaches the provider
+	prepared := make([]TransactionRequest, 0, len(reqs))
+	indexes := make([]int, 0, len(reqs))
+	for i := range reqs {
+		req := reqs[i]
+		if err := rh.checkSend(ctx, provider, &req); err != nil {
+			fail(i, err)
+			continue
+		}
+		prepared = append(prepared, req)
+		indexes = append(indexes, i)
+	}
+
+	if bulk, ok := provider.(BatchSender); ok && len(prepared) > 0 {
+		results, err := bulk.SendMoneyBatch(ctx, prepared)
+		var batchErr *BatchError
+		if err != nil && !errors.As(err, &batchErr) {
+			// The whole call failed; nothing was created
+			for _, i := range indexes {
+				fail(i, err)
+			}
+		} else {
+			for j, tx := range results {
+				if tx == nil {
+					continue
+				}
+				tx.normalizeError()
+				tx.setTotalDebit(prepared[j], provider.GetCapabilities().FeeModels)
+				responses[indexes[j]] = tx
+				rh.metrics.TransferSent(providerName, tx.Status)
+				rh.recordSend(ctx, providerName, prepared[j], tx)
+			}
+			if batchErr != nil {
+				for _, item := range batchErr.Failed {
+					rh.metrics.TransferSent(providerName, StatusFailed)
+					fail(indexes[item.Index], item.Err)
+				}
+			}
+		}
+	} else {
+		sem := make(chan struct{}, maxBatchConcurrency)
+		var wg sync.WaitGroup
+		for j := range prepared {
+			wg.Add(1)
+			sem <- struct{}{}
+			go func(j int) {
+				defer func() { <-sem; wg.Done() }()
+				tx, err := rh.sendWithProvider(ctx, provider, prepared[j])
+				if err != nil {
+					fail(indexes[j], err)
+					return
+				}
+				responses[indexes[j]] = tx
+			}(j)
+		}
+		wg.Wait()
+	}
+
+	if len(failed) > 0 {
+		sort.Slice(failed, func(a, b int) bool { return failed[a].Index < failed[b].Index })
+		return responses, &BatchError{Total: len(reqs), Failed: failed}
+	}
+	return responses, nil
+}
@@ -0,0 +1,111 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// defaultQuoteBudget caps a whole GetQuotes call when the caller's context
+// has no earlier deadline
+const defaultQuoteBudget = 10 * time.Second
+
+// providerBudgetFraction is the share of the remaining budget each provider
+// gets. The rest is headroom to collect, annotate and sort the results before
+// the caller's deadline.
+const providerBudgetFraction = 0.8
+
+// QuoteTimeoutError records a provider dropped from GetQuotes for not
+// answering within its budget
+type QuoteTimeoutError struct {
+	Provider string
+	Err      error
+}
+
+func (e *QuoteTimeoutError) Error() string {
+	return fmt.Sprintf("%s quote timed out: %v", e.Provider, e.Err)
+}
+
+func (e *QuoteTimeoutError) Unwrap() error {
+	return e.Err
+}
+
+// SetQuoteBudget bounds the total time GetQuotes may take. A caller deadline
+// that is sooner still wins; zero disables the hub's own limit.
+func (rh *RemittanceHub) SetQuoteBudget(budget time.Duration) {
+	rh.quoteBudget = budget
+}
+
+func (rh *RemittanceHub) withQuoteBudget(ctx context.Context) (context.Context, context.CancelFunc) {
+	if rh.quoteBudget <= 0 {
+		return context.WithCancel(ctx)
+	}
+	return context.WithTimeout(ctx, rh.quoteBudget)
+}
+
+// providerQuoteContext derives a provider's context with a fraction of the
+// time left on ctx, so one slow provider can't run out the whole deadline
+func providerQuoteContext(ctx context.Context) (context.Context, context.CancelFunc) {
+	deadline, ok := ctx.Deadline()
+	if !ok {
+		return context.WithCancel(ctx)
+	}
+	remaining := time.Until(deadline)
+	return context.WithTimeout(ctx, time.Duration(float64(remaining)*providerBudgetFraction))
+}
+
+// QuoteOptions bounds a single GetQuotesWithOptions call. Zero fields impose
+// no limit beyond the caller's context and the hub's quote budget.
+type QuoteOptions struct {
+	// PerProviderTimeout caps each provider's quote call, retries
+	// included. Providers that run over are dropped.
+	PerProviderTimeout time.Duration
+	// OverallTimeout caps the whole call. When it expires the quotes that
+	// have arrived are returned and the providers still out are dropped.
+	OverallTimeout time.Duration
+}
+
+func (o QuoteOptions) batchContext(ctx context.Context) (context.Context, context.CancelFunc) {
+	if o.OverallTimeout <= 0 {
+		return context.WithCancel(ctx)
+	}
+	return context.WithTimeout(ctx, o.OverallTimeout)
+}
+
+// providerContext bounds one provider's calls by PerProviderTimeout as
+// well as its share of the time left on ctx
+func (o QuoteOptions) providerContext(ctx context.Context) (context.Context, context.CancelFunc) {
+	ctx, cancel := providerQuoteContext(ctx)
+	if o.PerProviderTimeout <= 0 {
+		return ctx, cancel
+	}
+	ctx, cancelTimeout := context.WithTimeout(ctx, o.PerProviderTimeout)
+	return ctx, func() {
+		cancelTimeout()
+		cancel()
+	}
+}
+
+// GetQuotesWithOptions is GetQuotes within the limits in opts, e.g. each
+// provider at most 2s and the whole call at most 5s. Providers dropped for
+// either limit are reported rather than only logged: alongside the quotes
+// that did arrive, the error joins a *QuoteTimeoutError for each of them.
+// Use errors.As to tell this apart from a failed call, which returns no
+// quotes.
+func (rh *RemittanceHub) GetQuotesWithOptions(ctx context.Context, req TransactionRequest, opts QuoteOptions) ([]*RemittanceQuote, error) {
+	quotes, timeouts, err := rh.collectQuotes(ctx, req, opts)
+	if err == nil && len(timeouts) > 0 {
+		errs := make([]error, len(timeouts))
+		for i, timeout := range timeouts {
+			errs[i] = timeout
+		}
+		err = errors.Join(errs...)
+	}
+	if err != nil && len(quotes) == 0 {
+		return nil, err
+	}
+
+	rh.sortQuotes(quotes, req, EffectiveCost)
+	return quotes, err
+}
@@ -0,0 +1,55 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestGetQuotesWithOptionsDropsSlowProviders(t *testing.T) {
+	tests := []struct {
+		name string
+		opts QuoteOptions
+	}{
+		{"per provider", QuoteOptions{PerProviderTimeout: 50 * time.Millisecond}},
+		{"overall", QuoteOptions{OverallTimeout: 50 * time.Millisecond}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			blocking := newBlockingProvider(t)
+			hub := NewRemittanceHub()
+			hub.AddProvider(newSimulatedRemitly())
+			hub.AddProvider(blocking)
+
+			start := time.Now()
+			quotes, err := hub.GetQuotesWithOptions(context.Background(), testRequest(), tt.opts)
+			if elapsed := time.Since(start); elapsed > time.Second {
+				t.Fatalf("took %v; the limit didn't cut the blocked provider off", elapsed)
+			}
+
+			var timeout *QuoteTimeoutError
+			if !errors.As(err, &timeout) || timeout.Provider != blocking.GetName() {
+				t.Fatalf("err = %v, want a QuoteTimeoutError for %s", err, blocking.GetName())
+			}
+			if len(quotes) != 1 || quotes[0].Provider != "Remitly" {
+				t.Fatalf("quotes = %v, want only Remitly's", quotes)
+			}
+		})
+	}
+}
+
+func TestGetQuotesWithOptionsWithinLimits(t *testing.T) {
+	hub := NewRemittanceHub()
+	hub.AddProvider(newSimulatedRemitly())
+	hub.AddProvider(newSimulatedWorldRemit())
+
+	opts := QuoteOptions{PerProviderTimeout: time.Second, OverallTimeout: 2 * time.Second}
+	quotes, err := hub.GetQuotesWithOptions(context.Background(), testRequest(), opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(quotes) != 2 {
+		t.Errorf("got %d quotes, want 2", len(quotes))
+	}
+}
@@ -0,0 +1,378 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// ChaosFault is a kind of misbehaviour ChaosProvider can inject
+type ChaosFault int
+
+const (
+	FaultNone ChaosFault = iota
+	// FaultLatency delays the call by Latency, then makes it normally
+	FaultLatency
+	// FaultStatus fails the call with an APIError carrying one of
+	// StatusCodes, without reaching the provider
+	FaultStatus
+	// FaultDrop fails the call as a connection closed before any response,
+	// without reaching the provider
+	FaultDrop
+	// FaultMalformedJSON makes the call, then discards the result and fails
+	// as if the response body could not be decoded. For sends this is the
+	// ambiguous case: the transfer may exist.
+	FaultMalformedJSON
+)
+
+func (f ChaosFault) String() string {
+	switch f {
+	case FaultLatency:
+		return "latency"
+	case FaultStatus:
+		return "status"
+	case FaultDrop:
+		return "drop"
+	case FaultMalformedJSON:
+		return "malformed-json"
+	}
+	return "none"
+}
+
+// ChaosProvider wraps a provider and makes a share of its calls misbehave,
+// to exercise the hub's retry, fallback and fan-out paths. It lives in a
+// test file so it can never be compiled into a production binary.
+//
+// Every call that reaches a provider (quotes, sends, status, events, rates,
+// limits and refunds) draws from a random source seeded by NewChaosProvider,
+// so a given seed and call sequence injects the same faults each run.
+// Wrapping hides the provider's optional interfaces from the hub, except
+// io.Closer.
+type ChaosProvider struct {
+	RemittanceProvider
+	// Rate is the fraction of calls that misbehave, from 0 to 1
+	Rate float64
+	// Faults are picked from uniformly for each misbehaving call; empty
+	// means all of them
+	Faults []ChaosFault
+	// Latency is the delay FaultLatency adds (default 2s)
+	Latency time.Duration
+	// StatusCodes are picked from uniformly for FaultStatus (default 503)
+	StatusCodes []int
+
+	mu       sync.Mutex
+	random   *rand.Rand
+	injected map[ChaosFault]int
+}
+
+func NewChaosProvider(provider RemittanceProvider, seed int64) *ChaosProvider {
+	return &ChaosProvider{
+		RemittanceProvider: provider,
+		random:             rand.New(rand.NewSource(seed)),
+		injected:           make(map[ChaosFault]int),
+	}
+}
+
+// Injected reports how many times fault has been injected so far
+func (c *ChaosProvider) Injected(fault ChaosFault) int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.injected[fault]
+}
+
+var allChaosFaults = []ChaosFault{FaultLatency, FaultStatus, FaultDrop, FaultMalformedJSON}
+
+// draw decides whether this call misbehaves and how
+func (c *ChaosProvider) draw() (fault ChaosFault, status int) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.random.Float64() >= c.Rate {
+		return FaultNone, 0
+	}
+	faults := c.Faults
+	if len(faults) == 0 {
+		faults = allChaosFaults
+	}
+	fault = faults[c.random.Intn(len(faults))]
+	if fault == FaultStatus {
+		status = http.StatusServiceUnavailable
+		if len(c.StatusCodes) > 0 {
+			status = c.StatusCodes[c.random.Intn(len(c.StatusCodes))]
+		}
+	}
+	c.injected[fault]++
+	return fault, status
+}
+
+// before injects any fault that stops the call from reaching the provider.
+// The fault is returned so FaultMalformedJSON can be applied afterwards.
+func (c *ChaosProvider) before(ctx context.Context, method, operation string) (ChaosFault, error) {
+	fault, status := c.draw()
+	switch fault {
+	case FaultLatency:
+		latency := c.Latency
+		if latency <= 0 {
+			latency = 2 * time.Second
+		}
+		timer := time.NewTimer(latency)
+		defer timer.Stop()
+		select {
+		case <-ctx.Done():
+			return fault, ctx.Err()
+		case <-timer.C:
+		}
+	case FaultStatus:
+		return fault, &APIError{
+			Provider:   c.GetName(),
+			StatusCode: status,
+			Message:    "injected by chaos provider",
+			Header:     http.Header{},
+		}
+	case FaultDrop:
+		return fault, &url.Error{Op: method, URL: "chaos://" + c.GetName() + "/" + operation, Err: io.EOF}
+	}
+	return fault, nil
+}
+
+// malformedJSON is the error a provider returns when a gateway answers
+// with an HTML error page instead of JSON
+func (c *ChaosProvider) malformedJSON(operation string) error {
+	var body interface{}
+	err := json.Unmarshal([]byte("<html><body>502 Bad Gateway</body></html>"), &body)
+	return fmt.Errorf("%s %s: %w", c.GetName(), operation, err)
+}
+
+func (c *ChaosProvider) GetQuote(ctx context.Context, req TransactionRequest) (*RemittanceQuote, error) {
+	fault, err := c.before(ctx, "POST", "quote")
+	if err != nil {
+		return nil, err
+	}
+	quote, err := c.RemittanceProvider.GetQuote(ctx, req)
+	if err == nil && fault == FaultMalformedJSON {
+		return nil, c.malformedJSON("quote")
+	}
+	return quote, err
+}
+
+func (c *ChaosProvider) SendMoney(ctx context.Context, req TransactionRequest) (*TransactionResponse, error) {
+	fault, err := c.before(ctx, "POST", "send")
+	if err != nil {
+		return nil, err
+	}
+	tx, err := c.RemittanceProvider.SendMoney(ctx, req)
+	if err == nil && fault == FaultMalformedJSON {
+		return nil, c.malformedJSON("send")
+	}
+	return tx, err
+}
+
+func (c *ChaosProvider) GetTransactionStatus(ctx context.Context, transactionID string) (*TransactionResponse, error) {
+	fault, err := c.before(ctx, "GET", "status")
+	if err != nil {
+		return nil, err
+	}
+	tx, err := c.RemittanceProvider.GetTransactionStatus(ctx, transactionID)
+	if err == nil && fault == FaultMalformedJSON {
+		return nil, c.malformedJSON("status")
+	}
+	return tx, err
+}
+
+func (c *ChaosProvider) GetTransactionEvents(ctx context.Context, transactionID string) ([]TransactionEvent, error) {
+	fault, err := c.before(ctx, "GET", "events")
+	if err != nil {
+		return nil, err
+	}
+	events, err := c.RemittanceProvider.GetTransactionEvents(ctx, transactionID)
+	if err == nil && fault == FaultMalformedJSON {
+		return nil, c.malformedJSON("events")
+	}
+	return events, err
+}
+
+func (c *ChaosProvider) GetExchangeRates(ctx context.Context, from, to Currency) (*ExchangeRate, error) {
+	fault, err := c.before(ctx, "GET", "rates")
+	if err != nil {
+		return nil, err
+	}
+	rate, err := c.RemittanceProvider.GetExchangeRates(ctx, from, to)
+	if err == nil && fault == FaultMalformedJSON {
+		return nil, c.malformedJSON("rates")
+	}
+	return rate, err
+}
+
+func (c *ChaosProvider) GetCorridorLimits(ctx context.Context, from, to Currency, country string) (min, max float64, err error) {
+	fault, err := c.before(ctx, "GET", "limits")
+	if err != nil {
+		return 0, 0, err
+	}
+	min, max, err = c.RemittanceProvider.GetCorridorLimits(ctx, from, to, country)
+	if err == nil && fault == FaultMalformedJSON {
+		return 0, 0, c.malformedJSON("limits")
+	}
+	return min, max, err
+}
+
+func (c *ChaosProvider) GetExchangeRatesBatch(ctx context.Context, pairs []CurrencyPair) (map[CurrencyPair]*ExchangeRate, error) {
+	fault, err := c.before(ctx, "GET", "rates")
+	if err != nil {
+		return nil, err
+	}
+	rates, err := c.RemittanceProvider.GetExchangeRatesBatch(ctx, pairs)
+	if err == nil && fault == FaultMalformedJSON {
+		return nil, c.malformedJSON("rates")
+	}
+	return rates, err
+}
+
+func (c *ChaosProvider) RefundTransaction(ctx context.Context, transactionID, reason string) (*TransactionResponse, error) {
+	fault, err := c.before(ctx, "POST", "refund")
+	if err != nil {
+		return nil, err
+	}
+	tx, err := c.RemittanceProvider.RefundTransaction(ctx, transactionID, reason)
+	if err == nil && fault == FaultMalformedJSON {
+		return nil, c.malformedJSON("refund")
+	}
+	return tx, err
+}
+
+// Close closes the wrapped provider if it implements io.Closer
+func (c *ChaosProvider) Close() error {
+	if closer, ok := c.RemittanceProvider.(io.Closer); ok {
+		return closer.Close()
+	}
+	return nil
+}
+
+func TestChaosStatusRetriedThroughTransientFaults(t *testing.T) {
+	ctx := context.Background()
+	sim := newSimulatedRemitly()
+	tx, err := sim.SendMoney(ctx, testRequest())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	chaos := NewChaosProvider(sim, 1)
+	chaos.Rate = 0.5
+	chaos.Faults = []ChaosFault{FaultStatus, FaultDrop}
+	hub := NewRemittanceHub()
+	hub.AddProvider(chaos)
+	hub.SetRetryPolicy(RetryPolicy{MaxAttempts: 20, BaseDelay: time.Microsecond, MaxDelay: time.Millisecond})
+
+	for i := 0; i < 20; i++ {
+		if _, err := hub.GetTransactionStatus(ctx, sim.GetName(), tx.TransactionID); err != nil {
+			t.Fatalf("status %d: %v", i, err)
+		}
+	}
+	if chaos.Injected(FaultStatus)+chaos.Injected(FaultDrop) == 0 {
+		t.Fatal("no faults injected; the test exercised nothing")
+	}
+}
+
+func TestChaosMalformedStatusNotRetried(t *testing.T) {
+	ctx := context.Background()
+	sim := newSimulatedRemitly()
+	tx, err := sim.SendMoney(ctx, testRequest())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	chaos := NewChaosProvider(sim, 1)
+	chaos.Rate = 1
+	chaos.Faults = []ChaosFault{FaultMalformedJSON}
+	hub := NewRemittanceHub()
+	hub.AddProvider(chaos)
+	hub.SetRetryPolicy(RetryPolicy{MaxAttempts: 5, BaseDelay: time.Microsecond})
+
+	if _, err := hub.GetTransactionStatus(ctx, sim.GetName(), tx.TransactionID); err == nil {
+		t.Fatal("want the decode error")
+	}
+	if n := chaos.Injected(FaultMalformedJSON); n != 1 {
+		t.Errorf("called %d times, want 1: a body that won't decode isn't retried", n)
+	}
+}
+
+func TestChaosSendFallsBackOnStatusFault(t *testing.T) {
+	ctx := context.Background()
+	req := testRequest()
+	remitly, worldRemit := newSimulatedRemitly(), newSimulatedWorldRemit()
+	chaos := NewChaosProvider(remitly, 1)
+	chaos.Rate = 1
+	chaos.Faults = []ChaosFault{FaultStatus}
+	hub := NewRemittanceHub()
+	hub.AddProvider(chaos)
+	hub.AddProvider(worldRemit)
+
+	quotes := make([]*RemittanceQuote, 0, 2)
+	for _, provider := range []RemittanceProvider{remitly, worldRemit} {
+		quote, err := provider.GetQuote(ctx, req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		quotes = append(quotes, quote)
+	}
+
+	tx, err := hub.SendMoneyWithFallback(ctx, quotes, req)
+	if err != nil {
+		t.Fatalf("fallback: %v", err)
+	}
+	if !strings.HasPrefix(tx.TransactionID, "SIM_WorldRemit_") {
+		t.Errorf("sent as %s, want WorldRemit after Remitly's 503", tx.TransactionID)
+	}
+}
+
+func TestChaosSendStopsWhenTransferMayExist(t *testing.T) {
+	ctx := context.Background()
+	req := testRequest()
+	remitly, worldRemit := newSimulatedRemitly(), newSimulatedWorldRemit()
+	chaos := NewChaosProvider(remitly, 1)
+	chaos.Rate = 1
+	chaos.Faults = []ChaosFault{FaultDrop}
+	hub := NewRemittanceHub()
+	hub.AddProvider(chaos)
+	hub.AddProvider(worldRemit)
+
+	first, err := remitly.GetQuote(ctx, req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	second, err := worldRemit.GetQuote(ctx, req)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = hub.SendMoneyWithFallback(ctx, []*RemittanceQuote{first, second}, req)
+	var fallback *FallbackError
+	if !errors.As(err, &fallback) || len(fallback.Attempts) != 1 {
+		t.Fatalf("err = %v, want a FallbackError with only Remitly tried", err)
+	}
+}
+
+func TestChaosSameSeedSameFaults(t *testing.T) {
+	draws := func() []ChaosFault {
+		chaos := NewChaosProvider(newSimulatedRemitly(), 42)
+		chaos.Rate = 0.5
+		faults := make([]ChaosFault, 50)
+		for i := range faults {
+			faults[i], _ = chaos.draw()
+		}
+		return faults
+	}
+	a, b := draws(), draws()
+	for i := range a {
+		if a[i] != b[i] {
+			t.Fatalf("draw %d: %v vs %v with the same seed", i, a[i], b[i])
+		}
+	}
+}
@@ -0,0 +1,95 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+)
+
+// Close shuts the hub down for good. It stops its background work (rate
+// cache refreshes and SubscribeRates polling, whose channel closes), then
+// closes every registered provider, the reference rate source and the
+// transaction store that implement io.Closer, joining their errors. The hub
+// is unusable afterwards: its providers are unregistered, AddProvider
+// fails with ErrClosed and ReplaceProvider does nothing. Calling Close
+// again does nothing.
+func (rh *RemittanceHub) Close() error {
+	var err error
+	rh.closeOnce.Do(func() {
+		close(rh.done)
+		if rh.rates != nil {
+			rh.rates.close()
+		}
+
+		rh.mu.Lock()
+		providers := rh.providers
+		rh.providers = nil
+		rh.mu.Unlock()
+
+		var errs []error
+		for _, provider := range providers {
+			if closer, ok := provider.(io.Closer); ok {
+				if err := closer.Close(); err != nil {
+					errs = append(errs, fmt.Errorf("%s: %w", provider.GetName(), err))
+				}
+			}
+		}
+		for _, dependency := range []interface{}{rh.reference, rh.store} {
+			if closer, ok := dependency.(io.Closer); ok {
+				if err := closer.Close(); err != nil {
+					errs = append(errs, err)
+				}
+			}
+		}
+		err = errors.Join(errs...)
+	})
+	return err
+}
+
+// closed reports whether Close has been called
+func (rh *RemittanceHub) closed() bool {
+	select {
+	case <-rh.done:
+		return true
+	default:
+		return false
+	}
+}
+
+// Close closes the hub and everything it owns; see RemittanceHub.Close
+func (wrs *WalletRemittanceService) Close() error {
+	return wrs.hub.Close()
+}
+
+// Close releases the provider's idle connections. The provider must not be
+// used afterwards. Providers share a transport by default, so this also
+// drops other providers' idle connections, which they simply redial.
+func (w *WiseProvider) Close() error {
+	w.client.CloseIdleConnections()
+	return nil
+}
+
+// Close releases the provider's idle connections; see WiseProvider.Close
+func (r *RemitlyProvider) Close() error {
+	r.client.CloseIdleConnections()
+	return nil
+}
+
+// Close releases the provider's idle connections; see WiseProvider.Close
+func (wr *WorldRemitProvider) Close() error {
+	wr.client.CloseIdleConnections()
+	return nil
+}
+
+// Close releases the provider's idle connections; see WiseProvider.Close
+func (x *XoomProvider) Close() error {
+	x.client.CloseIdleConnections()
+	return nil
+}
+
+// Close releases the source's idle connections. It must not be used
+// afterwards.
+func (e *ECBReferenceRates) Close() error {
+	e.client.CloseIdleConnections()
+	return nil
+}
@@ -0,0 +1,164 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"sort"
+	"sync"
+	"time"
+)
+
+// QuoteComparison summarises a set of quotes for display ("you save $12
+// vs the most expensive option"). Best and Worst are ranked by effective
+// cost, so a savings figure can be negative when the best option costs
+// more upfront but delivers enough extra to make up for it.
+type QuoteComparison struct {
+	Best   *RemittanceQuote `json:"best"`
+	Worst  *RemittanceQuote `json:"worst"`
+	Quotes int              `json:"quotes"`
+	// CostSavings is the difference in what the sender pays, in the send
+	// currency (NormalizedCost, see cost.go); the percentage is relative to
+	// Worst's cost
+	CostSavings        float64 `json:"cost_savings"`
+	CostSavingsPercent float64 `json:"cost_savings_percent"`
+	// ReceivedGain is Best.ReceivedAmount - Worst.ReceivedAmount in the
+	// receive currency; the percentage is relative to Worst.ReceivedAmount
+	ReceivedGain        float64 `json:"received_gain"`
+	ReceivedGainPercent float64 `json:"received_gain_percent"`
+	// Spreads are max minus min across all quotes, not just Best and Worst
+	CostSpread     float64 `json:"cost_spread"`
+	ReceivedSpread float64 `json:"received_spread"`
+	RateSpread     float64 `json:"rate_spread"`
+}
+
+// CompareQuotes quotes req across providers and summarises the result
+func (rh *RemittanceHub) CompareQuotes(ctx context.Context, req TransactionRequest) (*QuoteComparison, error) {
+	quotes, err := rh.GetQuotes(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	return NewQuoteComparison(quotes)
+}
+
+// NewQuoteComparison summarises quotes, which must have EffectiveCost
+// filled in as GetQuotes does. quotes is not reordered.
+func NewQuoteComparison(quotes []*RemittanceQuote) (*QuoteComparison, error) {
+	if len(quotes) == 0 {
+		return nil, ErrNoQuotes
+	}
+	ranked := append([]*RemittanceQuote(nil), quotes...)
+	sortQuotes(ranked, EffectiveCost)
+
+	c := &QuoteComparison{
+		Best:   ranked[0],
+		Worst:  ranked[len(ranked)-1],
+		Quotes: len(ranked),
+	}
+	c.CostSavings = c.Worst.cost() - c.Best.cost()
+	c.CostSavingsPercent = percentOf(c.CostSavings, c.Worst.cost())
+	c.ReceivedGain = c.Best.ReceivedAmount - c.Worst.ReceivedAmount
+	c.ReceivedGainPercent = percentOf(c.ReceivedGain, c.Worst.ReceivedAmount)
+
+	c.CostSpread = spread(ranked, func(q *RemittanceQuote) float64 { return q.cost() })
+	c.ReceivedSpread = spread(ranked, func(q *RemittanceQuote) float64 { return q.ReceivedAmount })
+	c.RateSpread = spread(ranked, func(q *RemittanceQuote) float64 { return q.ExchangeRate })
+	return c, nil
+}
+
+// percentOf returns part as a percentage of whole, or 0 when whole is 0
+func percentOf(part, whole float64) float64 {
+	if whole == 0 {
+		return 0
+	}
+	return part / whole * 100
+}
+
+func spread(quotes []*RemittanceQuote, value func(*RemittanceQuote) float64) float64 {
+	min, max := value(quotes[0]), value(quotes[0])
+	for _, q := range quotes[1:] {
+		v := value(q)
+		if v < min {
+			min = v
+		}
+		if v > max {
+			max = v
+		}
+	}
+	return max - min
+}
+
+// RateComparison is one provider's exchange rate for a pair, ignoring
+// transfer fees other than any the provider attaches to the rate itself
+type RateComparison struct {
+	Provider   string    `json:"provider"`
+	Rate       float64   `json:"rate"`
+	Fee        float64   `json:"fee"`
+	ValidUntil time.Time `json:"valid_until"`
+	// Derived is set when the provider inverted the reverse pair
+	Derived bool `json:"derived,omitempty"`
+	// MarginPercent is set when the hub has a ReferenceRateProvider
+	MarginPercent float64 `json:"margin_percent,omitempty"`
+}
+
+// CompareRates asks every provider serving from->to for its rate at once
+// and returns them best rate first. Providers that fail are logged and left
+// out, as in GetQuotes; an error is returned only if none answer.
+func (rh *RemittanceHub) CompareRates(ctx context.Context, from, to Currency) ([]RateComparison, error) {
+	pair := CurrencyPair{From: from, To: to}
+	providers := rh.GetAvailableProviders("", "", from, to)
+	if len(providers) == 0 {
+		return nil, fmt.Errorf("%w: %s", ErrNoProvidersForCorridor, pair)
+	}
+
+	ctx, cancel := rh.withQuoteBudget(ctx)
+	defer cancel()
+
+	rates := make([]*ExchangeRate, len(providers))
+	errs := make([]error, len(providers))
+	var wg sync.WaitGroup
+	for i, provider := range providers {
+		wg.Add(1)
+		go func(i int, provider RemittanceProvider) {
+			defer wg.Done()
+			providerCtx, cancel := providerQuoteContext(ctx)
+			defer cancel()
+			errs[i] = rh.retry(providerCtx, func(ctx context.Context) (err error) {
+				rates[i], err = rh.exchangeRate(ctx, provider, from, to)
+				return err
+			})
+		}(i, provider)
+	}
+	wg.Wait()
+
+	midMarket := rh.referenceRate(ctx, TransactionRequest{FromCurrency: from, ToCurrency: to})
+	var comparisons []RateComparison
+	var failed []error
+	for i, provider := range providers {
+		if errs[i] != nil || rates[i] == nil {
+			log.Printf("Error getting %s rate from %s: %v", pair, provider.GetName(), errs[i])
+			failed = append(failed, fmt.Errorf("%s: %w", provider.GetName(), errs[i]))
+			continue
+		}
+		c := RateComparison{
+			Provider:   provider.GetName(),
+			Rate:       rates[i].Rate,
+			Fee:        rates[i].Fee,
+			ValidUntil: rates[i].ValidUntil,
+			Derived:    rates[i].Derived,
+		}
+		if midMarket > 0 {
+			c.MarginPercent = (midMarket - c.Rate) / midMarket * 100
+		}
+		comparisons = append(comparisons, c)
+	}
+	if len(comparisons) == 0 {
+		return nil, fmt.Errorf("%w: no provider returned a rate for %s: %w", ErrNoQuotes, pair, errors.Join(failed...))
+	}
+
+	sort.SliceStable(comparisons, func(i, j int) bool {
+		return comparisons[i].Rate > comparisons[j].Rate
+	})
+	return comparisons, nil
+}
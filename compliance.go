@@ -0,0 +1,133 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"unicode"
+)
+
+// ScreenResult is the outcome of screening one party
+type ScreenResult struct {
+	Match bool `json:"match"`
+	// MatchedName is the list entry that matched, as it appears on the list
+	MatchedName string `json:"matched_name,omitempty"`
+	List        string `json:"list,omitempty"`
+}
+
+// Screener checks parties against sanctions lists (e.g. OFAC SDN)
+type Screener interface {
+	Screen(ctx context.Context, recipient Recipient) (ScreenResult, error)
+}
+
+// ComplianceError blocks a send. Result is set for list matches; Err is set
+// when screening itself failed, since an unscreened send must not proceed.
+type ComplianceError struct {
+	Recipient string
+	Result    ScreenResult
+	Err       error
+}
+
+func (e *ComplianceError) Error() string {
+	if e.Err != nil {
+		return fmt.Sprintf("compliance: screening %q failed: %v", e.Recipient, e.Err)
+	}
+	return fmt.Sprintf("compliance: %q matches %s entry %q", e.Recipient, e.Result.List, e.Result.MatchedName)
+}
+
+func (e *ComplianceError) Unwrap() error {
+	return e.Err
+}
+
+// SanctionsListLoader fetches the current list of sanctioned names
+type SanctionsListLoader func(ctx context.Context) ([]string, error)
+
+// ListScreener is a basic Screener that matches recipient names exactly,
+// after normalising case, punctuation and spacing, against a list from its
+// loader. Production screening needs fuzzy matching and aliases; this is the
+// plug point for wiring the real list.
+type ListScreener struct {
+	List   string
+	Loader SanctionsListLoader
+
+	mu    sync.RWMutex
+	names map[string]string // normalised -> as listed
+}
+
+func NewListScreener(list string, loader SanctionsListLoader) *ListScreener {
+	return &ListScreener{List: list, Loader: loader}
+}
+
+// Reload replaces the in-memory list with the loader's current one
+func (s *ListScreener) Reload(ctx context.Context) error {
+	entries, err := s.Loader(ctx)
+	if err != nil {
+		return fmt.Errorf("loading %s list: %w", s.List, err)
+	}
+	names := make(map[string]string, len(entries))
+	for _, entry := range entries {
+		if key := normalizeName(entry); key != "" {
+			names[key] = entry
+		}
+	}
+
+	s.mu.Lock()
+	s.names = names
+	s.mu.Unlock()
+	return nil
+}
+
+// Screen loads the list on first use, then checks the recipient's name
+func (s *ListScreener) Screen(ctx context.Context, recipient Recipient) (ScreenResult, error) {
+	s.mu.RLock()
+	loaded := s.names != nil
+	s.mu.RUnlock()
+	if !loaded {
+		if err := s.Reload(ctx); err != nil {
+			return ScreenResult{}, err
+		}
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if listed, ok := s.names[normalizeName(recipient.Name)]; ok {
+		return ScreenResult{Match: true, MatchedName: listed, List: s.List}, nil
+	}
+	return ScreenResult{}, nil
+}
+
+// normalizeName lowercases, drops punctuation and collapses whitespace
+func normalizeName(name string) string {
+	var b strings.Builder
+	for _, r := range strings.ToLower(name) {
+		switch {
+		case unicode.IsLetter(r) || unicode.IsDigit(r):
+			b.WriteRune(r)
+		case unicode.IsSpace(r):
+			b.WriteRune(' ')
+		}
+	}
+	return strings.Join(strings.Fields(b.String()), " ")
+}
+
+// SetScreener makes the hub screen every recipient before sending. Call it
+// before serving traffic.
+func (rh *RemittanceHub) SetScreener(screener Screener) {
+	rh.screener = screener
+}
+
+// screen returns a ComplianceError if the recipient may not be paid
+func (rh *RemittanceHub) screen(ctx context.Context, recipient Recipient) error {
+	if rh.screener == nil {
+		return nil
+	}
+	result, err := rh.screener.Screen(ctx, recipient)
+	if err != nil {
+		return &ComplianceError{Recipient: recipient.Name, Err: err}
+	}
+	if result.Match {
+		return &ComplianceError{Recipient: recipient.Name, Result: result}
+	}
+	return nil
+}
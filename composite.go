@@ -0,0 +1,126 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+)
+
+// StatusPartiallyFailed is only ever a CompositeTransaction status: some
+// legs failed, were cancelled or refunded while others went through or are
+// still in flight
+const StatusPartiallyFailed TransactionStatus = "PARTIALLY_FAILED"
+
+// CompositeLeg is one provider transfer within a CompositeTransaction
+type CompositeLeg struct {
+	Provider string               `json:"provider"`
+	Response *TransactionResponse `json:"response"`
+}
+
+// CompositeTransaction is one logical transfer made of several provider
+// transfers, such as an executed split. Its Status is derived from the
+// legs: COMPLETED once every leg completes, FAILED (or CANCELLED) when
+// every leg did, PARTIALLY_FAILED when only some failed, otherwise PENDING.
+type CompositeTransaction struct {
+	Legs   []CompositeLeg    `json:"legs"`
+	Status TransactionStatus `json:"status"`
+}
+
+// NewCompositeTransaction combines ExecuteSplit's responses with the plan
+// they were sent from. Legs that were never sent (nil responses after a
+// failed ExecuteSplit) are left out.
+func NewCompositeTransaction(plan []PlannedTransfer, responses []*TransactionResponse) *CompositeTransaction {
+	c := &CompositeTransaction{}
+	for i, leg := range plan {
+		if i < len(responses) && responses[i] != nil {
+			c.Legs = append(c.Legs, CompositeLeg{Provider: leg.Provider, Response: responses[i]})
+		}
+	}
+	c.Status = c.aggregateStatus()
+	return c
+}
+
+// Amount is the total sent across all legs
+func (c *CompositeTransaction) Amount() float64 {
+	var total float64
+	for _, leg := range c.Legs {
+		total += leg.Response.Amount
+	}
+	return total
+}
+
+// Fee is the total fee across all legs
+func (c *CompositeTransaction) Fee() float64 {
+	var total float64
+	for _, leg := range c.Legs {
+		total += leg.Response.Fee
+	}
+	return total
+}
+
+// Done reports whether every leg has reached a terminal status
+func (c *CompositeTransaction) Done() bool {
+	for _, leg := range c.Legs {
+		if !leg.Response.Status.IsTerminal() {
+			return false
+		}
+	}
+	return true
+}
+
+func (c *CompositeTransaction) aggregateStatus() TransactionStatus {
+	if len(c.Legs) == 0 {
+		return StatusPending
+	}
+	var completed, cancelled, failed int
+	for _, leg := range c.Legs {
+		switch leg.Response.Status {
+		case StatusCompleted:
+			completed++
+		case StatusCancelled:
+			cancelled++
+			failed++
+		case StatusFailed, StatusRefundPending, StatusRefunded:
+			failed++
+		}
+	}
+	switch n := len(c.Legs); {
+	case completed == n:
+		return StatusCompleted
+	case cancelled == n:
+		return StatusCancelled
+	case failed == n:
+		return StatusFailed
+	case failed > 0:
+		return StatusPartiallyFailed
+	}
+	return StatusPending
+}
+
+// RefreshComposite re-polls every leg not yet in a terminal status, all at
+// once, and recomputes c.Status. Legs whose poll fails keep their last
+// known status; their errors are returned joined alongside the status.
+func (rh *RemittanceHub) RefreshComposite(ctx context.Context, c *CompositeTransaction) (TransactionStatus, error) {
+	errs := make([]error, len(c.Legs))
+	var wg sync.WaitGroup
+	for i, leg := range c.Legs {
+		if leg.Response.Status.IsTerminal() {
+			continue
+		}
+		wg.Add(1)
+		go func(i int, leg CompositeLeg) {
+			defer wg.Done()
+			tx, err := rh.GetTransactionStatus(ctx, leg.Provider, leg.Response.TransactionID)
+			if err != nil {
+				errs[i] = fmt.Errorf("leg %d via %s: %w", i+1, leg.Provider, err)
+				return
+			}
+			c.Legs[i].Response = tx
+		}(i, leg)
+	}
+	wg.Wait()
+
+	c.Status = c.aggregateStatus()
+	return c.Status, errors.Join(errs...)
+}
@@ -0,0 +1,435 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Provider identifiers used in Config.Providers and environment variables
+const (
+	ProviderWise       = "wise"
+	ProviderRemitly    = "remitly"
+	ProviderWorldRemit = "worldremit"
+	ProviderXoom       = "xoom"
+)
+
+// Duration is a time.Duration that reads and writes as a Go duration string
+// ("30s", "2m") in configuration files. Bare numbers are taken as seconds.
+type Duration time.Duration
+
+func (d Duration) MarshalJSON() ([]byte, error) {
+	return json.Marshal(time.Duration(d).String())
+}
+
+func (d *Duration) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		var seconds float64
+		if err := json.Unmarshal(data, &seconds); err != nil {
+			return fmt.Errorf("invalid duration %s", data)
+		}
+		*d = Duration(seconds * float64(time.Second))
+		return nil
+	}
+	parsed, err := time.ParseDuration(s)
+	if err != nil {
+		seconds, numErr := strconv.ParseFloat(s, 64)
+		if numErr != nil {
+			return err
+		}
+		parsed = time.Duration(seconds * float64(time.Second))
+	}
+	*d = Duration(parsed)
+	return nil
+}
+
+// Per-provider settings. BaseURL and Timeout are optional overrides of the
+// provider defaults (e.g. to point at a sandbox); Identity sets the
+// User-Agent and client ID headers sent to the provider.
+type WiseConfig struct {
+	WiseCredentials
+	BaseURL  string         `json:"base_url,omitempty"`
+	Timeout  Duration       `json:"timeout,omitempty"`
+	Identity ClientIdentity `json:"identity,omitempty"`
+	// AutoCreateRecipients registers recipients sent without an ID
+	AutoCreateRecipients bool `json:"auto_create_recipients,omitempty"`
+}
+
+type RemitlyConfig struct {
+	RemitlyCredentials
+	BaseURL  string         `json:"base_url,omitempty"`
+	Timeout  Duration       `json:"timeout,omitempty"`
+	Identity ClientIdentity `json:"identity,omitempty"`
+	// Simulate swaps in a SimulatedProvider that never calls the API (demos only)
+	Simulate bool `json:"simulate,omitempty"`
+}
+
+type WorldRemitConfig struct {
+	WorldRemitCredentials
+	BaseURL  string         `json:"base_url,omitempty"`
+	Timeout  Duration       `json:"timeout,omitempty"`
+	Identity ClientIdentity `json:"identity,omitempty"`
+	// Simulate swaps in a SimulatedProvider that never calls the API (demos only)
+	Simulate bool `json:"simulate,omitempty"`
+}
+
+type XoomConfig struct {
+	XoomCredentials
+	BaseURL  string         `json:"base_url,omitempty"`
+	Timeout  Duration       `json:"timeout,omitempty"`
+	Identity ClientIdentity `json:"identity,omitempty"`
+}
+
+// Config configures NewWalletRemittanceService.
+//
+// Providers lists the providers to enable by identifier (ProviderWise etc.).
+// When empty, every provider with at least one credential set is enabled.
+// Either way, an enabled provider must have all of its credentials.
+type Config struct {
+	Providers  []string         `json:"providers,omitempty"`
+	Wise       WiseConfig       `json:"wise"`
+	Remitly    RemitlyConfig    `json:"remitly"`
+	WorldRemit WorldRemitConfig `json:"worldremit"`
+	Xoom       XoomConfig       `json:"xoom"`
+	// Transport, if set, gives the configured providers their own pooled
+	// transport instead of the shared default
+	Transport *TransportOptions `json:"transport,omitempty"`
+}
+
+// requiredField pairs a config key with its value for validation
+type requiredField struct {
+	key   string
+	value string
+}
+
+func (c Config) requiredFields(provider string) []requiredField {
+	switch provider {
+	case ProviderWise:
+		return []requiredField{{"api_key", c.Wise.APIKey}, {"profile_id", c.Wise.ProfileID}}
+	case ProviderRemitly:
+		return []requiredField{{"api_key", c.Remitly.APIKey}}
+	case ProviderWorldRemit:
+		return []requiredField{{"api_key", c.WorldRemit.APIKey}, {"api_secret", c.WorldRemit.APISecret}}
+	case ProviderXoom:
+		return []requiredField{{"client_id", c.Xoom.ClientID}, {"client_secret", c.Xoom.ClientSecret}}
+	}
+	return nil
+}
+
+var knownProviders = []string{ProviderWise, ProviderRemitly, ProviderWorldRemit, ProviderXoom}
+
+// EnabledProviders resolves which providers the config turns on
+func (c Config) EnabledProviders() []string {
+	if len(c.Providers) > 0 {
+		enabled := make([]string, 0, len(c.Providers))
+		for _, name := range c.Providers {
+			enabled = append(enabled, strings.ToLower(strings.TrimSpace(name)))
+		}
+		return enabled
+	}
+
+	var enabled []string
+	for _, name := range knownProviders {
+		for _, field := range c.requiredFields(name) {
+			if field.value != "" {
+				enabled = append(enabled, name)
+				break
+			}
+		}
+	}
+	return enabled
+}
+
+// Validate checks that every enabled provider is known and fully configured
+func (c Config) Validate() error {
+	for _, name := range c.EnabledProviders() {
+		fields := c.requiredFields(name)
+		if fields == nil {
+			return fmt.Errorf("config: unknown provider %q", name)
+		}
+		for _, field := range fields {
+			if field.value == "" {
+				return fmt.Errorf("config: provider %s is enabled but %s is missing", name, field.key)
+			}
+		}
+	}
+	return nil
+}
+
+// LoadConfigFromEnv reads configuration from XCHNG_* environment variables:
+//
+//	XCHNG_PROVIDERS                  comma-separated enabled providers
+//	XCHNG_WISE_API_KEY, XCHNG_WISE_PROFILE_ID
+//	XCHNG_REMITLY_API_KEY
+//	XCHNG_WORLDREMIT_API_KEY, XCHNG_WORLDREMIT_API_SECRET
+//	XCHNG_XOOM_CLIENT_ID, XCHNG_XOOM_CLIENT_SECRET
+//	XCHNG_<PROVIDER>_BASE_URL, XCHNG_<PROVIDER>_TIMEOUT  optional overrides
+//	XCHNG_<PROVIDER>_USER_AGENT                          User-Agent sent to the provider
+//	XCHNG_REMITLY_SIMULATE, XCHNG_WORLDREMIT_SIMULATE     "true" for simulated pricing
+//	XCHNG_WISE_AUTO_CREATE_RECIPIENTS                    "true" to register recipients on send
+//	XCHNG_HTTP_MAX_IDLE_CONNS_PER_HOST, XCHNG_HTTP_IDLE_CONN_TIMEOUT,
+//	XCHNG_HTTP_KEEP_ALIVE                                connection pool tuning
+func LoadConfigFromEnv() (Config, error) {
+	var cfg Config
+	if providers := os.Getenv("XCHNG_PROVIDERS"); providers != "" {
+		cfg.Providers = strings.Split(providers, ",")
+	}
+
+	cfg.Wise.APIKey = os.Getenv("XCHNG_WISE_API_KEY")
+	cfg.Wise.ProfileID = os.Getenv("XCHNG_WISE_PROFILE_ID")
+	cfg.Remitly.APIKey = os.Getenv("XCHNG_REMITLY_API_KEY")
+	cfg.WorldRemit.APIKey = os.Getenv("XCHNG_WORLDREMIT_API_KEY")
+	cfg.WorldRemit.APISecret = os.Getenv("XCHNG_WORLDREMIT_API_SECRET")
+	cfg.Xoom.ClientID = os.Getenv("XCHNG_XOOM_CLIENT_ID")
+	cfg.Xoom.ClientSecret = os.Getenv("XCHNG_XOOM_CLIENT_SECRET")
+
+	flags := []struct {
+		name  string
+		value *bool
+	}{
+		{"XCHNG_REMITLY_SIMULATE", &cfg.Remitly.Simulate},
+		{"XCHNG_WORLDREMIT_SIMULATE", &cfg.WorldRemit.Simulate},
+		{"XCHNG_WISE_AUTO_CREATE_RECIPIENTS", &cfg.Wise.AutoCreateRecipients},
+	}
+	for _, flag := range flags {
+		if v := os.Getenv(flag.name); v != "" {
+			on, err := strconv.ParseBool(v)
+			if err != nil {
+				return Config{}, fmt.Errorf("config: %s: %w", flag.name, err)
+			}
+			*flag.value = on
+		}
+	}
+
+	overrides := []struct {
+		prefix    string
+		baseURL   *string
+		timeout   *Duration
+		userAgent *string
+	}{
+		{"XCHNG_WISE_", &cfg.Wise.BaseURL, &cfg.Wise.Timeout, &cfg.Wise.Identity.UserAgent},
+		{"XCHNG_REMITLY_", &cfg.Remitly.BaseURL, &cfg.Remitly.Timeout, &cfg.Remitly.Identity.UserAgent},
+		{"XCHNG_WORLDREMIT_", &cfg.WorldRemit.BaseURL, &cfg.WorldRemit.Timeout, &cfg.WorldRemit.Identity.UserAgent},
+		{"XCHNG_XOOM_", &cfg.Xoom.BaseURL, &cfg.Xoom.Timeout, &cfg.Xoom.Identity.UserAgent},
+	}
+	for _, o := range overrides {
+		*o.baseURL = os.Getenv(o.prefix + "BASE_URL")
+		*o.userAgent = os.Getenv(o.prefix + "USER_AGENT")
+		if v := os.Getenv(o.prefix + "TIMEOUT"); v != "" {
+			d, err := time.ParseDuration(v)
+			if err != nil {
+				return Config{}, fmt.Errorf("config: %sTIMEOUT: %w", o.prefix, err)
+			}
+			*o.timeout = Duration(d)
+		}
+	}
+
+	if err := loadTransportFromEnv(&cfg); err != nil {
+		return Config{}, err
+	}
+
+	return cfg, cfg.Validate()
+}
+
+// loadTransportFromEnv sets cfg.Transport if any XCHNG_HTTP_* tuning
+// variable is present
+func loadTransportFromEnv(cfg *Config) error {
+	var opts TransportOptions
+	set := false
+	if v := os.Getenv("XCHNG_HTTP_MAX_IDLE_CONNS_PER_HOST"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil {
+			return fmt.Errorf("config: XCHNG_HTTP_MAX_IDLE_CONNS_PER_HOST: %w", err)
+		}
+		opts.MaxIdleConnsPerHost, set = n, true
+	}
+	durations := []struct {
+		name  string
+		value *Duration
+	}{
+		{"XCHNG_HTTP_IDLE_CONN_TIMEOUT", &opts.IdleConnTimeout},
+		{"XCHNG_HTTP_KEEP_ALIVE", &opts.KeepAlive},
+	}
+	for _, d := range durations {
+		if v := os.Getenv(d.name); v != "" {
+			parsed, err := time.ParseDuration(v)
+			if err != nil {
+				return fmt.Errorf("config: %s: %w", d.name, err)
+			}
+			*d.value, set = Duration(parsed), true
+		}
+	}
+	if set {
+		cfg.Transport = &opts
+	}
+	return nil
+}
+
+// LoadConfigFromFile reads a JSON (.json) or YAML (.yaml, .yml) config file.
+// Keys match the JSON tags on Config.
+func LoadConfigFromFile(path string) (Config, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return Config{}, err
+	}
+
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml":
+		if data, err = yamlToJSON(data); err != nil {
+			return Config{}, fmt.Errorf("config: %s: %w", path, err)
+		}
+	case ".json":
+	default:
+		return Config{}, fmt.Errorf("config: %s: unsupported file type (want .json, .yaml or .yml)", path)
+	}
+
+	var cfg Config
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&cfg); err != nil {
+		return Config{}, fmt.Errorf("config: %s: %w", path, err)
+	}
+	return cfg, cfg.Validate()
+}
+
+// yamlToJSON converts the small YAML subset config files need (nested
+// mappings, "- item" and "[a, b]" sequences of scalars, comments) into JSON
+// so decoding can reuse Config's JSON tags. Anchors and block scalars are
+// rejected rather than misread.
+func yamlToJSON(data []byte) ([]byte, error) {
+	type yamlLine struct {
+		no      int
+		indent  int
+		content string
+	}
+	var lines []yamlLine
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for no := 1; scanner.Scan(); no++ {
+		line := strings.TrimRight(stripYAMLComment(scanner.Text()), " \t")
+		content := strings.TrimLeft(line, " ")
+		if content == "" {
+			continue
+		}
+		if strings.HasPrefix(content, "\t") {
+			return nil, fmt.Errorf("line %d: tabs are not allowed for indentation", no)
+		}
+		lines = append(lines, yamlLine{no: no, indent: len(line) - len(content), content: content})
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	type frame struct {
+		indent int
+		node   map[string]interface{}
+	}
+	root := map[string]interface{}{}
+	stack := []frame{{indent: -1, node: root}}
+	// listParent[listKey] is the block sequence currently being filled
+	var listParent map[string]interface{}
+	var listKey string
+	listIndent := 0
+
+	for i, l := range lines {
+		if l.content == "-" || strings.HasPrefix(l.content, "- ") {
+			if listParent == nil || l.indent < listIndent {
+				return nil, fmt.Errorf("line %d: sequence item outside a sequence", l.no)
+			}
+			items := listParent[listKey].([]interface{})
+			listParent[listKey] = append(items, yamlScalar(strings.TrimSpace(l.content[1:])))
+			continue
+		}
+		listParent = nil
+
+		for len(stack) > 1 && l.indent <= stack[len(stack)-1].indent {
+			stack = stack[:len(stack)-1]
+		}
+		parent := stack[len(stack)-1].node
+
+		colon := strings.Index(l.content, ":")
+		if colon <= 0 {
+			return nil, fmt.Errorf("line %d: expected \"key: value\"", l.no)
+		}
+		key := strings.Trim(strings.TrimSpace(l.content[:colon]), `"'`)
+		value := strings.TrimSpace(l.content[colon+1:])
+
+		switch {
+		case value == "":
+			// A bare key opens either a block sequence or a nested mapping
+			if i+1 < len(lines) && strings.HasPrefix(lines[i+1].content, "-") {
+				parent[key] = []interface{}{}
+				listParent, listKey, listIndent = parent, key, l.indent
+				continue
+			}
+			child := map[string]interface{}{}
+			parent[key] = child
+			stack = append(stack, frame{indent: l.indent, node: child})
+		case strings.HasPrefix(value, "["):
+			if !strings.HasSuffix(value, "]") {
+				return nil, fmt.Errorf("line %d: unterminated flow sequence", l.no)
+			}
+			items := []interface{}{}
+			for _, item := range strings.Split(value[1:len(value)-1], ",") {
+				if item = strings.TrimSpace(item); item != "" {
+					items = append(items, yamlScalar(item))
+				}
+			}
+			parent[key] = items
+		case value[0] == '&' || value[0] == '*' || value[0] == '|' || value[0] == '>' || value[0] == '{':
+			return nil, fmt.Errorf("line %d: unsupported YAML syntax %q", l.no, value)
+		default:
+			parent[key] = yamlScalar(value)
+		}
+	}
+	return json.Marshal(root)
+}
+
+// stripYAMLComment drops a trailing "# comment" that isn't inside quotes
+func stripYAMLComment(line string) string {
+	inSingle, inDouble := false, false
+	for i, r := range line {
+		switch r {
+		case '\'':
+			if !inDouble {
+				inSingle = !inSingle
+			}
+		case '"':
+			if !inSingle {
+				inDouble = !inDouble
+			}
+		case '#':
+			if !inSingle && !inDouble && (i == 0 || line[i-1] == ' ' || line[i-1] == '\t') {
+				return line[:i]
+			}
+		}
+	}
+	return line
+}
+
+// yamlScalar converts a plain or quoted YAML scalar. Every Config value is
+// a string (or a Duration, which also decodes from a string), so scalars are
+// kept as strings rather than guessing at numbers: a numeric Wise profile ID
+// must still decode into a string field.
+func yamlScalar(value string) interface{} {
+	if len(value) >= 2 {
+		if value[0] == '"' && value[len(value)-1] == '"' {
+			if unquoted, err := strconv.Unquote(value); err == nil {
+				return unquoted
+			}
+		}
+		if value[0] == '\'' && value[len(value)-1] == '\'' {
+			return strings.ReplaceAll(value[1:len(value)-1], "''", "'")
+		}
+	}
+	if value == "null" || value == "~" || value == "" {
+		return nil
+	}
+	return value
+}
@@ -0,0 +1,32 @@
+package main
+
+import (
+	"context"
+	"net/http"
+)
+
+// CorrelationIDHeader carries the correlation ID on every provider call
+const CorrelationIDHeader = "X-Correlation-ID"
+
+type correlationIDKey struct{}
+
+// WithCorrelationID tags ctx with a correlation ID, typically once per
+// incoming request. Every provider HTTP call made with the context, or a
+// context derived from it, sends the ID in CorrelationIDHeader.
+func WithCorrelationID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, correlationIDKey{}, id)
+}
+
+// CorrelationIDFromContext returns the ID set by WithCorrelationID, or ""
+func CorrelationIDFromContext(ctx context.Context) string {
+	id, _ := ctx.Value(correlationIDKey{}).(string)
+	return id
+}
+
+// setCorrelationID copies the request context's correlation ID, if any,
+// onto the outgoing request
+func setCorrelationID(req *http.Request) {
+	if id := CorrelationIDFromContext(req.Context()); id != "" {
+		req.Header.Set(CorrelationIDHeader, id)
+	}
+}
@@ -0,0 +1,83 @@
+package main
+
+import "sort"
+
+// Route is one usable combination of source currency, destination currency
+// and destination country
+type Route struct {
+	From    Currency `json:"from"`
+	To      Currency `json:"to"`
+	Country string   `json:"country"`
+}
+
+// Corridor is a Route together with the providers that serve it
+type Corridor struct {
+	Route
+	Providers []string `json:"providers"`
+}
+
+// routeTable expands source currencies against the payout currencies each
+// destination country accepts. Same-currency routes are left out.
+func routeTable(sources []Currency, payouts map[string][]Currency) []Route {
+	countries := make([]string, 0, len(payouts))
+	for country := range payouts {
+		countries = append(countries, country)
+	}
+	sort.Strings(countries)
+
+	var routes []Route
+	for _, from := range sources {
+		for _, country := range countries {
+			for _, to := range payouts[country] {
+				if from != to {
+					routes = append(routes, Route{From: from, To: to, Country: country})
+				}
+			}
+		}
+	}
+	return routes
+}
+
+// hasRoute reports whether routes include from->to for country, which is
+// how providers built on a route table implement SupportsCorridor. An empty
+// country matches any destination country.
+func hasRoute(routes []Route, from, to Currency, country string) bool {
+	for _, route := range routes {
+		if route.From == from && route.To == to && (country == "" || route.Country == country) {
+			return true
+		}
+	}
+	return false
+}
+
+// SupportedCorridors returns every route at least one registered provider
+// serves, deduplicated and sorted, with the providers for each in
+// registration order. Use it to offer only valid choices up front.
+func (rh *RemittanceHub) SupportedCorridors() []Corridor {
+	index := make(map[Route]int)
+	var corridors []Corridor
+
+	for _, provider := range rh.snapshot() {
+		for _, route := range provider.GetSupportedRoutes() {
+			i, ok := index[route]
+			if !ok {
+				i = len(corridors)
+				index[route] = i
+				corridors = append(corridors, Corridor{Route: route})
+			}
+			corridors[i].Providers = append(corridors[i].Providers, provider.GetName())
+		}
+	}
+
+	sort.Slice(corridors, func(i, j int) bool {
+		a, b := corridors[i].Route, corridors[j].Route
+		if a.From != b.From {
+			return a.From < b.From
+		}
+		if a.To != b.To {
+			return a.To < b.To
+		}
+		return a.Country < b.Country
+	})
+	return corridors
+}
@@ -0,0 +1,59 @@
+package main
+
+import (
+	"context"
+	"testing"
+)
+
+func TestCorridorLimitsDifferByDestination(t *testing.T) {
+	ctx := context.Background()
+	remitly := newSimulatedRemitly()
+	_, inrMax, err := remitly.GetCorridorLimits(ctx, USD, INR, "IN")
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, mxnMax, err := remitly.GetCorridorLimits(ctx, USD, MXN, "MX")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if inrMax == mxnMax {
+		t.Fatalf("USD->INR and USD->MXN share max %v; limits aren't per corridor", inrMax)
+	}
+
+	// Between the two maximums only the INR corridor quotes
+	hub := NewRemittanceHub()
+	hub.AddProvider(remitly)
+	amount := (inrMax + mxnMax) / 2
+
+	req := testRequest()
+	req.Amount = amount
+	if quotes, err := hub.GetQuotes(ctx, req); err != nil || len(quotes) != 1 {
+		t.Errorf("USD->INR %v: got %d quotes, %v; want Remitly's", amount, len(quotes), err)
+	}
+
+	req.ToCurrency = MXN
+	req.Recipient = Recipient{Name: "Ana Ruiz", Currency: MXN, Address: Address{CountryCode: "MX"}}
+	req.PaymentMethod = PaymentCash
+	if quotes, _ := hub.GetQuotes(ctx, req); len(quotes) != 0 {
+		t.Errorf("USD->MXN %v: got %d quotes, want Remitly skipped over its %v max", amount, len(quotes), mxnMax)
+	}
+}
+
+func TestCorridorLimitsCoverEveryRoute(t *testing.T) {
+	tables := map[string]struct {
+		routes []Route
+		limits map[Route]corridorLimit
+	}{
+		"Wise":       {wiseRoutes, wiseCorridorLimits},
+		"Remitly":    {remitlyRoutes, remitlyCorridorLimits},
+		"WorldRemit": {worldRemitRoutes, worldRemitCorridorLimits},
+		"Xoom":       {xoomRoutes, xoomCorridorLimits},
+	}
+	for name, table := range tables {
+		for _, route := range table.routes {
+			if _, _, err := lookupCorridorLimit(name, table.limits, route.From, route.To, route.Country); err != nil {
+				t.Error(err)
+			}
+		}
+	}
+}
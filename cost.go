@@ -0,0 +1,111 @@
+package main
+
+import (
+	"context"
+	"log"
+)
+
+// Effective cost methodology
+//
+// TotalCost (Amount + Fee) only counts the explicit fee, but providers also
+// earn a margin by quoting a rate below mid-market. A zero-fee quote with a
+// poor rate can therefore look cheapest while the recipient gets less.
+//
+// The effective cost of a quote is what the sender pays minus the value of
+// what the recipient receives, both in the source currency:
+//
+//	EffectiveCost = TotalCost - ReceivedAmount / referenceRate
+//
+// where referenceRate is the mid-market rate for the pair. The result is the
+// fee plus the rate margin.
+//
+// The reference comes from the hub's ReferenceRateProvider when one is set.
+// Without it the reference is the best rate quoted in the same comparison,
+// so margins are measured against the best offer on the table: a lower bound
+// on the true margin, but the same for every quote, so the ranking is still
+// like-for-like.
+//
+// Cost normalization
+//
+// Costs are compared in one reference currency: the request's FromCurrency,
+// which every provider prices TotalCost in. A quote funded from a balance
+// in another currency (FundingLeg) really costs the leg's Amount in that
+// currency, so the hub converts it back into FromCurrency as NormalizedCost:
+//
+//	NormalizedCost = FundingLeg.Amount * midMarket(FundingLeg.From -> FromCurrency)
+//
+// using the hub's ReferenceRateProvider, which makes the conversion margin
+// part of the cost. Without one, the leg's own quoted rate is used, which
+// still counts the conversion fee. Other quotes have NormalizedCost equal to
+// TotalCost. LowestCost and EffectiveCost rank on NormalizedCost.
+
+// cost is the sender's cost in the request's FromCurrency: NormalizedCost
+// once the hub has set it, else TotalCost
+func (q *RemittanceQuote) cost() float64 {
+	if q.NormalizedCost != 0 {
+		return q.NormalizedCost
+	}
+	return q.TotalCost
+}
+
+// normalizeCosts sets NormalizedCost on every quote, rounded to the minor
+// unit of from. fundingRate is the mid-market rate from the funding
+// currency to from, or 0 if unknown.
+func normalizeCosts(quotes []*RemittanceQuote, from Currency, fundingRate float64) {
+	for _, quote := range quotes {
+		leg := quote.FundingLeg
+		switch {
+		case leg == nil || leg.Amount <= 0:
+			quote.NormalizedCost = quote.TotalCost
+		case fundingRate > 0:
+			quote.NormalizedCost = RoundReceivedAmount(leg.Amount*fundingRate, from, "", RoundingNearest)
+		case leg.ExchangeRate > 0:
+			quote.NormalizedCost = RoundReceivedAmount(leg.Amount*leg.ExchangeRate, from, "", RoundingNearest)
+		default:
+			quote.NormalizedCost = quote.TotalCost
+		}
+	}
+}
+
+// fundingReferenceRate returns the mid-market rate for converting req's
+// FundingCurrency into FromCurrency, or 0 when req isn't funded from
+// another balance or no reference source answers
+func (rh *RemittanceHub) fundingReferenceRate(ctx context.Context, req TransactionRequest) float64 {
+	if !req.fundingConversion() || rh.reference == nil {
+		return 0
+	}
+	pair := CurrencyPair{From: req.FundingCurrency, To: req.FromCurrency}
+	rate, err := rh.midMarketRate(ctx, pair.From, pair.To)
+	if err != nil {
+		log.Printf("Reference rate unavailable for %s: %v", pair, err)
+		return 0
+	}
+	return rate
+}
+
+// effectiveCost applies the formula above. A non-positive referenceRate
+// leaves only the cost.
+func effectiveCost(quote *RemittanceQuote, referenceRate float64) float64 {
+	if referenceRate <= 0 {
+		return quote.cost()
+	}
+	return quote.cost() - quote.ReceivedAmount/referenceRate
+}
+
+func annotateEffectiveCost(quotes []*RemittanceQuote, referenceRate float64) {
+	for _, quote := range quotes {
+		quote.EffectiveCost = effectiveCost(quote, referenceRate)
+	}
+}
+
+// bestQuotedRate is the highest exchange rate among quotes, the stand-in
+// reference rate when no mid-market source is available
+func bestQuotedRate(quotes []*RemittanceQuote) float64 {
+	var best float64
+	for _, quote := range quotes {
+		if quote.ExchangeRate > best {
+			best = quote.ExchangeRate
+		}
+	}
+	return best
+}
@@ -0,0 +1,64 @@
+package main
+
+import "math"
+
+// setTotalDebit fills in TotalDebit and DebitCurrency from the response's
+// Amount and Fee: the send amount plus the sender's share of the fee, card
+// surcharge included. models are the sending provider's FeeModels, which
+// decide who paid the fee (see feePaidBy).
+//
+// A transfer funded from another balance debits that balance instead, so
+// the total is the quoted FundingLeg's Amount in FundingCurrency, worked
+// back through the leg's rate and fee if the charge moved since the quote.
+// Without a funding leg to go by, the total stays in FromCurrency.
+//
+// Responses that don't report an Amount or Fee are left alone rather than
+// given a total that might understate the charge, as are failed transfers,
+// which debit nothing.
+func (tx *TransactionResponse) setTotalDebit(req TransactionRequest, models []FeePaidBy) {
+	if tx.Amount <= 0 || tx.FeeUnknown || tx.Status == StatusFailed {
+		return
+	}
+	debit := RoundReceivedAmount(tx.Amount+senderFeeShare(tx.Fee, feePaidBy(req, models)), req.FromCurrency, "", RoundingNearest)
+	tx.TotalDebit, tx.DebitCurrency = debit, req.FromCurrency
+
+	if !req.fundingConversion() || req.Quote == nil {
+		return
+	}
+	leg := req.Quote.FundingLeg
+	if leg == nil || leg.From != req.FundingCurrency || leg.ExchangeRate <= 0 {
+		return
+	}
+	funded := leg.Amount
+	if math.Abs(debit-req.Quote.TotalCost) >= precisionEpsilon {
+		funded = debit/leg.ExchangeRate + leg.Fee
+	}
+	tx.TotalDebit, tx.DebitCurrency = RoundReceivedAmount(funded, leg.From, "", RoundingNearest), leg.From
+}
+
+// feePaidBy is the fee model a send was charged under. The accepted quote
+// says so when there is one. Otherwise it is the provider's: a provider
+// with a single model (Wise always takes its fee from the source amount)
+// charges that whatever was asked, and only one pricing several is held to
+// req.FeePaidBy. nil models mean sender pays, as in Capabilities.
+func feePaidBy(req TransactionRequest, models []FeePaidBy) FeePaidBy {
+	if req.Quote != nil && req.Quote.FeePaidBy != "" {
+		return req.Quote.FeePaidBy
+	}
+	switch len(models) {
+	case 0:
+		return FeePaidBySender
+	case 1:
+		return models[0]
+	}
+	requested := req.FeePaidBy
+	if requested == "" {
+		requested = FeePaidBySender
+	}
+	for _, model := range models {
+		if model == requested {
+			return requested
+		}
+	}
+	return models[0]
+}
@@ -0,0 +1,56 @@
+package main
+
+import (
+	"context"
+	"testing"
+)
+
+func TestTotalDebitWithoutQuoteUsesProviderFeeModel(t *testing.T) {
+	srv := newRecordingServer(t, map[string]string{
+		"/v1/quotes":    `{"id":"q1","source":"USD","target":"INR","sourceAmount":500,"targetAmount":41085,"rate":83,"fee":5}`,
+		"/v1/transfers": `{"id":12,"quoteUuid":"q1","status":"incoming_payment_waiting","rate":83}`,
+	})
+	wise := NewWiseProvider("key", "profile")
+	wise.BaseURL = srv.URL
+	hub := NewRemittanceHub()
+	hub.AddProvider(wise)
+
+	req := testRequest()
+	req.Recipient.ID = "acct-1"
+	tx, err := hub.SendMoneyWithProvider(context.Background(), wise.GetName(), req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Wise takes its fee out of the 500 sent, so nothing is added on top
+	if tx.Fee != 5 || tx.TotalDebit != 500 || tx.DebitCurrency != USD {
+		t.Errorf("fee %v, total debit %v %s; want fee 5 within a 500 USD debit", tx.Fee, tx.TotalDebit, tx.DebitCurrency)
+	}
+}
+
+func TestFeePaidBy(t *testing.T) {
+	both := []FeePaidBy{FeePaidBySender, FeePaidByRecipient}
+	tests := []struct {
+		name      string
+		requested FeePaidBy
+		quoted    FeePaidBy
+		models    []FeePaidBy
+		want      FeePaidBy
+	}{
+		{"quote decides", FeePaidBySender, FeePaidByRecipient, both, FeePaidByRecipient},
+		{"single model overrides request", FeePaidBySender, "", []FeePaidBy{FeePaidByRecipient}, FeePaidByRecipient},
+		{"single model with nothing requested", "", "", []FeePaidBy{FeePaidByRecipient}, FeePaidByRecipient},
+		{"no models means sender", FeePaidByRecipient, "", nil, FeePaidBySender},
+		{"request honoured when priced", FeePaidByRecipient, "", both, FeePaidByRecipient},
+		{"nothing requested", "", "", both, FeePaidBySender},
+		{"unpriced request", FeePaidByShared, "", both, FeePaidBySender},
+	}
+	for _, tt := range tests {
+		req := TransactionRequest{FeePaidBy: tt.requested}
+		if tt.quoted != "" {
+			req.Quote = &RemittanceQuote{FeePaidBy: tt.quoted}
+		}
+		if got := feePaidBy(req, tt.models); got != tt.want {
+			t.Errorf("%s: feePaidBy = %s, want %s", tt.name, got, tt.want)
+		}
+	}
+}
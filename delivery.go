@@ -0,0 +1,84 @@
+package main
+
+import (
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Delivery time approximations used when a provider only gives us words.
+// Business days are treated as 24h here; they are a ranking aid, not an SLA.
+const (
+	instantDelivery = time.Minute
+	minutesDelivery = time.Hour
+	hoursDelivery   = 24 * time.Hour
+	dayDelivery     = 24 * time.Hour
+)
+
+// ParseEstimatedTime converts a provider's human-readable delivery estimate
+// ("Minutes", "Minutes to hours", "1-2 business days") into a duration range.
+// ok is false when the string isn't recognised.
+func ParseEstimatedTime(estimate string) (min, max time.Duration, ok bool) {
+	s := strings.ToLower(strings.TrimSpace(estimate))
+	if s == "" {
+		return 0, 0, false
+	}
+
+	// "Minutes to hours": lower bound from the left, upper bound from the right
+	if parts := strings.SplitN(s, " to ", 2); len(parts) == 2 {
+		lo, _, okLo := parseDeliveryTerm(parts[0])
+		_, hi, okHi := parseDeliveryTerm(parts[1])
+		return lo, hi, okLo && okHi
+	}
+
+	return parseDeliveryTerm(s)
+}
+
+// parseDeliveryTerm handles a single term such as "minutes", "2 hours" or
+// "1-2 business days".
+func parseDeliveryTerm(term string) (min, max time.Duration, ok bool) {
+	fields := strings.Fields(term)
+	if len(fields) == 0 {
+		return 0, 0, false
+	}
+
+	unit := fields[len(fields)-1]
+	var base time.Duration
+	switch {
+	case strings.HasPrefix(unit, "instant"), unit == "seconds":
+		return 0, instantDelivery, true
+	case strings.HasPrefix(unit, "minute"):
+		base = time.Minute
+	case strings.HasPrefix(unit, "hour"):
+		base = time.Hour
+	case strings.HasPrefix(unit, "day"):
+		base = dayDelivery
+	default:
+		return 0, 0, false
+	}
+
+	// Bare unit ("Minutes", "hours") means "some number of" that unit
+	if len(fields) == 1 {
+		switch base {
+		case time.Minute:
+			return time.Minute, minutesDelivery, true
+		case time.Hour:
+			return time.Hour, hoursDelivery, true
+		}
+		return dayDelivery, 2 * dayDelivery, true
+	}
+
+	lo, hi := fields[0], fields[0]
+	if bounds := strings.SplitN(fields[0], "-", 2); len(bounds) == 2 {
+		lo, hi = bounds[0], bounds[1]
+	}
+	loN, err := strconv.Atoi(lo)
+	if err != nil {
+		return 0, 0, false
+	}
+	hiN, err := strconv.Atoi(hi)
+	if err != nil || hiN < loN {
+		return 0, 0, false
+	}
+	return time.Duration(loN) * base, time.Duration(hiN) * base, true
+}
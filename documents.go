@@ -0,0 +1,153 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/textproto"
+	"path/filepath"
+	"strings"
+)
+
+// maxDocumentSize bounds an uploaded compliance document. Providers reject
+// larger files; reading stops here rather than buffering the rest.
+const maxDocumentSize = 10 << 20
+
+// DocumentType is a provider-neutral kind of compliance document
+type DocumentType string
+
+const (
+	DocumentProofOfFunds    DocumentType = "PROOF_OF_FUNDS"
+	DocumentSourceOfWealth  DocumentType = "SOURCE_OF_WEALTH"
+	DocumentProofOfAddress  DocumentType = "PROOF_OF_ADDRESS"
+	DocumentIdentity        DocumentType = "IDENTITY"
+	DocumentBankStatement   DocumentType = "BANK_STATEMENT"
+	DocumentPurposeEvidence DocumentType = "PURPOSE_EVIDENCE"
+)
+
+// Document is a file sent to a provider to clear a compliance hold
+type Document struct {
+	Type     DocumentType
+	Filename string
+	// ContentType defaults to the type implied by Filename's extension
+	ContentType string
+	// Content is read once, up to maxDocumentSize
+	Content io.Reader
+}
+
+func (d Document) validate() error {
+	if d.Type == "" {
+		return &ValidationError{Field: "document.type", Err: errors.New("is required")}
+	}
+	if d.Filename == "" {
+		return &ValidationError{Field: "document.filename", Err: errors.New("is required")}
+	}
+	if d.Content == nil {
+		return &ValidationError{Field: "document.content", Err: errors.New("is required")}
+	}
+	return nil
+}
+
+func (d Document) contentType() string {
+	if d.ContentType != "" {
+		return d.ContentType
+	}
+	if t := mime.TypeByExtension(strings.ToLower(filepath.Ext(d.Filename))); t != "" {
+		return t
+	}
+	return "application/octet-stream"
+}
+
+// DocumentUploader is implemented by providers that accept compliance
+// documents against a transfer. A transfer that needs one lists it in
+// TransactionResponse.RequiredDocuments.
+type DocumentUploader interface {
+	UploadComplianceDocument(ctx context.Context, transactionID string, doc Document) error
+}
+
+// UploadComplianceDocument sends doc to the named provider for one of its
+// transfers. Providers that take no documents return ErrUnsupported.
+func (rh *RemittanceHub) UploadComplianceDocument(ctx context.Context, providerName, transactionID string, doc Document) error {
+	if err := doc.validate(); err != nil {
+		return err
+	}
+	provider, err := rh.findProvider(providerName)
+	if err != nil {
+		return err
+	}
+	uploader, ok := provider.(DocumentUploader)
+	if !ok {
+		return &UnsupportedError{Provider: providerName, Operation: "compliance documents"}
+	}
+	return uploader.UploadComplianceDocument(ctx, transactionID, doc)
+}
+
+// requireDocuments records the documents a provider asked for and adds a
+// RequiredActions entry for each one not already listed
+func (tx *TransactionResponse) requireDocuments(types []DocumentType) {
+	for _, t := range types {
+		if t == "" || containsDocument(tx.RequiredDocuments, t) {
+			continue
+		}
+		tx.RequiredDocuments = append(tx.RequiredDocuments, t)
+		tx.RequiredActions = append(tx.RequiredActions, documentAction(t))
+	}
+}
+
+// documentAction is the RequiredActions entry for a missing document
+func documentAction(t DocumentType) string {
+	return fmt.Sprintf("Upload a %s document (UploadComplianceDocument)", strings.ToLower(strings.ReplaceAll(string(t), "_", " ")))
+}
+
+func containsDocument(types []DocumentType, t DocumentType) bool {
+	for _, have := range types {
+		if have == t {
+			return true
+		}
+	}
+	return false
+}
+
+// documentTypes converts the document codes in a provider response,
+// upper-casing them to match the DocumentType constants
+func documentTypes(codes []string) []DocumentType {
+	types := make([]DocumentType, 0, len(codes))
+	for _, code := range codes {
+		types = append(types, DocumentType(strings.ToUpper(strings.TrimSpace(code))))
+	}
+	return types
+}
+
+// documentForm encodes doc as a multipart form with a "type" field and a
+// "file" part, returning the body and its Content-Type. The body is
+// buffered so middleware retries can replay it.
+func documentForm(doc Document) (*bytes.Buffer, string, error) {
+	var body bytes.Buffer
+	form := multipart.NewWriter(&body)
+	if err := form.WriteField("type", string(doc.Type)); err != nil {
+		return nil, "", err
+	}
+
+	header := make(textproto.MIMEHeader)
+	header.Set("Content-Disposition", mime.FormatMediaType("form-data", map[string]string{"name": "file", "filename": doc.Filename}))
+	header.Set("Content-Type", doc.contentType())
+	part, err := form.CreatePart(header)
+	if err != nil {
+		return nil, "", err
+	}
+	n, err := io.Copy(part, io.LimitReader(doc.Content, maxDocumentSize+1))
+	if err != nil {
+		return nil, "", fmt.Errorf("read document: %w", err)
+	}
+	if n > maxDocumentSize {
+		return nil, "", &ValidationError{Field: "document.content", Err: fmt.Errorf("exceeds %d bytes", maxDocumentSize)}
+	}
+	if err := form.Close(); err != nil {
+		return nil, "", err
+	}
+	return &body, form.FormDataContentType(), nil
+}
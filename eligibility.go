@@ -0,0 +1,107 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// SenderProfile is what the hub knows about the sender for eligibility
+// checks. Zero fields are unknown and not checked.
+type SenderProfile struct {
+	// CountryOfResidence is an ISO 3166-1 alpha-2 code
+	CountryOfResidence string     `json:"country_of_residence,omitempty"`
+	DateOfBirth        *time.Time `json:"date_of_birth,omitempty"`
+}
+
+// EligibilityRule restricts who may send through a provider. A rule with
+// an empty Country applies to every destination; otherwise only to
+// transfers paid out in Country.
+type EligibilityRule struct {
+	Country string
+	// SenderCountries lists where the sender must live; nil means anywhere
+	SenderCountries []string
+	// MinimumAge is the sender's minimum age in whole years; 0 means none
+	MinimumAge int
+}
+
+// IneligibleError says why a sender can't use a provider
+type IneligibleError struct {
+	Provider string
+	Reason   string
+}
+
+func (e *IneligibleError) Error() string {
+	return fmt.Sprintf("provider %s: %s: %v", e.Provider, e.Reason, ErrSenderIneligible)
+}
+
+// Is makes errors.Is(err, ErrSenderIneligible) match
+func (e *IneligibleError) Is(target error) bool {
+	return target == ErrSenderIneligible
+}
+
+// checkEligibility applies the provider's EligibilityRules for a payout in
+// toCountry. A nil sender is not checked.
+func checkEligibility(provider RemittanceProvider, toCountry string, sender *SenderProfile, now time.Time) *IneligibleError {
+	if sender == nil {
+		return nil
+	}
+	for _, rule := range provider.GetCapabilities().Eligibility {
+		if rule.Country != "" && !strings.EqualFold(rule.Country, toCountry) {
+			continue
+		}
+		if residence := sender.CountryOfResidence; residence != "" && rule.SenderCountries != nil &&
+			!containsString(rule.SenderCountries, strings.ToUpper(residence)) {
+			return &IneligibleError{
+				Provider: provider.GetName(),
+				Reason:   fmt.Sprintf("senders resident in %s are not accepted", strings.ToUpper(residence)),
+			}
+		}
+		if rule.MinimumAge > 0 && sender.DateOfBirth != nil && ageOn(*sender.DateOfBirth, now) < rule.MinimumAge {
+			return &IneligibleError{
+				Provider: provider.GetName(),
+				Reason:   fmt.Sprintf("sender must be at least %d", rule.MinimumAge),
+			}
+		}
+	}
+	return nil
+}
+
+// sendingCountry is where the transfer is sent from: the sender's country
+// of residence, or "" when it isn't known
+func (r TransactionRequest) sendingCountry() string {
+	if r.Sender == nil {
+		return ""
+	}
+	return strings.ToUpper(r.Sender.CountryOfResidence)
+}
+
+// servesCountry reports whether country is in a provider's sending or
+// receiving list. An empty list or an empty country always matches.
+func servesCountry(countries []string, country string) bool {
+	return len(countries) == 0 || country == "" || containsString(countries, strings.ToUpper(country))
+}
+
+// ineligibleSender is the error when every provider on the route was
+// excluded for the sender, listing each provider's reason
+func ineligibleSender(excluded []*IneligibleError) error {
+	reasons := make([]string, len(excluded))
+	for i, e := range excluded {
+		reasons[i] = e.Provider + ": " + e.Reason
+	}
+	return fmt.Errorf("%w for any provider on this route (%s)", ErrSenderIneligible, strings.Join(reasons, "; "))
+}
+
+// ageOn returns the age in whole years on the given day
+func ageOn(dob, now time.Time) int {
+	age := now.Year() - dob.Year()
+	if now.Month() < dob.Month() || (now.Month() == dob.Month() && now.Day() < dob.Day()) {
+		age--
+	}
+	return age
+}
+
+var adultsOnly = []EligibilityRule{{MinimumAge: 18}}
+
+// Xoom only accepts senders resident in the US
+var xoomEligibility = []EligibilityRule{{SenderCountries: []string{"US"}, MinimumAge: 18}}
@@ -0,0 +1,38 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSenderProfileOmitsUnknownDateOfBirth(t *testing.T) {
+	data, err := json.Marshal(SenderProfile{CountryOfResidence: "US"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(data), "date_of_birth") {
+		t.Errorf("marshalled %s; an unknown date of birth should be omitted", data)
+	}
+}
+
+func TestGetEligibleProvidersExcludesMinors(t *testing.T) {
+	hub := NewRemittanceHub()
+	hub.AddProvider(newSimulatedRemitly())
+	dob := time.Now().AddDate(-16, 0, 0)
+
+	available, excluded := hub.GetEligibleProviders("US", "IN", USD, INR, "", &SenderProfile{DateOfBirth: &dob})
+	if len(available) != 0 || len(excluded) != 1 || !errors.Is(excluded[0], ErrSenderIneligible) {
+		t.Errorf("minor: available %v, excluded %v; want Remitly excluded", available, excluded)
+	}
+
+	available, excluded = hub.GetEligibleProviders("US", "IN", USD, INR, "", &SenderProfile{CountryOfResidence: "US"})
+	if len(available) != 1 || len(excluded) != 0 {
+		t.Errorf("unknown age: available %v, excluded %v; want Remitly available", available, excluded)
+	}
+	if got := hub.GetAvailableProviders("US", "IN", USD, INR); len(got) != 1 {
+		t.Errorf("GetAvailableProviders = %v, want Remitly", got)
+	}
+}
@@ -0,0 +1,106 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+)
+
+// The enum types reject unknown values when decoded, so a request body with
+// {"from_currency":"XYZ"} fails at the JSON boundary instead of travelling
+// on as an invalid Currency. The empty string is accepted as "unset" so zero
+// values round-trip; Validate reports missing fields.
+
+// RegisterCurrency adds or replaces a currency in the registry used for
+// decoding and precision checks. It is safe to call while serving traffic.
+func RegisterCurrency(info CurrencyInfo) {
+	currencyMu.Lock()
+	defer currencyMu.Unlock()
+	currencyInfo[info.Code] = info
+}
+
+// RegisteredCurrencies returns the known currency codes, sorted
+func RegisteredCurrencies() []Currency {
+	currencyMu.RLock()
+	currencies := make([]Currency, 0, len(currencyInfo))
+	for c := range currencyInfo {
+		currencies = append(currencies, c)
+	}
+	currencyMu.RUnlock()
+	sort.Slice(currencies, func(i, j int) bool { return currencies[i] < currencies[j] })
+	return currencies
+}
+
+// IsRegistered reports whether c is in the currency registry
+func (c Currency) IsRegistered() bool {
+	_, ok := c.Info()
+	return ok
+}
+
+func (c *Currency) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return fmt.Errorf("currency: %w", err)
+	}
+	if s != "" && !Currency(s).IsRegistered() {
+		return fmt.Errorf("unknown currency %q", s)
+	}
+	*c = Currency(s)
+	return nil
+}
+
+var knownStatuses = map[TransactionStatus]bool{
+	StatusPending:       true,
+	StatusCompleted:     true,
+	StatusFailed:        true,
+	StatusCancelled:     true,
+	StatusRefundPending: true,
+	StatusRefunded:      true,
+	// only on a CompositeTransaction
+	StatusPartiallyFailed: true,
+}
+
+func (s *TransactionStatus) UnmarshalJSON(data []byte) error {
+	var v string
+	if err := json.Unmarshal(data, &v); err != nil {
+		return fmt.Errorf("transaction status: %w", err)
+	}
+	if v != "" && !knownStatuses[TransactionStatus(v)] {
+		return fmt.Errorf("unknown transaction status %q", v)
+	}
+	*s = TransactionStatus(v)
+	return nil
+}
+
+var knownPaymentMethods = map[PaymentMethod]bool{
+	PaymentBankTransfer: true,
+	PaymentCard:         true,
+	PaymentWallet:       true,
+	PaymentCash:         true,
+}
+
+func (m *PaymentMethod) UnmarshalJSON(data []byte) error {
+	var v string
+	if err := json.Unmarshal(data, &v); err != nil {
+		return fmt.Errorf("payment method: %w", err)
+	}
+	if v != "" && !knownPaymentMethods[PaymentMethod(v)] {
+		return fmt.Errorf("unknown payment method %q", v)
+	}
+	*m = PaymentMethod(v)
+	return nil
+}
+
+func (p *FeePaidBy) UnmarshalJSON(data []byte) error {
+	var v string
+	if err := json.Unmarshal(data, &v); err != nil {
+		return fmt.Errorf("fee paid by: %w", err)
+	}
+	switch FeePaidBy(v) {
+	case "", FeePaidBySender, FeePaidByRecipient, FeePaidByShared:
+	default:
+		return fmt.Errorf("unknown fee model %q", v)
+	}
+	*p = FeePaidBy(v)
+	return nil
+}
@@ -0,0 +1,78 @@
+package main
+
+import (
+	"encoding/json"
+	"sync"
+	"testing"
+)
+
+func TestEnumsRoundTripJSON(t *testing.T) {
+	type enums struct {
+		Currency  Currency          `json:"currency"`
+		Status    TransactionStatus `json:"status"`
+		Method    PaymentMethod     `json:"method"`
+		FeePaidBy FeePaidBy         `json:"fee_paid_by"`
+	}
+	for _, in := range []enums{
+		{USD, StatusCompleted, PaymentCash, FeePaidByRecipient},
+		{JPY, StatusPartiallyFailed, PaymentWallet, FeePaidByShared},
+		{},
+	} {
+		data, err := json.Marshal(in)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var out enums
+		if err := json.Unmarshal(data, &out); err != nil {
+			t.Fatalf("unmarshal %s: %v", data, err)
+		}
+		if out != in {
+			t.Errorf("round trip of %s = %+v, want %+v", data, out, in)
+		}
+	}
+}
+
+func TestEnumsRejectUnknownValues(t *testing.T) {
+	for _, tt := range []struct {
+		name string
+		data string
+		into interface{}
+	}{
+		{"currency", `"XYZ"`, new(Currency)},
+		{"status", `"LOST"`, new(TransactionStatus)},
+		{"payment method", `"CHEQUE"`, new(PaymentMethod)},
+		{"fee paid by", `"NOBODY"`, new(FeePaidBy)},
+		{"not a string", `42`, new(Currency)},
+	} {
+		if err := json.Unmarshal([]byte(tt.data), tt.into); err == nil {
+			t.Errorf("%s: %s decoded without error", tt.name, tt.data)
+		}
+	}
+}
+
+func TestRegisterCurrencyConcurrentWithReads(t *testing.T) {
+	const code Currency = "XTS"
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			RegisterCurrency(CurrencyInfo{Code: code, DecimalPlaces: 3})
+		}()
+		go func() {
+			defer wg.Done()
+			USD.DecimalPlaces()
+			RegisteredCurrencies()
+			code.IsRegistered()
+		}()
+	}
+	wg.Wait()
+
+	var c Currency
+	if err := json.Unmarshal([]byte(`"XTS"`), &c); err != nil || c != code {
+		t.Errorf("registered currency decoded as %q, %v", c, err)
+	}
+	if got := code.DecimalPlaces(); got != 3 {
+		t.Errorf("DecimalPlaces() = %d, want 3", got)
+	}
+}
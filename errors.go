@@ -0,0 +1,191 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// Sentinel errors for conditions callers commonly branch on. They are
+// returned wrapped, so test with errors.Is.
+var (
+	// ErrProviderNotFound: no registered provider has the requested name
+	ErrProviderNotFound = errors.New("provider not found")
+	// ErrNoProvidersForCorridor: no registered provider serves the route
+	ErrNoProvidersForCorridor = errors.New("no providers for corridor")
+	// ErrNoQuotes: providers serve the route but none returned a quote
+	ErrNoQuotes = errors.New("no quotes available")
+	// ErrQuoteExpired: the accepted quote's ValidUntil has passed
+	ErrQuoteExpired = errors.New("quote expired")
+	// ErrSplitUncoverable: the corridor's providers can't cover a split
+	// transfer's amount within their per-transfer limits
+	ErrSplitUncoverable = errors.New("amount cannot be covered by available providers")
+	// ErrSenderIneligible: the sender's residence or age rules out the
+	// provider (see IneligibleError)
+	ErrSenderIneligible = errors.New("sender not eligible")
+	// ErrRecipientNotFound: no registered recipient account matches
+	ErrRecipientNotFound = errors.New("recipient not found")
+	// ErrQuoteRefreshLimited: a QuoteSession refreshed too recently to
+	// fetch again
+	ErrQuoteRefreshLimited = errors.New("quote refresh rate limited")
+	// ErrQuoteChanged: the price moved beyond tolerance since the user
+	// confirmed it
+	ErrQuoteChanged = errors.New("quote changed since confirmation")
+	// ErrWalletNotFound: the provider has no mobile wallet for the
+	// recipient's phone number
+	ErrWalletNotFound = errors.New("recipient wallet not found")
+	// ErrClosed: the hub has been shut down with Close
+	ErrClosed = errors.New("hub closed")
+)
+
+// ErrUnsupported is returned (wrapped) when a provider lacks an optional
+// capability. The hub never emulates a missing capability, e.g. it will not
+// silently turn a recurring transfer into a single one.
+var ErrUnsupported = errors.New("operation not supported by provider")
+
+// UnsupportedError names the provider and the capability it lacks
+type UnsupportedError struct {
+	Provider  string
+	Operation string
+}
+
+func (e *UnsupportedError) Error() string {
+	return fmt.Sprintf("provider %s: %s: %v", e.Provider, e.Operation, ErrUnsupported)
+}
+
+// Is makes errors.Is(err, ErrUnsupported) match
+func (e *UnsupportedError) Is(target error) bool {
+	return target == ErrUnsupported
+}
+
+// APIError is a non-2xx response from a provider API. Network failures are
+// returned as-is, so errors.As(err, &apiErr) tells the two apart.
+type APIError struct {
+	Provider   string
+	StatusCode int
+	// Code is the provider's own error code, when it sends one
+	Code    string
+	Message string
+	// Header holds the response headers, e.g. Retry-After on a 429
+	Header http.Header
+}
+
+func (e *APIError) Error() string {
+	if e.Code != "" {
+		return fmt.Sprintf("%s: status %d: %s (%s)", e.Provider, e.StatusCode, e.Message, e.Code)
+	}
+	return fmt.Sprintf("%s: status %d: %s", e.Provider, e.StatusCode, e.Message)
+}
+
+// Retryable reports whether the same request may succeed later: rate
+// limiting and server-side failures
+func (e *APIError) Retryable() bool {
+	return e.StatusCode == http.StatusTooManyRequests || e.StatusCode >= 500
+}
+
+// isNotFound reports whether err is a provider 404
+func isNotFound(err error) bool {
+	var apiErr *APIError
+	return errors.As(err, &apiErr) && apiErr.StatusCode == http.StatusNotFound
+}
+
+// checkResponse turns a non-2xx response into an *APIError, picking up the
+// message from the common JSON error shapes. The body is closed on error.
+// secrets are the caller's credentials, masked in case the provider echoes
+// one back in its error message.
+func checkResponse(provider string, resp *http.Response, secrets ...string) error {
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return nil
+	}
+	defer resp.Body.Close()
+
+	var body errorEnvelope
+	json.NewDecoder(io.LimitReader(resp.Body, 64<<10)).Decode(&body)
+
+	apiErr := &APIError{
+		Provider:   provider,
+		StatusCode: resp.StatusCode,
+		Header:     RedactHeaders(resp.Header),
+	}
+	apiErr.Code, apiErr.Message = body.codeAndMessage()
+	apiErr.Message = RedactSecrets(apiErr.Message, secrets...)
+	if apiErr.Message == "" {
+		apiErr.Message = http.StatusText(resp.StatusCode)
+	}
+	return apiErr
+}
+
+// errorEnvelope covers the common JSON error shapes providers send:
+// {"code","message"}, PayPal's {"name","message"}, OAuth's
+// {"error","error_description"}, {"error"} and {"errors":[{"code","message"}]}
+type errorEnvelope struct {
+	Code             string `json:"code"`
+	Name             string `json:"name"`
+	Message          string `json:"message"`
+	Error            string `json:"error"`
+	ErrorDescription string `json:"error_description"`
+	Errors           []struct {
+		Code    string `json:"code"`
+		Message string `json:"message"`
+	} `json:"errors"`
+}
+
+// codeAndMessage picks the first populated shape; message is "" when the
+// body held none of them
+func (b errorEnvelope) codeAndMessage() (code, message string) {
+	code = b.Code
+	if code == "" {
+		code = b.Name
+	}
+	switch {
+	case b.Message != "":
+		return code, b.Message
+	case b.ErrorDescription != "":
+		return b.Error, b.ErrorDescription
+	case b.Error != "":
+		return code, b.Error
+	case len(b.Errors) > 0:
+		return b.Errors[0].Code, b.Errors[0].Message
+	}
+	return code, ""
+}
+
+// genericFailureReason fills TransactionResponse.Error for a failed
+// transfer whose provider gave no reason
+const genericFailureReason = "transfer failed; provider gave no reason"
+
+// failureReason returns the TransactionResponse.Error value for a transfer
+// in status: the provider's reason if it failed, otherwise ""
+func failureReason(status TransactionStatus, reason string) string {
+	if status != StatusFailed {
+		return ""
+	}
+	if reason = strings.TrimSpace(reason); reason == "" {
+		return genericFailureReason
+	}
+	return reason
+}
+
+// normalizeError enforces the TransactionResponse.Error contract on
+// responses from any provider, including ones outside this package
+func (r *TransactionResponse) normalizeError() {
+	r.Error = failureReason(r.Status, r.Error)
+}
+
+// ValidationError reports a TransactionRequest field that failed
+// validation. Err may itself be typed, e.g. *MissingBankDetailsError.
+type ValidationError struct {
+	Field string
+	Err   error
+}
+
+func (e *ValidationError) Error() string {
+	return fmt.Sprintf("invalid %s: %v", e.Field, e.Err)
+}
+
+func (e *ValidationError) Unwrap() error {
+	return e.Err
+}
@@ -0,0 +1,188 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+)
+
+// TransactionEventType is a provider-neutral step in a transfer's life
+type TransactionEventType string
+
+const (
+	EventCreated       TransactionEventType = "CREATED"
+	EventFundsReceived TransactionEventType = "FUNDS_RECEIVED"
+	EventProcessing    TransactionEventType = "PROCESSING"
+	EventPaidOut       TransactionEventType = "PAID_OUT"
+	EventFailed        TransactionEventType = "FAILED"
+	EventCancelled     TransactionEventType = "CANCELLED"
+	EventRefunded      TransactionEventType = "REFUNDED"
+	// EventUpdated is any provider event without a neutral equivalent;
+	// ProviderCode says what it was
+	EventUpdated TransactionEventType = "UPDATED"
+)
+
+// TransactionEvent is one entry in a transfer's timeline
+type TransactionEvent struct {
+	Type      TransactionEventType `json:"type"`
+	Timestamp time.Time            `json:"timestamp"`
+	Detail    string               `json:"detail,omitempty"`
+	// ProviderCode is the provider's own name for the event
+	ProviderCode string `json:"provider_code,omitempty"`
+}
+
+// GetTransactionEvents returns the timeline of a transfer sent through the
+// named provider, oldest first
+func (rh *RemittanceHub) GetTransactionEvents(ctx context.Context, providerName, transactionID string) ([]TransactionEvent, error) {
+	provider, err := rh.findProvider(providerName)
+	if err != nil {
+		return nil, err
+	}
+	var events []TransactionEvent
+	err = rh.retry(ctx, func(ctx context.Context) error {
+		events, err = provider.GetTransactionEvents(ctx, transactionID)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	sortEvents(events)
+	return events, nil
+}
+
+// newEvent maps a provider event code through codes, case-insensitively,
+// falling back to EventUpdated
+func newEvent(codes map[string]TransactionEventType, code string, at time.Time, detail string) TransactionEvent {
+	eventType, ok := codes[strings.ToLower(code)]
+	if !ok {
+		eventType = EventUpdated
+	}
+	return TransactionEvent{Type: eventType, Timestamp: at, Detail: detail, ProviderCode: code}
+}
+
+func sortEvents(events []TransactionEvent) {
+	sort.SliceStable(events, func(i, j int) bool {
+		return events[i].Timestamp.Before(events[j].Timestamp)
+	})
+}
+
+var wiseEventCodes = map[string]TransactionEventType{
+	"incoming_payment_waiting":  EventCreated,
+	"incoming_payment_received": EventFundsReceived,
+	"processing":                EventProcessing,
+	"funds_converted":           EventProcessing,
+	"outgoing_payment_sent":     EventPaidOut,
+	"cancelled":                 EventCancelled,
+	"bounced_back":              EventFailed,
+	"funds_refunded":            EventRefunded,
+}
+
+// GetTransactionEvents reads the transfer's state changes
+func (w *WiseProvider) GetTransactionEvents(ctx context.Context, transactionID string) ([]TransactionEvent, error) {
+	resp, err := w.makeRequest(ctx, "GET", "/v1/transfers/"+url.PathEscape(transactionID)+"/events", nil)
+	if err != nil {
+		if isNotFound(err) {
+			return nil, fmt.Errorf("wise transfer %s: %w", transactionID, ErrTransactionNotFound)
+		}
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	var feed []struct {
+		State       string    `json:"state"`
+		CreatedAt   time.Time `json:"createdAt"`
+		Description string    `json:"description"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&feed); err != nil {
+		return nil, fmt.Errorf("wise events: %w", err)
+	}
+
+	events := make([]TransactionEvent, 0, len(feed))
+	for _, e := range feed {
+		events = append(events, newEvent(wiseEventCodes, e.State, e.CreatedAt, e.Description))
+	}
+	return events, nil
+}
+
+var remitlyEventCodes = map[string]TransactionEventType{
+	"created":    EventCreated,
+	"funded":     EventFundsReceived,
+	"processing": EventProcessing,
+	"in_transit": EventProcessing,
+	"delivered":  EventPaidOut,
+	"failed":     EventFailed,
+	"rejected":   EventFailed,
+	"cancelled":  EventCancelled,
+	"refunded":   EventRefunded,
+}
+
+func (r *RemitlyProvider) GetTransactionEvents(ctx context.Context, transactionID string) ([]TransactionEvent, error) {
+	resp, err := r.makeRequest(ctx, "GET", "/v1/transfers/"+url.PathEscape(transactionID)+"/events", nil)
+	if err != nil {
+		if isNotFound(err) {
+			return nil, fmt.Errorf("remitly transfer %s: %w", transactionID, ErrTransactionNotFound)
+		}
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	var feed struct {
+		Events []struct {
+			EventType   string    `json:"event_type"`
+			OccurredAt  time.Time `json:"occurred_at"`
+			Description string    `json:"description"`
+		} `json:"events"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&feed); err != nil {
+		return nil, fmt.Errorf("remitly events: %w", err)
+	}
+
+	events := make([]TransactionEvent, 0, len(feed.Events))
+	for _, e := range feed.Events {
+		events = append(events, newEvent(remitlyEventCodes, e.EventType, e.OccurredAt, e.Description))
+	}
+	return events, nil
+}
+
+var worldRemitEventCodes = map[string]TransactionEventType{
+	"created":         EventCreated,
+	"paymentreceived": EventFundsReceived,
+	"processing":      EventProcessing,
+	"paid":            EventPaidOut,
+	"collected":       EventPaidOut,
+	"failed":          EventFailed,
+	"cancelled":       EventCancelled,
+	"refunded":        EventRefunded,
+}
+
+func (wr *WorldRemitProvider) GetTransactionEvents(ctx context.Context, transactionID string) ([]TransactionEvent, error) {
+	resp, err := wr.makeRequest(ctx, "GET", "/v1/transactions/"+url.PathEscape(transactionID)+"/events", nil)
+	if err != nil {
+		if isNotFound(err) {
+			return nil, fmt.Errorf("worldremit transaction %s: %w", transactionID, ErrTransactionNotFound)
+		}
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	var feed struct {
+		Events []struct {
+			Type      string    `json:"type"`
+			Timestamp time.Time `json:"timestamp"`
+			Message   string    `json:"message"`
+		} `json:"events"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&feed); err != nil {
+		return nil, fmt.Errorf("worldremit events: %w", err)
+	}
+
+	events := make([]TransactionEvent, 0, len(feed.Events))
+	for _, e := range feed.Events {
+		events = append(events, newEvent(worldRemitEventCodes, e.Type, e.Timestamp, e.Message))
+	}
+	return events, nil
+}
@@ -0,0 +1,116 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// FallbackAttempt is one provider SendMoneyWithFallback tried
+type FallbackAttempt struct {
+	Provider string
+	Err      error
+}
+
+// FallbackError reports every provider SendMoneyWithFallback tried and why
+// each failed. The last attempt's error is the one that stopped the chain.
+type FallbackError struct {
+	Attempts []FallbackAttempt
+}
+
+func (e *FallbackError) Error() string {
+	parts := make([]string, len(e.Attempts))
+	for i, a := range e.Attempts {
+		parts[i] = fmt.Sprintf("%s: %v", a.Provider, a.Err)
+	}
+	return fmt.Sprintf("send failed after trying %d provider(s): %s", len(e.Attempts), strings.Join(parts, "; "))
+}
+
+// Unwrap exposes each attempt's error to errors.Is and errors.As
+func (e *FallbackError) Unwrap() []error {
+	errs := make([]error, len(e.Attempts))
+	for i, a := range e.Attempts {
+		errs[i] = a.Err
+	}
+	return errs
+}
+
+// SendMoneyWithFallback sends req through the providers of orderedQuotes
+// in order (e.g. as ranked by GetQuotes), moving on to the next when a
+// provider fails transiently or can't take this transfer. Each attempt is
+// sent against that provider's quote.
+//
+// The chain stops at the first error that would fail everywhere, such as
+// validation, compliance or insufficient funds. It also stops when a send
+// might have gone through: a network error from a provider that can't
+// confirm by idempotency key that no transfer was created. Failing over
+// then could pay twice. A transfer the provider confirms it did create is
+// returned as a success.
+func (rh *RemittanceHub) SendMoneyWithFallback(ctx context.Context, orderedQuotes []*RemittanceQuote, req TransactionRequest) (*TransactionResponse, error) {
+	if len(orderedQuotes) == 0 {
+		return nil, ErrNoQuotes
+	}
+
+	fallback := &FallbackError{}
+	for _, quote := range orderedQuotes {
+		tx, next, err := rh.trySend(ctx, quote, req)
+		if err == nil {
+			return tx, nil
+		}
+		fallback.Attempts = append(fallback.Attempts, FallbackAttempt{Provider: quote.Provider, Err: err})
+		if !next || ctx.Err() != nil {
+			break
+		}
+	}
+	return nil, fallback
+}
+
+// trySend makes one attempt of SendMoneyWithFallback, reporting whether
+// the next provider may be tried after a failure
+func (rh *RemittanceHub) trySend(ctx context.Context, quote *RemittanceQuote, req TransactionRequest) (tx *TransactionResponse, next bool, err error) {
+	provider, err := rh.findProvider(quote.Provider)
+	if err != nil {
+		return nil, true, err
+	}
+
+	req.Quote = quote
+	if req.LockedQuote != nil && (req.LockedQuote.Quote == nil || req.LockedQuote.Quote.Provider != quote.Provider) {
+		req.LockedQuote = nil
+	}
+	if err := rh.checkSend(ctx, provider, &req); err != nil {
+		return nil, providerSpecific(err), err
+	}
+
+	tx, err = rh.sendWithProvider(ctx, provider, req)
+	if err == nil {
+		return tx, false, nil
+	}
+	if !retryable(err) {
+		return nil, false, err
+	}
+
+	// The send may have reached the provider; only fail over once it is
+	// known that no transfer was created
+	if finder, ok := provider.(TransactionFinder); ok && req.idempotencyKey() != "" {
+		found, findErr := finder.FindTransactionByIdempotencyKey(ctx, req.idempotencyKey())
+		if findErr == nil {
+			found.normalizeError()
+			found.setTotalDebit(req, provider.GetCapabilities().FeeModels)
+			rh.recordSend(ctx, provider.GetName(), req, found)
+			return found, false, nil
+		}
+		return nil, errors.Is(findErr, ErrTransactionNotFound), err
+	}
+	// An API error is the provider answering that it didn't take the send
+	var apiErr *APIError
+	return nil, errors.As(err, &apiErr), err
+}
+
+// providerSpecific reports whether a checkSend failure is about this
+// provider rather than the transfer, so another provider may accept it
+func providerSpecific(err error) bool {
+	return errors.Is(err, ErrUnsupported) ||
+		errors.Is(err, ErrQuoteExpired) ||
+		errors.Is(err, ErrSenderIneligible)
+}
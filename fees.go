@@ -0,0 +1,175 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+)
+
+// FeeEstimator is implemented by providers with a published fee formula
+// that can be evaluated locally, without an authenticated quote call.
+// Results are estimates for display and are not binding.
+type FeeEstimator interface {
+	EstimateFee(from, to Currency, amount float64, method PaymentMethod) (float64, error)
+}
+
+// Fee estimate sources
+const (
+	FeeSourceFormula = "formula" // provider's published fee formula
+	FeeSourceCached  = "cached"  // scaled from a recent real quote
+)
+
+// FeeEstimate is a non-binding fee ballpark for one provider
+type FeeEstimate struct {
+	Provider string  `json:"provider"`
+	Fee      float64 `json:"fee"`
+	Source   string  `json:"source"`
+}
+
+// feeCacheTTL bounds how stale an observed fee may be before it is no
+// longer used for estimates
+const feeCacheTTL = time.Hour
+
+type feeCacheKey struct {
+	provider string
+	from, to Currency
+	method   PaymentMethod
+}
+
+type observedFee struct {
+	ratio      float64 // fee as a fraction of the send amount
+	observedAt time.Time
+}
+
+// feeCache remembers the fee ratio of recent quotes so providers without a
+// local formula can still be estimated.
+type feeCache struct {
+	mu   sync.Mutex
+	ttl  time.Duration
+	fees map[feeCacheKey]observedFee
+}
+
+func newFeeCache(ttl time.Duration) *feeCache {
+	return &feeCache{ttl: ttl, fees: make(map[feeCacheKey]observedFee)}
+}
+
+func (c *feeCache) observe(quote *RemittanceQuote, req TransactionRequest) {
+	if quote == nil || quote.Amount <= 0 {
+		return
+	}
+	key := feeCacheKey{quote.Provider, req.FromCurrency, req.ToCurrency, req.PaymentMethod}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.fees[key] = observedFee{ratio: quote.Fee / quote.Amount, observedAt: time.Now()}
+}
+
+func (c *feeCache) estimate(key feeCacheKey, amount float64) (float64, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	observed, ok := c.fees[key]
+	if !ok || time.Since(observed.observedAt) > c.ttl {
+		return 0, false
+	}
+	return observed.ratio * amount, true
+}
+
+// EstimateFees returns a quick, non-binding fee estimate per provider for
+// search results. Providers with a published formula (FeeEstimator) are
+// evaluated locally; others fall back to the fee ratio of a real quote seen
+// in the last hour. Providers with neither are left out. No network calls
+// are made, so figures can differ from what GetQuotes returns.
+func (rh *RemittanceHub) EstimateFees(from, to Currency, amount float64, method PaymentMethod) []FeeEstimate {
+	var estimates []FeeEstimate
+	for _, provider := range rh.snapshot() {
+		if estimator, ok := provider.(FeeEstimator); ok {
+			if fee, err := estimator.EstimateFee(from, to, amount, method); err == nil {
+				estimates = append(estimates, FeeEstimate{Provider: provider.GetName(), Fee: fee, Source: FeeSourceFormula})
+				continue
+			}
+		}
+		key := feeCacheKey{provider.GetName(), from, to, method}
+		if fee, ok := rh.fees.estimate(key, amount); ok {
+			estimates = append(estimates, FeeEstimate{Provider: provider.GetName(), Fee: fee, Source: FeeSourceCached})
+		}
+	}
+
+	sort.SliceStable(estimates, func(i, j int) bool {
+		return estimates[i].Fee < estimates[j].Fee
+	})
+	return estimates
+}
+
+// EstimateFee returns a single provider's non-binding fee estimate, using
+// the same formula-then-cache fallback as EstimateFees.
+func (rh *RemittanceHub) EstimateFee(providerName string, from, to Currency, amount float64, method PaymentMethod) (float64, error) {
+	provider, err := rh.findProvider(providerName)
+	if err != nil {
+		return 0, err
+	}
+	if estimator, ok := provider.(FeeEstimator); ok {
+		return estimator.EstimateFee(from, to, amount, method)
+	}
+	if fee, ok := rh.fees.estimate(feeCacheKey{providerName, from, to, method}, amount); ok {
+		return fee, nil
+	}
+	return 0, fmt.Errorf("provider %s: no fee formula and no recent quote to estimate from", providerName)
+}
+
+// FeePaidBy says who bears a transfer's fee
+type FeePaidBy string
+
+const (
+	// FeePaidBySender adds the fee on top of the send amount
+	FeePaidBySender FeePaidBy = "SENDER"
+	// FeePaidByRecipient takes the fee out of the send amount before
+	// conversion, so the recipient gets less
+	FeePaidByRecipient FeePaidBy = "RECIPIENT"
+	// FeePaidByShared splits the fee evenly between the two
+	FeePaidByShared FeePaidBy = "SHARED"
+)
+
+var allFeeModels = []FeePaidBy{FeePaidBySender, FeePaidByRecipient, FeePaidByShared}
+
+// applyFeeModel sets TotalCost and ReceivedAmount from Amount, Fee and
+// ExchangeRate for quotes priced locally. An empty model means sender pays.
+func applyFeeModel(quote *RemittanceQuote, paidBy FeePaidBy) {
+	if paidBy != FeePaidByRecipient && paidBy != FeePaidByShared {
+		paidBy = FeePaidBySender
+	}
+	senderShare := senderFeeShare(quote.Fee, paidBy)
+	recipientShare := quote.Fee - senderShare
+
+	quote.FeePaidBy = paidBy
+	quote.TotalCost = quote.Amount + senderShare
+	quote.ReceivedAmount = (quote.Amount - recipientShare) * quote.ExchangeRate
+}
+
+// fillImpliedRate derives ExchangeRate for a quote whose provider sent
+// the amount received and the fee but no rate: ReceivedAmount over the
+// part of Amount actually converted, which excludes any fee share the
+// recipient bears. It reports whether a rate could be derived, and marks
+// the quote RateImplied when one was.
+func (quote *RemittanceQuote) fillImpliedRate() bool {
+	converted := quote.Amount - (quote.Fee - senderFeeShare(quote.Fee, quote.FeePaidBy))
+	if quote.ReceivedAmount <= 0 || converted <= 0 {
+		return false
+	}
+	quote.ExchangeRate = quote.ReceivedAmount / converted
+	quote.RateImplied = true
+	return true
+}
+
+// senderFeeShare is the part of fee the sender pays on top of the send
+// amount under paidBy. An empty model means sender pays.
+func senderFeeShare(fee float64, paidBy FeePaidBy) float64 {
+	switch paidBy {
+	case FeePaidByRecipient:
+		return 0
+	case FeePaidByShared:
+		return fee / 2
+	}
+	return fee
+}
@@ -0,0 +1,63 @@
+package main
+
+import (
+	"context"
+	"math"
+	"testing"
+)
+
+func TestFillImpliedRate(t *testing.T) {
+	tests := []struct {
+		name  string
+		quote RemittanceQuote
+		want  float64
+		ok    bool
+	}{
+		// 1000 sent, fee deducted before converting: 990 converted
+		{"recipient pays", RemittanceQuote{Amount: 1000, Fee: 10, ReceivedAmount: 82170, FeePaidBy: FeePaidByRecipient}, 83, true},
+		// Fee on top: all 1000 converted
+		{"sender pays", RemittanceQuote{Amount: 1000, Fee: 10, ReceivedAmount: 83000, FeePaidBy: FeePaidBySender}, 83, true},
+		{"shared", RemittanceQuote{Amount: 1000, Fee: 10, ReceivedAmount: 82585, FeePaidBy: FeePaidByShared}, 83, true},
+		{"nothing received", RemittanceQuote{Amount: 1000, Fee: 10}, 0, false},
+		{"fee swallows amount", RemittanceQuote{Amount: 10, Fee: 10, ReceivedAmount: 1, FeePaidBy: FeePaidByRecipient}, 0, false},
+	}
+	for _, tt := range tests {
+		quote := tt.quote
+		ok := quote.fillImpliedRate()
+		if ok != tt.ok || math.Abs(quote.ExchangeRate-tt.want) > 1e-9 || quote.RateImplied != tt.ok {
+			t.Errorf("%s: got rate %v implied %v (ok %v), want %v (ok %v)", tt.name, quote.ExchangeRate, quote.RateImplied, ok, tt.want, tt.ok)
+		}
+	}
+}
+
+func TestWiseQuoteWithoutRate(t *testing.T) {
+	ctx := context.Background()
+	srv := newRecordingServer(t, map[string]string{
+		"/v1/quotes": `{"id":"q1","source":"USD","target":"INR","sourceAmount":1000,"targetAmount":82170,"fee":10}`,
+	})
+	w := NewWiseProvider("key", "profile")
+	w.BaseURL = srv.URL
+
+	req := testRequest()
+	req.Amount = 1000
+	quote, err := w.GetQuote(ctx, req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !quote.RateImplied || math.Abs(quote.ExchangeRate-83) > 1e-9 {
+		t.Errorf("rate %v implied %v, want 83 implied from 82170 / (1000 - 10)", quote.ExchangeRate, quote.RateImplied)
+	}
+}
+
+func TestXoomQuoteWithoutAnyRateRejected(t *testing.T) {
+	srv := newRecordingServer(t, map[string]string{
+		"/v1/remittances/quotes": `{"quote_id":"q1","send_amount":1000,"fee":5}`,
+	})
+	x := NewXoomProvider("id", "secret")
+	x.BaseURL = srv.URL
+	x.TokenSource = StaticTokenSource("token")
+
+	if quote, err := x.GetQuote(context.Background(), testRequest()); err == nil {
+		t.Fatalf("got quote with rate %v, want an error", quote.ExchangeRate)
+	}
+}
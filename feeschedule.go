@@ -0,0 +1,102 @@
+package main
+
+import (
+	"context"
+	"math"
+)
+
+// FeeTier prices transfers whose amount falls in [MinAmount, MaxAmount] as
+// FlatFee plus PercentFee of the amount, held within MinFee and MaxFee
+type FeeTier struct {
+	MinAmount float64 `json:"min_amount"`
+	// MaxAmount of 0 means no upper bound
+	MaxAmount float64 `json:"max_amount,omitempty"`
+	FlatFee   float64 `json:"flat_fee"`
+	// PercentFee is a fraction of the send amount (0.02 = 2%)
+	PercentFee float64 `json:"percent_fee"`
+	// MinFee and MaxFee of 0 mean no floor or cap
+	MinFee float64 `json:"min_fee,omitempty"`
+	MaxFee float64 `json:"max_fee,omitempty"`
+}
+
+// FeeSchedule is a provider's published fee structure for one corridor and
+// payment method, for rendering a pricing table. Like EstimateFee it is not
+// binding: promotions and the rate margin are not included.
+type FeeSchedule struct {
+	Provider string        `json:"provider"`
+	From     Currency      `json:"from"`
+	To       Currency      `json:"to"`
+	Method   PaymentMethod `json:"method,omitempty"`
+	// Tiers are in ascending amount order; a boundary amount belongs to
+	// the first tier that contains it
+	Tiers []FeeTier `json:"tiers"`
+}
+
+// Fee prices amount under the schedule. ok is false when no tier covers it.
+func (s FeeSchedule) Fee(amount float64) (fee float64, ok bool) {
+	for _, tier := range s.Tiers {
+		if amount < tier.MinAmount || (tier.MaxAmount > 0 && amount > tier.MaxAmount) {
+			continue
+		}
+		fee = tier.FlatFee + amount*tier.PercentFee
+		if tier.MinFee > 0 {
+			fee = math.Max(fee, tier.MinFee)
+		}
+		if tier.MaxFee > 0 {
+			fee = math.Min(fee, tier.MaxFee)
+		}
+		return RoundReceivedAmount(fee, s.From, "", RoundingNearest), true
+	}
+	return 0, false
+}
+
+// FeeScheduleProvider is implemented by providers that publish their fee
+// tiers
+type FeeScheduleProvider interface {
+	GetFeeSchedule(ctx context.Context, from, to Currency, method PaymentMethod) (FeeSchedule, error)
+}
+
+// GetFeeSchedule returns the named provider's fee tiers for a corridor and
+// payment method. Providers without a published schedule return
+// ErrUnsupported.
+func (rh *RemittanceHub) GetFeeSchedule(ctx context.Context, providerName string, from, to Currency, method PaymentMethod) (FeeSchedule, error) {
+	provider, err := rh.findProvider(providerName)
+	if err != nil {
+		return FeeSchedule{}, err
+	}
+	publisher, ok := provider.(FeeScheduleProvider)
+	if !ok {
+		return FeeSchedule{}, &UnsupportedError{Provider: providerName, Operation: "fee schedule"}
+	}
+	return publisher.GetFeeSchedule(ctx, from, to, method)
+}
+
+// Remitly charges remitlyFeeRate across its whole corridor range
+func (r *RemitlyProvider) GetFeeSchedule(ctx context.Context, from, to Currency, method PaymentMethod) (FeeSchedule, error) {
+	min, max, err := r.GetCorridorLimits(ctx, from, to, "")
+	if err != nil {
+		return FeeSchedule{}, err
+	}
+	return FeeSchedule{
+		Provider: r.GetName(),
+		From:     from,
+		To:       to,
+		Method:   method,
+		Tiers:    []FeeTier{{MinAmount: min, MaxAmount: max, PercentFee: remitlyFeeRate}},
+	}, nil
+}
+
+// WorldRemit charges worldRemitFlatFee across its whole corridor range
+func (wr *WorldRemitProvider) GetFeeSchedule(ctx context.Context, from, to Currency, method PaymentMethod) (FeeSchedule, error) {
+	min, max, err := wr.GetCorridorLimits(ctx, from, to, "")
+	if err != nil {
+		return FeeSchedule{}, err
+	}
+	return FeeSchedule{
+		Provider: wr.GetName(),
+		From:     from,
+		To:       to,
+		Method:   method,
+		Tiers:    []FeeTier{{MinAmount: min, MaxAmount: max, FlatFee: worldRemitFlatFee}},
+	}, nil
+}
@@ -0,0 +1,152 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+)
+
+// ConversionLeg is the extra balance conversion needed when a transfer is
+// funded from a balance in a currency other than the send currency: Amount
+// of From is converted at ExchangeRate, less Fee, into the send amount.
+type ConversionLeg struct {
+	From         Currency `json:"from"`
+	To           Currency `json:"to"`
+	Amount       float64  `json:"amount"`
+	Fee          float64  `json:"fee"`
+	ExchangeRate float64  `json:"exchange_rate"`
+	// QuoteID is the provider's quote for the conversion, reused on send
+	QuoteID string `json:"quote_id,omitempty"`
+}
+
+// fundingConversion reports whether req is funded from a balance that has
+// to be converted into FromCurrency first
+func (r TransactionRequest) fundingConversion() bool {
+	return r.FundingCurrency != "" && r.FundingCurrency != r.FromCurrency
+}
+
+// fundsFrom reports whether the provider can fund req from the requested
+// balance; requests funded in the send currency need no support
+func fundsFrom(provider RemittanceProvider, req TransactionRequest) bool {
+	return !req.fundingConversion() || provider.GetCapabilities().MultiCurrencyFunding
+}
+
+// cardOrBank is the FundingMethods list of providers taking card and bank
+// payments
+var cardOrBank = []PaymentMethod{PaymentBankTransfer, PaymentCard}
+
+// fundingMethod is how the sender pays; empty leaves it to the provider
+func (r TransactionRequest) fundingMethod() PaymentMethod {
+	return r.FundingMethod
+}
+
+// payoutMethod is how the recipient is paid; empty means a bank deposit
+func (r TransactionRequest) payoutMethod() PaymentMethod {
+	if r.PaymentMethod == "" {
+		return PaymentBankTransfer
+	}
+	return r.PaymentMethod
+}
+
+// paysWith reports whether the provider accepts the request's funding
+// method; providers that don't list their methods are assumed to
+func paysWith(provider RemittanceProvider, req TransactionRequest) bool {
+	method := req.fundingMethod()
+	methods := provider.GetCapabilities().FundingMethods
+	if method == "" || len(methods) == 0 {
+		return true
+	}
+	for _, m := range methods {
+		if m == method {
+			return true
+		}
+	}
+	return false
+}
+
+// wisePayIn maps funding methods to Wise's payIn option
+var wisePayIn = map[PaymentMethod]string{
+	PaymentBankTransfer: "BANK_TRANSFER",
+	PaymentCard:         "DEBIT",
+}
+
+// xoomFundingSources maps funding methods to Xoom's funding_source. With
+// none given Xoom draws on the sender's PayPal balance.
+var xoomFundingSources = map[PaymentMethod]string{
+	PaymentBankTransfer: "BANK_ACCOUNT",
+	PaymentCard:         "DEBIT_CARD",
+}
+
+// xoomFundingSource is the funding_source sent on both quote and transfer,
+// so the transfer is funded the way the quote was priced
+func xoomFundingSource(req TransactionRequest) string {
+	if source, ok := xoomFundingSources[req.fundingMethod()]; ok {
+		return source
+	}
+	return "PAYPAL_BALANCE"
+}
+
+// quoteFunding quotes converting the sender's FundingCurrency balance into
+// amount of FromCurrency, the quote's TotalCost
+func (w *WiseProvider) quoteFunding(ctx context.Context, req TransactionRequest, amount float64) (*ConversionLeg, error) {
+	quoteReq := map[string]interface{}{
+		"profile":      w.ProfileID,
+		"source":       req.FundingCurrency,
+		"target":       req.FromCurrency,
+		"targetAmount": amount,
+		"type":         "BALANCE_CONVERSION",
+	}
+
+	resp, err := w.makeRequest(ctx, "POST", "/v1/quotes", quoteReq)
+	if err != nil {
+		return nil, fmt.Errorf("wise funding conversion %s to %s: %w", req.FundingCurrency, req.FromCurrency, err)
+	}
+	defer resp.Body.Close()
+
+	var quoteResp WiseQuoteResponse
+	if err := json.NewDecoder(resp.Body).Decode(&quoteResp); err != nil {
+		return nil, fmt.Errorf("wise funding conversion %s to %s: %w", req.FundingCurrency, req.FromCurrency, err)
+	}
+	if err := quoteResp.validateConversion(); err != nil {
+		return nil, fmt.Errorf("wise funding conversion %s to %s: %w", req.FundingCurrency, req.FromCurrency, err)
+	}
+
+	return &ConversionLeg{
+		From:         req.FundingCurrency,
+		To:           req.FromCurrency,
+		Amount:       quoteResp.SourceAmount,
+		Fee:          quoteResp.Fee,
+		ExchangeRate: quoteResp.Rate,
+		QuoteID:      string(quoteResp.ID),
+	}, nil
+}
+
+// convertFunding moves money between the sender's Wise balances so the
+// transfer can be paid from the FromCurrency balance. It reuses the quoted
+// conversion when the accepted quote carries one.
+func (w *WiseProvider) convertFunding(ctx context.Context, req TransactionRequest) error {
+	var quoteID string
+	if req.Quote != nil && req.Quote.FundingLeg != nil && req.Quote.FundingLeg.From == req.FundingCurrency {
+		quoteID = req.Quote.FundingLeg.QuoteID
+	}
+	if quoteID == "" {
+		amount := req.Amount
+		if req.Quote != nil && req.Quote.TotalCost > 0 {
+			amount = req.Quote.TotalCost
+		}
+		leg, err := w.quoteFunding(ctx, req, amount)
+		if err != nil {
+			return err
+		}
+		quoteID = leg.QuoteID
+	}
+
+	resp, err := w.makeRequest(ctx, "POST", "/v2/profiles/"+w.ProfileID+"/balance-movements", map[string]interface{}{
+		"quoteId": quoteID,
+	})
+	if err != nil {
+		return fmt.Errorf("wise funding conversion %s to %s: %w", req.FundingCurrency, req.FromCurrency, err)
+	}
+	resp.Body.Close()
+	return nil
+}
@@ -0,0 +1,153 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// recordingServer answers each request with the first response whose path
+// suffix matches and keeps the decoded JSON bodies by path
+type recordingServer struct {
+	*httptest.Server
+	mu     sync.Mutex
+	bodies map[string]map[string]interface{}
+}
+
+func newRecordingServer(t *testing.T, responses map[string]string) *recordingServer {
+	t.Helper()
+	rs := &recordingServer{bodies: make(map[string]map[string]interface{})}
+	rs.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body map[string]interface{}
+		json.NewDecoder(r.Body).Decode(&body)
+		rs.mu.Lock()
+		rs.bodies[r.URL.Path] = body
+		rs.mu.Unlock()
+		for suffix, resp := range responses {
+			if strings.HasSuffix(r.URL.Path, suffix) {
+				fmt.Fprint(w, resp)
+				return
+			}
+		}
+		http.NotFound(w, r)
+	}))
+	t.Cleanup(rs.Close)
+	return rs
+}
+
+func (rs *recordingServer) body(path string) map[string]interface{} {
+	rs.mu.Lock()
+	defer rs.mu.Unlock()
+	return rs.bodies[path]
+}
+
+func TestFundingMethodSentOnQuoteAndSend(t *testing.T) {
+	ctx := context.Background()
+	req := TransactionRequest{
+		Recipient:     Recipient{ID: "r1", Address: Address{CountryCode: "PH"}},
+		Amount:        500,
+		FromCurrency:  USD,
+		ToCurrency:    PHP,
+		PaymentMethod: PaymentCash,
+		FundingMethod: PaymentCard,
+		Reference:     "REF-1",
+	}
+
+	t.Run("worldremit", func(t *testing.T) {
+		srv := newRecordingServer(t, map[string]string{
+			"/v1/quotes":       `{"quoteId":"q1","exchangeRate":56,"fee":3}`,
+			"/v1/transactions": `{"id":"t1","status":"PENDING","fee":3,"exchangeRate":56}`,
+		})
+		wr := NewWorldRemitProvider("key", "secret")
+		wr.BaseURL = srv.URL
+		if _, err := wr.GetQuote(ctx, req); err != nil {
+			t.Fatalf("quote: %v", err)
+		}
+		if _, err := wr.SendMoney(ctx, req); err != nil {
+			t.Fatalf("send: %v", err)
+		}
+		quoted, sent := srv.body("/v1/quotes")["paymentMethod"], srv.body("/v1/transactions")["paymentMethod"]
+		if quoted != "CARD" || sent != quoted {
+			t.Errorf("paymentMethod quoted %v, sent %v; want CARD on both", quoted, sent)
+		}
+		for _, path := range []string{"/v1/quotes", "/v1/transactions"} {
+			if got := srv.body(path)["payoutMethod"]; got != "CASH_PICKUP" {
+				t.Errorf("%s payoutMethod = %v, want CASH_PICKUP", path, got)
+			}
+		}
+	})
+
+	t.Run("xoom", func(t *testing.T) {
+		srv := newRecordingServer(t, map[string]string{
+			"/quotes":    `{"quote_id":"q1","exchange_rate":56,"fee":3,"receive_amount":27832}`,
+			"/transfers": `{"id":"t1","status":"PENDING","send_amount":500,"fee":3,"exchange_rate":56}`,
+		})
+		x := NewXoomProvider("id", "secret")
+		x.BaseURL = srv.URL
+		x.TokenSource = StaticTokenSource("token")
+		if _, err := x.GetQuote(ctx, req); err != nil {
+			t.Fatalf("quote: %v", err)
+		}
+		if _, err := x.SendMoney(ctx, req); err != nil {
+			t.Fatalf("send: %v", err)
+		}
+		quoted := srv.body("/v1/remittances/quotes")["funding_source"]
+		sent := srv.body("/v1/remittances/transfers")["funding_source"]
+		if quoted != "DEBIT_CARD" || sent != quoted {
+			t.Errorf("funding_source quoted %v, sent %v; want DEBIT_CARD on both", quoted, sent)
+		}
+	})
+}
+
+func TestFundingMethodIndependentOfPayout(t *testing.T) {
+	req := TransactionRequest{PaymentMethod: PaymentWallet, FundingMethod: PaymentCard}
+	if got := req.fundingMethod(); got != PaymentCard {
+		t.Errorf("fundingMethod() = %q, want CARD", got)
+	}
+	req.FundingMethod = ""
+	if got := xoomFundingSource(req); got != "PAYPAL_BALANCE" {
+		t.Errorf("xoomFundingSource with no funding method = %q, want PAYPAL_BALANCE", got)
+	}
+}
+
+func TestWiseFundingQuote(t *testing.T) {
+	tests := []struct {
+		name    string
+		body    string
+		wantID  string
+		wantErr bool
+	}{
+		{"numeric id", `{"id":12345,"sourceAmount":466.2,"targetAmount":505,"rate":1.0832,"fee":0.6}`, "12345", false},
+		{"string id", `{"id":"q-77","sourceAmount":466.2,"targetAmount":505,"rate":1.0832,"fee":0.6}`, "q-77", false},
+		{"no source amount", `{"id":1,"targetAmount":505,"rate":1.0832,"fee":0.6}`, "", true},
+		{"no rate", `{"id":1,"sourceAmount":466.2,"targetAmount":505,"fee":0.6}`, "", true},
+	}
+	req := testRequest()
+	req.FundingCurrency = EUR
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := newRecordingServer(t, map[string]string{"/v1/quotes": tt.body})
+			w := NewWiseProvider("key", "profile")
+			w.BaseURL = srv.URL
+
+			leg, err := w.quoteFunding(context.Background(), req, 505)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("accepted %s as a leg costing %v", tt.body, leg.Amount)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if leg.QuoteID != tt.wantID || leg.Amount != 466.2 {
+				t.Errorf("leg = %+v, want quote %s for 466.2", leg, tt.wantID)
+			}
+		})
+	}
+}
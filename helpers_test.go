@@ -0,0 +1,55 @@
+package main
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+// testRequest is a valid USD to INR bank transfer the simulated providers
+// all accept
+func testRequest() TransactionRequest {
+	dob := time.Now().AddDate(-30, 0, 0)
+	return TransactionRequest{
+		SenderID:     "sender-1",
+		Amount:       500,
+		FromCurrency: USD,
+		ToCurrency:   INR,
+		Reference:    "REF-TEST",
+		Sender:       &SenderProfile{CountryOfResidence: "US", DateOfBirth: &dob},
+		Recipient: Recipient{
+			Name:        "Asha Rao",
+			Currency:    INR,
+			Address:     Address{Country: "India", CountryCode: "IN"},
+			BankDetails: map[string]string{"ifsc": "HDFC0000001", "account_number": "123456789"},
+		},
+	}
+}
+
+// blockingProvider quotes like the simulated WorldRemit, but GetQuote
+// ignores its context and hangs until the test ends, like a provider that
+// is slow to notice cancellation
+type blockingProvider struct {
+	*SimulatedProvider
+	release chan struct{}
+	started chan struct{}
+}
+
+func newBlockingProvider(t *testing.T) *blockingProvider {
+	b := &blockingProvider{
+		SimulatedProvider: newSimulatedWorldRemit(),
+		release:           make(chan struct{}),
+		started:           make(chan struct{}, 1),
+	}
+	t.Cleanup(func() { close(b.release) })
+	return b
+}
+
+func (b *blockingProvider) GetQuote(ctx context.Context, req TransactionRequest) (*RemittanceQuote, error) {
+	select {
+	case b.started <- struct{}{}:
+	default:
+	}
+	<-b.release
+	return b.SimulatedProvider.GetQuote(ctx, req)
+}
@@ -0,0 +1,90 @@
+package main
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"strconv"
+	"time"
+)
+
+// defaultHistoryLimit is the page size used when HistoryOptions.Limit is 0
+const defaultHistoryLimit = 50
+
+// HistoryOptions selects a page of a provider's transaction history.
+// Cursor is opaque: pass "" for the first page and then each page's
+// NextCursor. Providers paginate differently (offsets, page numbers,
+// cursor tokens), and the cursor hides which one is in use, so callers
+// must not build or parse it.
+type HistoryOptions struct {
+	Cursor string
+	// Limit is the page size; 0 means defaultHistoryLimit. Providers may
+	// return fewer.
+	Limit int
+	// Since and Until bound the creation time; zero means unbounded
+	Since time.Time
+	Until time.Time
+}
+
+func (o HistoryOptions) limit() int {
+	if o.Limit > 0 {
+		return o.Limit
+	}
+	return defaultHistoryLimit
+}
+
+// HistoryPage is one page of transaction history. NextCursor is empty on
+// the last page, so iterating until it is empty yields every transaction.
+type HistoryPage struct {
+	Transactions []*TransactionResponse
+	NextCursor   string
+}
+
+// HistoryProvider is implemented by providers that can list past transfers
+type HistoryProvider interface {
+	GetTransactionHistory(ctx context.Context, opts HistoryOptions) (*HistoryPage, error)
+}
+
+// GetTransactionHistory returns one page of the named provider's history.
+// Providers that can't list transfers return ErrUnsupported.
+func (rh *RemittanceHub) GetTransactionHistory(ctx context.Context, providerName string, opts HistoryOptions) (*HistoryPage, error) {
+	provider, err := rh.findProvider(providerName)
+	if err != nil {
+		return nil, err
+	}
+	lister, ok := provider.(HistoryProvider)
+	if !ok {
+		return nil, &UnsupportedError{Provider: providerName, Operation: "transaction history"}
+	}
+	return lister.GetTransactionHistory(ctx, opts)
+}
+
+// encodeOffsetCursor wraps an offset for providers that paginate by
+// position, so it reads as an opaque token like any other cursor
+func encodeOffsetCursor(offset int) string {
+	return base64.RawURLEncoding.EncodeToString([]byte("offset:" + strconv.Itoa(offset)))
+}
+
+// decodeOffsetCursor reverses encodeOffsetCursor; "" is offset 0
+func decodeOffsetCursor(cursor string) (int, error) {
+	if cursor == "" {
+		return 0, nil
+	}
+	raw, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err == nil {
+		var offset int
+		if _, err = fmt.Sscanf(string(raw), "offset:%d", &offset); err == nil && offset >= 0 {
+			return offset, nil
+		}
+	}
+	return 0, fmt.Errorf("invalid history cursor %q", cursor)
+}
+
+// nextOffsetCursor returns the cursor after a page of n items starting at
+// offset, or "" when a short page shows there are no more
+func nextOffsetCursor(offset, n, limit int) string {
+	if n < limit {
+		return ""
+	}
+	return encodeOffsetCursor(offset + n)
+}
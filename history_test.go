@@ -0,0 +1,150 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"testing"
+)
+
+// collectHistory follows NextCursor through the hub until it comes back
+// empty, failing the test if it doesn't within maxPages
+func collectHistory(t *testing.T, hub *RemittanceHub, provider string, limit, maxPages int) (ids []string, pages int) {
+	t.Helper()
+	opts := HistoryOptions{Limit: limit}
+	for {
+		page, err := hub.GetTransactionHistory(context.Background(), provider, opts)
+		if err != nil {
+			t.Fatalf("page %d: %v", pages+1, err)
+		}
+		pages++
+		for _, tx := range page.Transactions {
+			ids = append(ids, tx.TransactionID)
+		}
+		if page.NextCursor == "" {
+			return ids, pages
+		}
+		if pages == maxPages {
+			t.Fatalf("still paging after %d pages", maxPages)
+		}
+		opts.Cursor = page.NextCursor
+	}
+}
+
+func TestWiseHistoryPagesByOffset(t *testing.T) {
+	tests := []struct {
+		name      string
+		total     int
+		wantPages int
+	}{
+		{"short last page", 5, 3},
+		// A full last page can't show it's the last; one empty page ends it
+		{"exact multiple", 4, 3},
+		{"empty", 0, 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var mu sync.Mutex
+			var offsets []string
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				mu.Lock()
+				offsets = append(offsets, r.URL.Query().Get("offset"))
+				mu.Unlock()
+				offset, _ := strconv.Atoi(r.URL.Query().Get("offset"))
+				limit, _ := strconv.Atoi(r.URL.Query().Get("limit"))
+				transfers := []WiseTransferResponse{}
+				for i := offset; i < tt.total && i < offset+limit; i++ {
+					transfers = append(transfers, WiseTransferResponse{ID: WiseID(strconv.Itoa(i)), Status: "outgoing_payment_sent"})
+				}
+				json.NewEncoder(w).Encode(transfers)
+			}))
+			defer srv.Close()
+			wise := NewWiseProvider("key", "profile")
+			wise.BaseURL = srv.URL
+			hub := NewRemittanceHub()
+			hub.AddProvider(wise)
+
+			ids, pages := collectHistory(t, hub, wise.GetName(), 2, 10)
+			if pages != tt.wantPages {
+				t.Errorf("fetched %d pages, want %d", pages, tt.wantPages)
+			}
+			if len(ids) != tt.total {
+				t.Fatalf("got %d transfers, want %d: %v", len(ids), tt.total, ids)
+			}
+			for i, id := range ids {
+				if id != strconv.Itoa(i) {
+					t.Errorf("transfer %d is %s; a cursor skipped or repeated a page", i, id)
+				}
+			}
+			for i, offset := range offsets {
+				if offset != strconv.Itoa(i*2) {
+					t.Errorf("request %d asked for offset %s, want %d", i, offset, i*2)
+				}
+			}
+		})
+	}
+}
+
+func TestXoomHistoryPassesCursorThrough(t *testing.T) {
+	next := map[string]string{"": "tok-2", "tok-2": "tok-3", "tok-3": ""}
+	var mu sync.Mutex
+	var cursors []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		cursor := r.URL.Query().Get("cursor")
+		mu.Lock()
+		cursors = append(cursors, cursor)
+		mu.Unlock()
+		fmt.Fprintf(w, `{"transfers":[{"id":"t-%s","status":"COMPLETED"}],"next_cursor":%q}`, cursor, next[cursor])
+	}))
+	defer srv.Close()
+	x := NewXoomProvider("id", "secret")
+	x.BaseURL = srv.URL
+	x.TokenSource = StaticTokenSource("token")
+	hub := NewRemittanceHub()
+	hub.AddProvider(x)
+
+	ids, pages := collectHistory(t, hub, x.GetName(), 1, 10)
+	if pages != 3 || len(ids) != 3 {
+		t.Fatalf("got %d pages, transfers %v; want 3 of each", pages, ids)
+	}
+	if want := []string{"", "tok-2", "tok-3"}; fmt.Sprint(cursors) != fmt.Sprint(want) {
+		t.Errorf("cursors sent %q, want %q", cursors, want)
+	}
+}
+
+func TestSimulatedHistoryPagesNewestFirst(t *testing.T) {
+	ctx := context.Background()
+	sim := newSimulatedRemitly()
+	hub := NewRemittanceHub()
+	hub.AddProvider(sim)
+	var sent []string
+	for i := 0; i < 5; i++ {
+		req := testRequest()
+		req.Reference = fmt.Sprintf("REF-HISTORY-%d", i)
+		tx, err := sim.SendMoney(ctx, req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		sent = append([]string{tx.TransactionID}, sent...)
+	}
+
+	ids, pages := collectHistory(t, hub, sim.GetName(), 2, 10)
+	if pages != 3 {
+		t.Errorf("fetched %d pages, want 3", pages)
+	}
+	if fmt.Sprint(ids) != fmt.Sprint(sent) {
+		t.Errorf("history = %v, want %v", ids, sent)
+	}
+}
+
+func TestHistoryRejectsForeignCursor(t *testing.T) {
+	hub := NewRemittanceHub()
+	hub.AddProvider(newSimulatedRemitly())
+	if _, err := hub.GetTransactionHistory(context.Background(), "Remitly", HistoryOptions{Cursor: "tok-2"}); err == nil {
+		t.Error("a cursor that isn't an offset token was accepted")
+	}
+}
@@ -0,0 +1,72 @@
+package main
+
+import (
+	"strings"
+	"time"
+)
+
+// HolidayCalendar decides which days banks in a country process payouts.
+// date is interpreted in its own location, which EstimateArrival sets to
+// the recipient country's timezone.
+type HolidayCalendar interface {
+	IsBusinessDay(country string, date time.Time) bool
+}
+
+// BankHolidays is a HolidayCalendar of Saturday/Sunday weekends plus the
+// listed holidays per ISO country code. An entry is either "MM-DD" for a
+// holiday on the same date every year or "YYYY-MM-DD" for a single date,
+// which is how movable feasts and weekend substitutes are given.
+type BankHolidays map[string][]string
+
+func (b BankHolidays) IsBusinessDay(country string, date time.Time) bool {
+	if wd := date.Weekday(); wd == time.Saturday || wd == time.Sunday {
+		return false
+	}
+	full := date.Format("2006-01-02")
+	for _, holiday := range b[strings.ToUpper(country)] {
+		if holiday == full || holiday == full[5:] {
+			return false
+		}
+	}
+	return true
+}
+
+// DefaultHolidayCalendar is used when no calendar is configured. It covers
+// national bank holidays in the main payout markets through 2027; India's
+// movable and state-level holidays are not included.
+var DefaultHolidayCalendar = DefaultBankHolidays()
+
+// DefaultBankHolidays returns a fresh copy of the seeded holiday table, so
+// a deployment can add or replace countries before passing it to
+// SetHolidayCalendar.
+func DefaultBankHolidays() BankHolidays {
+	return BankHolidays{
+		"US": {
+			"01-01", "06-19", "07-04", "11-11", "12-25",
+			"2026-01-19", "2026-02-16", "2026-05-25", "2026-09-07", "2026-10-12", "2026-11-26",
+			"2027-01-18", "2027-02-15", "2027-05-31", "2027-07-05", "2027-09-06", "2027-10-11", "2027-11-25",
+		},
+		"GB": {
+			"01-01", "12-25", "12-26",
+			"2026-04-03", "2026-04-06", "2026-05-04", "2026-05-25", "2026-08-31", "2026-12-28",
+			"2027-03-26", "2027-03-29", "2027-05-03", "2027-05-31", "2027-08-30", "2027-12-27", "2027-12-28",
+		},
+		"IN": {"01-26", "08-15", "10-02", "12-25"},
+		"PH": {
+			"01-01", "04-09", "05-01", "06-12", "08-21", "11-01", "11-30", "12-25", "12-30",
+			"2026-04-02", "2026-04-03", "2026-08-31",
+			"2027-03-25", "2027-03-26", "2027-08-30",
+		},
+		"MX": {
+			"01-01", "05-01", "09-16", "12-25",
+			"2026-02-02", "2026-03-16", "2026-04-02", "2026-04-03", "2026-11-16",
+			"2027-02-01", "2027-03-15", "2027-03-25", "2027-03-26", "2027-11-15",
+		},
+	}
+}
+
+// SetHolidayCalendar replaces the calendar used for quote arrival
+// estimates. Call it before serving traffic.
+func (rh *RemittanceHub) SetHolidayCalendar(cal HolidayCalendar) {
+	rh.holidays = cal
+}
@@ -0,0 +1,76 @@
+package main
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+)
+
+// TransactionFinder is implemented by providers that can look a transfer up
+// by the idempotency key it was created with
+type TransactionFinder interface {
+	// FindTransactionByIdempotencyKey returns ErrTransactionNotFound when the
+	// provider has no transfer for key
+	FindTransactionByIdempotencyKey(ctx context.Context, key string) (*TransactionResponse, error)
+}
+
+// idempotencyKey is the key sent to providers: IdempotencyKey, or Reference
+// when the caller didn't set one
+func (r TransactionRequest) idempotencyKey() string {
+	if r.IdempotencyKey != "" {
+		return r.IdempotencyKey
+	}
+	return r.Reference
+}
+
+// ensureIdempotencyKey gives a request with neither IdempotencyKey nor
+// Reference a random key, so no provider is sent an empty one. The hub
+// sets it once per send, before the provider is called, so a lookup after
+// a failed send uses the same key.
+func (r *TransactionRequest) ensureIdempotencyKey() {
+	if r.idempotencyKey() != "" {
+		return
+	}
+	var b [16]byte
+	rand.Read(b[:])
+	r.IdempotencyKey = "xp-" + hex.EncodeToString(b[:])
+}
+
+// checkIdempotencyKey rejects an empty lookup key, which providers would
+// treat as no filter at all and answer with an unrelated transfer
+func checkIdempotencyKey(key string) error {
+	if key == "" {
+		return &ValidationError{Field: "idempotency_key", Err: errors.New("is required")}
+	}
+	return nil
+}
+
+// FindTransactionByIdempotencyKey asks a provider whether it already created
+// a transfer for key. It is the recovery path for sends that reached the
+// provider but were never recorded locally (e.g. the process died before
+// SendMoney returned): on startup, check each unrecorded key here before
+// retrying, and a hit is saved to the hub's store like a status update.
+// Providers that can't search by key return ErrUnsupported.
+func (rh *RemittanceHub) FindTransactionByIdempotencyKey(ctx context.Context, providerName, key string) (*TransactionResponse, error) {
+	if err := checkIdempotencyKey(key); err != nil {
+		return nil, err
+	}
+	provider, err := rh.findProvider(providerName)
+	if err != nil {
+		return nil, err
+	}
+	finder, ok := provider.(TransactionFinder)
+	if !ok {
+		return nil, &UnsupportedError{Provider: providerName, Operation: "idempotency key lookup"}
+	}
+
+	tx, err := finder.FindTransactionByIdempotencyKey(ctx, key)
+	if err != nil {
+		return nil, err
+	}
+	tx.normalizeError()
+	tx.fillProgress()
+	rh.recordStatus(ctx, providerName, tx)
+	return tx, nil
+}
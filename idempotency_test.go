@@ -0,0 +1,40 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestXoomIdempotencyLookupChecksKey(t *testing.T) {
+	tests := []struct {
+		name    string
+		list    string
+		wantID  string
+		wantErr error
+	}{
+		{"matching key", `{"transfers":[{"id":"t-other","idempotency_key":"key-2"},{"id":"t-mine","idempotency_key":"key-1"}]}`, "t-mine", nil},
+		{"only another key", `{"transfers":[{"id":"t-other","idempotency_key":"key-2"}]}`, "", ErrTransactionNotFound},
+		{"no key on transfer", `{"transfers":[{"id":"t-unknown"}]}`, "", ErrTransactionNotFound},
+		{"empty list", `{"transfers":[]}`, "", ErrTransactionNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := newRecordingServer(t, map[string]string{"/transfers": tt.list})
+			x := NewXoomProvider("id", "secret")
+			x.BaseURL = srv.URL
+			x.TokenSource = StaticTokenSource("token")
+
+			tx, err := x.FindTransactionByIdempotencyKey(context.Background(), "key-1")
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("err = %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil || tx.TransactionID != tt.wantID {
+				t.Fatalf("got %v, %v; want %s", tx, err, tt.wantID)
+			}
+		})
+	}
+}
@@ -0,0 +1,32 @@
+package main
+
+import "net/http"
+
+// DefaultUserAgent identifies this library to providers that haven't been
+// given a User-Agent of their own
+const DefaultUserAgent = "xchngpassport/1.0"
+
+// ClientIdentity is how a provider client identifies itself upstream: its
+// User-Agent and any client identification headers the API requires, such
+// as a registered client or partner ID. Some APIs rate-limit anonymous Go
+// clients harder than identified ones.
+type ClientIdentity struct {
+	// UserAgent defaults to DefaultUserAgent
+	UserAgent string `json:"user_agent,omitempty"`
+	// Headers are set on every request, e.g. {"X-Client-Id": "acme-prod"}.
+	// They can't replace the provider's own auth or signature headers.
+	Headers map[string]string `json:"headers,omitempty"`
+}
+
+func (c ClientIdentity) userAgent() string {
+	return headerOrDefault(c.UserAgent, DefaultUserAgent)
+}
+
+// apply sets the identity headers on req. Call it before the provider's
+// auth headers so those always win.
+func (c ClientIdentity) apply(req *http.Request) {
+	for name, value := range c.Headers {
+		req.Header.Set(name, value)
+	}
+	req.Header.Set("User-Agent", c.userAgent())
+}
@@ -0,0 +1,102 @@
+package main
+
+import (
+	"fmt"
+	"math/rand"
+)
+
+// LoadBalancer spreads volume across providers that price a transfer
+// almost the same, for redundancy and to stay clear of per-provider limits,
+// instead of always sending through the single cheapest.
+//
+// Among quotes whose cost is within Tolerance of the best one, a provider
+// is picked at random in proportion to its weight. It applies to the
+// EffectiveCost and LowestCost strategies; other strategies still take the
+// best quote.
+type LoadBalancer struct {
+	// Tolerance is how much more than the best quote a quote may cost and
+	// still be picked, as a fraction of the send amount (0.002 = 0.2%)
+	Tolerance float64
+	// Weights sets each provider's share of the picks; unlisted providers
+	// weigh 1 and a weight of 0 keeps a provider out unless it is the best
+	Weights map[string]float64
+
+	// random returns a value in [0, 1); nil uses math/rand
+	random func() float64
+}
+
+// Validate rejects settings that would make picks meaningless
+func (lb *LoadBalancer) Validate() error {
+	if lb.Tolerance < 0 {
+		return fmt.Errorf("load balancer tolerance must not be negative, got %v", lb.Tolerance)
+	}
+	for provider, weight := range lb.Weights {
+		if weight < 0 {
+			return fmt.Errorf("load balancer weight for %s must not be negative, got %v", provider, weight)
+		}
+	}
+	return nil
+}
+
+func (lb *LoadBalancer) weight(provider string) float64 {
+	if weight, ok := lb.Weights[provider]; ok {
+		return weight
+	}
+	return 1
+}
+
+// pick chooses from quotes, which must be sorted best-first under strategy
+func (lb *LoadBalancer) pick(quotes []*RemittanceQuote, strategy SortStrategy) *RemittanceQuote {
+	best := quotes[0]
+	var cost func(*RemittanceQuote) float64
+	switch strategy {
+	case EffectiveCost:
+		cost = func(q *RemittanceQuote) float64 { return q.EffectiveCost }
+	case LowestCost:
+		cost = func(q *RemittanceQuote) float64 { return q.cost() }
+	default:
+		return best
+	}
+
+	limit := cost(best) + lb.Tolerance*best.Amount + precisionEpsilon
+	var candidates []*RemittanceQuote
+	var total float64
+	for _, q := range quotes {
+		if cost(q) > limit {
+			continue
+		}
+		if w := lb.weight(q.Provider); w > 0 {
+			candidates = append(candidates, q)
+			total += w
+		}
+	}
+	if total == 0 {
+		return best
+	}
+
+	random := lb.random
+	if random == nil {
+		random = rand.Float64
+	}
+	r := random() * total
+	for _, q := range candidates {
+		r -= lb.weight(q.Provider)
+		if r < 0 {
+			return q
+		}
+	}
+	return candidates[len(candidates)-1]
+}
+
+// SetLoadBalancer spreads GetBestQuote and GetBestQuoteBy picks across
+// near-equal providers. Call it before serving traffic; nil restores strict
+// best-cost selection.
+func (rh *RemittanceHub) SetLoadBalancer(lb *LoadBalancer) error {
+	if lb != nil {
+		if err := lb.Validate(); err != nil {
+			return err
+		}
+	}
+	rh.balancer = lb
+	return nil
+}
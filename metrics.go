@@ -0,0 +1,206 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Metrics receives operational events from the hub and provider HTTP calls.
+// Implementations must be safe for concurrent use.
+type Metrics interface {
+	QuoteRequested(provider string)
+	QuoteSucceeded(provider string)
+	QuoteFailed(provider string)
+	// ObserveUpstreamLatency records one provider HTTP call; statusCode is 0
+	// when no response was received
+	ObserveUpstreamLatency(provider, method string, statusCode int, d time.Duration)
+	TransferSent(provider string, status TransactionStatus)
+}
+
+type noopMetrics struct{}
+
+func (noopMetrics) QuoteRequested(provider string)                                                  {}
+func (noopMetrics) QuoteSucceeded(provider string)                                                  {}
+func (noopMetrics) QuoteFailed(provider string)                                                     {}
+func (noopMetrics) ObserveUpstreamLatency(provider, method string, statusCode int, d time.Duration) {}
+func (noopMetrics) TransferSent(provider string, status TransactionStatus)                          {}
+
+// MetricsMiddleware times every provider HTTP call and reports it to m
+func MetricsMiddleware(m Metrics) Middleware {
+	return metricsMiddleware(func() Metrics { return m })
+}
+
+// metricsMiddleware reports to whatever metrics returns at the time of each
+// call, so the hub can swap its target without installing another
+func metricsMiddleware(metrics func() Metrics) Middleware {
+	return func(next RoundTripFunc) RoundTripFunc {
+		return func(req *http.Request) (*http.Response, error) {
+			start := time.Now()
+			resp, err := next(req)
+
+			statusCode := 0
+			if resp != nil {
+				statusCode = resp.StatusCode
+			}
+			metrics().ObserveUpstreamLatency(ProviderNameFromContext(req.Context()), req.Method, statusCode, time.Since(start))
+			return resp, err
+		}
+	}
+}
+
+// Metric names exported by PrometheusMetrics
+const (
+	metricQuotesRequested = "remittance_quotes_requested_total"
+	metricQuotesSucceeded = "remittance_quotes_succeeded_total"
+	metricQuotesFailed    = "remittance_quotes_failed_total"
+	metricTransfersSent   = "remittance_transfers_sent_total"
+	metricUpstreamLatency = "remittance_upstream_request_duration_seconds"
+)
+
+var metricHelp = map[string]string{
+	metricQuotesRequested: "Quotes requested from each provider.",
+	metricQuotesSucceeded: "Quotes successfully returned by each provider.",
+	metricQuotesFailed:    "Quote requests that failed per provider.",
+	metricTransfersSent:   "Transfers submitted per provider by resulting status.",
+	metricUpstreamLatency: "Latency of provider HTTP calls.",
+}
+
+// DefaultLatencyBuckets are the upper bounds, in seconds, of the upstream
+// latency histogram (the Prometheus client defaults)
+var DefaultLatencyBuckets = []float64{.005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5, 10}
+
+// PrometheusMetrics implements Metrics in memory and serves the values in
+// the Prometheus text exposition format, so it can be mounted on /metrics
+// without depending on the Prometheus client library.
+type PrometheusMetrics struct {
+	mu         sync.Mutex
+	buckets    []float64
+	counters   map[string]map[string]float64 // metric -> rendered labels -> value
+	histograms map[string]*latencyHistogram  // rendered labels -> histogram
+}
+
+type latencyHistogram struct {
+	counts []uint64 // per bucket, non-cumulative
+	count  uint64
+	sum    float64
+}
+
+// NewPrometheusMetrics creates an empty registry using DefaultLatencyBuckets
+func NewPrometheusMetrics() *PrometheusMetrics {
+	return &PrometheusMetrics{
+		buckets:    DefaultLatencyBuckets,
+		counters:   make(map[string]map[string]float64),
+		histograms: make(map[string]*latencyHistogram),
+	}
+}
+
+func (p *PrometheusMetrics) inc(metric string, labels ...string) {
+	key := renderLabels(labels...)
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.counters[metric] == nil {
+		p.counters[metric] = make(map[string]float64)
+	}
+	p.counters[metric][key]++
+}
+
+func (p *PrometheusMetrics) QuoteRequested(provider string) {
+	p.inc(metricQuotesRequested, "provider", provider)
+}
+
+func (p *PrometheusMetrics) QuoteSucceeded(provider string) {
+	p.inc(metricQuotesSucceeded, "provider", provider)
+}
+
+func (p *PrometheusMetrics) QuoteFailed(provider string) {
+	p.inc(metricQuotesFailed, "provider", provider)
+}
+
+func (p *PrometheusMetrics) TransferSent(provider string, status TransactionStatus) {
+	p.inc(metricTransfersSent, "provider", provider, "status", string(status))
+}
+
+func (p *PrometheusMetrics) ObserveUpstreamLatency(provider, method string, statusCode int, d time.Duration) {
+	key := renderLabels("provider", provider, "method", method, "code", strconv.Itoa(statusCode))
+	seconds := d.Seconds()
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	h := p.histograms[key]
+	if h == nil {
+		h = &latencyHistogram{counts: make([]uint64, len(p.buckets))}
+		p.histograms[key] = h
+	}
+	for i, bound := range p.buckets {
+		if seconds <= bound {
+			h.counts[i]++
+			break
+		}
+	}
+	h.count++
+	h.sum += seconds
+}
+
+// ServeHTTP writes all metrics in the Prometheus text format
+func (p *PrometheusMetrics) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4")
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	for _, metric := range []string{metricQuotesRequested, metricQuotesSucceeded, metricQuotesFailed, metricTransfersSent} {
+		series := p.counters[metric]
+		if len(series) == 0 {
+			continue
+		}
+		fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s counter\n", metric, metricHelp[metric], metric)
+		for _, labels := range sortedKeys(series) {
+			fmt.Fprintf(w, "%s{%s} %g\n", metric, labels, series[labels])
+		}
+	}
+
+	if len(p.histograms) == 0 {
+		return
+	}
+	fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s histogram\n", metricUpstreamLatency, metricHelp[metricUpstreamLatency], metricUpstreamLatency)
+	keys := make([]string, 0, len(p.histograms))
+	for labels := range p.histograms {
+		keys = append(keys, labels)
+	}
+	sort.Strings(keys)
+	for _, labels := range keys {
+		h := p.histograms[labels]
+		var cumulative uint64
+		for i, bound := range p.buckets {
+			cumulative += h.counts[i]
+			fmt.Fprintf(w, "%s_bucket{%s,le=\"%g\"} %d\n", metricUpstreamLatency, labels, bound, cumulative)
+		}
+		fmt.Fprintf(w, "%s_bucket{%s,le=\"+Inf\"} %d\n", metricUpstreamLatency, labels, h.count)
+		fmt.Fprintf(w, "%s_sum{%s} %g\n", metricUpstreamLatency, labels, h.sum)
+		fmt.Fprintf(w, "%s_count{%s} %d\n", metricUpstreamLatency, labels, h.count)
+	}
+}
+
+// renderLabels formats alternating name/value pairs as a Prometheus label set
+func renderLabels(pairs ...string) string {
+	parts := make([]string, 0, len(pairs)/2)
+	for i := 0; i+1 < len(pairs); i += 2 {
+		parts = append(parts, fmt.Sprintf("%s=%s", pairs[i], strconv.Quote(pairs[i+1])))
+	}
+	return strings.Join(parts, ",")
+}
+
+func sortedKeys(m map[string]float64) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
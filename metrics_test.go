@@ -0,0 +1,46 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// upstreamCounter counts provider HTTP calls and ignores hub events
+type upstreamCounter struct {
+	noopMetrics
+	calls atomic.Int32
+}
+
+func (c *upstreamCounter) ObserveUpstreamLatency(provider, method string, statusCode int, d time.Duration) {
+	c.calls.Add(1)
+}
+
+func TestSetMetricsTwiceReportsOnce(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `[]`)
+	}))
+	defer srv.Close()
+	wise := NewWiseProvider("key", "profile")
+	wise.BaseURL = srv.URL
+	hub := NewRemittanceHub()
+	hub.AddProvider(wise)
+
+	first, second := &upstreamCounter{}, &upstreamCounter{}
+	hub.SetMetrics(first)
+	hub.SetMetrics(second)
+	if _, err := hub.GetTransactionHistory(context.Background(), wise.GetName(), HistoryOptions{}); err != nil {
+		t.Fatal(err)
+	}
+
+	if n := first.calls.Load(); n != 0 {
+		t.Errorf("replaced metrics saw %d calls, want 0", n)
+	}
+	if n := second.calls.Load(); n != 1 {
+		t.Errorf("current metrics saw %d calls, want 1", n)
+	}
+}
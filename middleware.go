@@ -0,0 +1,68 @@
+package main
+
+import (
+	"context"
+	"net/http"
+)
+
+// RoundTripFunc performs a single outbound HTTP exchange
+type RoundTripFunc func(*http.Request) (*http.Response, error)
+
+// Middleware wraps a RoundTripFunc to run code around every provider HTTP
+// call: header injection, tracing, metrics, recording and so on.
+type Middleware func(next RoundTripFunc) RoundTripFunc
+
+// MiddlewareUser is implemented by providers that make HTTP calls and accept
+// middleware. Use must be called before the provider starts serving requests.
+type MiddlewareUser interface {
+	Use(middleware ...Middleware)
+}
+
+// chainMiddleware builds the call stack; the first middleware is outermost.
+func chainMiddleware(base RoundTripFunc, middleware []Middleware) RoundTripFunc {
+	next := base
+	for i := len(middleware) - 1; i >= 0; i-- {
+		next = middleware[i](next)
+	}
+	return next
+}
+
+// RequestInterceptor runs fn on every outbound request before it is sent.
+// A non-nil error aborts the call.
+func RequestInterceptor(fn func(*http.Request) error) Middleware {
+	return func(next RoundTripFunc) RoundTripFunc {
+		return func(req *http.Request) (*http.Response, error) {
+			if err := fn(req); err != nil {
+				return nil, err
+			}
+			return next(req)
+		}
+	}
+}
+
+// ResponseInterceptor runs fn after every call with its outcome. fn must not
+// consume resp.Body.
+func ResponseInterceptor(fn func(*http.Request, *http.Response, error)) Middleware {
+	return func(next RoundTripFunc) RoundTripFunc {
+		return func(req *http.Request) (*http.Response, error) {
+			resp, err := next(req)
+			fn(req, resp, err)
+			return resp, err
+		}
+	}
+}
+
+type providerNameKey struct{}
+
+// withProviderName tags a request context with the provider making it so
+// middleware shared across providers can tell them apart.
+func withProviderName(ctx context.Context, name string) context.Context {
+	return context.WithValue(ctx, providerNameKey{}, name)
+}
+
+// ProviderNameFromContext returns the provider that issued a request, for
+// use inside Middleware.
+func ProviderNameFromContext(ctx context.Context) string {
+	name, _ := ctx.Value(providerNameKey{}).(string)
+	return name
+}
@@ -0,0 +1,61 @@
+package main
+
+// PayoutNetwork is a specific rail or wallet the recipient is paid through,
+// finer-grained than PaymentMethod
+type PayoutNetwork string
+
+const (
+	NetworkUPI      PayoutNetwork = "UPI"      // India, instant account/VPA payments
+	NetworkIMPS     PayoutNetwork = "IMPS"     // India, instant bank transfers
+	NetworkSPEI     PayoutNetwork = "SPEI"     // Mexico, interbank transfers
+	NetworkGCash    PayoutNetwork = "GCASH"    // Philippines, mobile wallet
+	NetworkMaya     PayoutNetwork = "MAYA"     // Philippines, mobile wallet
+	NetworkInstaPay PayoutNetwork = "INSTAPAY" // Philippines, instant bank transfers
+	NetworkMPesa    PayoutNetwork = "MPESA"    // Kenya, mobile money
+)
+
+// reachesNetwork reports whether the provider can pay out over network in
+// country. An empty network matches any provider; an empty country matches
+// the network in any country.
+func reachesNetwork(provider RemittanceProvider, country string, network PayoutNetwork) bool {
+	if network == "" {
+		return true
+	}
+	for c, networks := range provider.GetCapabilities().PayoutNetworks {
+		if country != "" && c != country {
+			continue
+		}
+		for _, n := range networks {
+			if n == network {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// Wise picks the rail from the recipient account it pays into, so the
+// network is advertised for filtering but not sent in requests
+var wiseNetworks = map[string][]PayoutNetwork{
+	"IN": {NetworkIMPS},
+	"MX": {NetworkSPEI},
+	"PH": {NetworkInstaPay},
+}
+
+var remitlyNetworks = map[string][]PayoutNetwork{
+	"IN": {NetworkUPI, NetworkIMPS},
+	"MX": {NetworkSPEI},
+	"PH": {NetworkGCash, NetworkMaya, NetworkInstaPay},
+}
+
+var worldRemitNetworks = map[string][]PayoutNetwork{
+	"IN": {NetworkUPI, NetworkIMPS},
+	"PH": {NetworkGCash, NetworkInstaPay},
+	"KE": {NetworkMPesa},
+}
+
+var xoomNetworks = map[string][]PayoutNetwork{
+	"IN": {NetworkUPI, NetworkIMPS},
+	"MX": {NetworkSPEI},
+	"PH": {NetworkGCash, NetworkInstaPay},
+}
@@ -0,0 +1,165 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+// tokenExpiryDelta treats a token as expired this long before its stated
+// expiry so an in-flight request never carries a token that lapses mid-call.
+const tokenExpiryDelta = time.Minute
+
+// Token is an OAuth2 access token as used by providers' makeRequest
+type Token struct {
+	AccessToken string
+	TokenType   string
+	// Expiry is zero for tokens that never expire
+	Expiry time.Time
+}
+
+// Valid reports whether the token is present and not about to expire
+func (t *Token) Valid() bool {
+	if t == nil || t.AccessToken == "" {
+		return false
+	}
+	return t.Expiry.IsZero() || time.Now().Add(tokenExpiryDelta).Before(t.Expiry)
+}
+
+// AuthorizationHeader renders the token for the Authorization header
+func (t *Token) AuthorizationHeader() string {
+	tokenType := t.TokenType
+	if tokenType == "" || strings.EqualFold(tokenType, "bearer") {
+		tokenType = "Bearer"
+	}
+	return tokenType + " " + t.AccessToken
+}
+
+// TokenSource supplies access tokens, modelled on golang.org/x/oauth2.
+// Implementations must be safe for concurrent use.
+type TokenSource interface {
+	Token(ctx context.Context) (*Token, error)
+}
+
+type staticTokenSource struct {
+	token *Token
+}
+
+// StaticTokenSource always returns the same non-expiring bearer token
+func StaticTokenSource(accessToken string) TokenSource {
+	return staticTokenSource{token: &Token{AccessToken: accessToken, TokenType: "Bearer"}}
+}
+
+func (s staticTokenSource) Token(ctx context.Context) (*Token, error) {
+	return s.token, nil
+}
+
+// ClientCredentialsTokenSource fetches a new token on every call using the
+// OAuth2 client-credentials grant. Wrap it in ReuseTokenSource to cache.
+type ClientCredentialsTokenSource struct {
+	TokenURL     string
+	ClientID     string
+	ClientSecret string
+	Scopes       []string
+	// HTTPClient defaults to a client with a 30s timeout
+	HTTPClient *http.Client
+	// Identity is sent with token requests
+	Identity ClientIdentity
+}
+
+func (c *ClientCredentialsTokenSource) Token(ctx context.Context) (*Token, error) {
+	form := url.Values{"grant_type": {"client_credentials"}}
+	if len(c.Scopes) > 0 {
+		form.Set("scope", strings.Join(c.Scopes, " "))
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "POST", c.TokenURL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, err
+	}
+	c.Identity.apply(req)
+	req.SetBasicAuth(c.ClientID, c.ClientSecret)
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	setCorrelationID(req)
+
+	client := c.HTTPClient
+	if client == nil {
+		client = newProviderClient(30 * time.Second)
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, redactError(err)
+	}
+	// The token endpoint may echo the client secret back in its error
+	provider := ProviderNameFromContext(ctx)
+	if provider == "" {
+		provider = req.URL.Host
+	}
+	if err := checkResponse(provider, resp, c.ClientSecret); err != nil {
+		return nil, fmt.Errorf("token request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	var tokenResp struct {
+		AccessToken      string `json:"access_token"`
+		TokenType        string `json:"token_type"`
+		ExpiresIn        int64  `json:"expires_in"`
+		Error            string `json:"error"`
+		ErrorDescription string `json:"error_description"`
+	}
+	decodeErr := json.NewDecoder(resp.Body).Decode(&tokenResp)
+	if tokenResp.Error != "" {
+		return nil, fmt.Errorf("token request: %s", RedactSecrets(strings.TrimSpace(tokenResp.Error+" "+tokenResp.ErrorDescription), c.ClientSecret))
+	}
+	if decodeErr != nil {
+		return nil, fmt.Errorf("token request: %w", decodeErr)
+	}
+	if tokenResp.AccessToken == "" {
+		return nil, errors.New("token request: empty access_token in response")
+	}
+
+	token := &Token{AccessToken: tokenResp.AccessToken, TokenType: tokenResp.TokenType}
+	if tokenResp.ExpiresIn > 0 {
+		token.Expiry = time.Now().Add(time.Duration(tokenResp.ExpiresIn) * time.Second)
+	}
+	return token, nil
+}
+
+// reuseTokenSource caches a token until it is close to expiry. Concurrent
+// callers block on the same refresh rather than each hitting the token
+// endpoint.
+type reuseTokenSource struct {
+	mu    sync.Mutex
+	src   TokenSource
+	token *Token
+}
+
+// ReuseTokenSource wraps src so that a token is only fetched when the
+// cached one is missing or near expiry.
+func ReuseTokenSource(src TokenSource) TokenSource {
+	if rts, ok := src.(*reuseTokenSource); ok {
+		return rts
+	}
+	return &reuseTokenSource{src: src}
+}
+
+func (r *reuseTokenSource) Token(ctx context.Context) (*Token, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.token.Valid() {
+		return r.token, nil
+	}
+	token, err := r.src.Token(ctx)
+	if err != nil {
+		return nil, err
+	}
+	r.token = token
+	return token, nil
+}
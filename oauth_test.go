@@ -0,0 +1,47 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// countingTokenSource hands out a fresh hour-long token on every call
+type countingTokenSource struct {
+	calls atomic.Int32
+}
+
+func (c *countingTokenSource) Token(ctx context.Context) (*Token, error) {
+	n := c.calls.Add(1)
+	return &Token{AccessToken: fmt.Sprintf("token-%d", n), TokenType: "Bearer", Expiry: time.Now().Add(time.Hour)}, nil
+}
+
+func TestWiseReusesTokens(t *testing.T) {
+	var auth atomic.Value
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		auth.Store(r.Header.Get("Authorization"))
+		fmt.Fprint(w, `[]`)
+	}))
+	defer srv.Close()
+
+	tokens := &countingTokenSource{}
+	w := NewWiseProvider("", "profile")
+	w.BaseURL = srv.URL
+	w.TokenSource = tokens
+	for i := 0; i < 3; i++ {
+		if _, err := w.GetTransactionHistory(context.Background(), HistoryOptions{}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if n := tokens.calls.Load(); n != 1 {
+		t.Errorf("fetched %d tokens for 3 requests, want 1", n)
+	}
+	if got := auth.Load(); got != "Bearer token-1" {
+		t.Errorf("Authorization = %v, want the cached token", got)
+	}
+}
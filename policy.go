@@ -0,0 +1,109 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+// Enhanced due diligence fields callers supply in
+// TransactionRequest.ComplianceInfo when a policy asks for them
+const (
+	ComplianceSourceOfFunds    = "source_of_funds"
+	ComplianceSenderOccupation = "sender_occupation"
+	ComplianceRelationship     = "relationship_to_recipient"
+)
+
+// PolicyDecision is a policy engine's verdict on a request. A zero
+// PolicyDecision lets the send proceed.
+type PolicyDecision struct {
+	Block  bool
+	Reason string
+	// RequiredFields are ComplianceInfo keys that must be supplied before
+	// the send can go ahead
+	RequiredFields []string
+}
+
+// PolicyEngine decides whether a transfer needs extra checks before
+// SendMoney proceeds
+type PolicyEngine interface {
+	Evaluate(ctx context.Context, req TransactionRequest) (PolicyDecision, error)
+}
+
+// PolicyError reports a send stopped by policy: either blocked outright or
+// waiting on the listed fields
+type PolicyError struct {
+	Reason         string
+	Blocked        bool
+	RequiredFields []string
+}
+
+func (e *PolicyError) Error() string {
+	if e.Blocked {
+		return "policy: transfer blocked: " + e.Reason
+	}
+	return fmt.Sprintf("policy: %s: missing required fields: %s", e.Reason, strings.Join(e.RequiredFields, ", "))
+}
+
+// Threshold is one currency's due diligence limits. Zero disables a limit.
+type Threshold struct {
+	// EnhancedAbove requires RequiredFields for amounts above it
+	EnhancedAbove  float64
+	RequiredFields []string
+	// BlockAbove rejects amounts above it outright
+	BlockAbove float64
+}
+
+// ThresholdPolicy applies per-currency Thresholds keyed on the source
+// currency. Currencies without an entry are not checked.
+type ThresholdPolicy map[Currency]Threshold
+
+func (p ThresholdPolicy) Evaluate(ctx context.Context, req TransactionRequest) (PolicyDecision, error) {
+	threshold, ok := p[req.FromCurrency]
+	if !ok {
+		return PolicyDecision{}, nil
+	}
+	if threshold.BlockAbove > 0 && req.Amount > threshold.BlockAbove {
+		return PolicyDecision{
+			Block:  true,
+			Reason: fmt.Sprintf("amount %.2f %s exceeds the %.2f limit", req.Amount, req.FromCurrency, threshold.BlockAbove),
+		}, nil
+	}
+	if threshold.EnhancedAbove > 0 && req.Amount > threshold.EnhancedAbove {
+		var missing []string
+		for _, field := range threshold.RequiredFields {
+			if strings.TrimSpace(req.ComplianceInfo[field]) == "" {
+				missing = append(missing, field)
+			}
+		}
+		if len(missing) > 0 {
+			return PolicyDecision{
+				Reason:         fmt.Sprintf("amounts above %.2f %s need enhanced due diligence", threshold.EnhancedAbove, req.FromCurrency),
+				RequiredFields: missing,
+			}, nil
+		}
+	}
+	return PolicyDecision{}, nil
+}
+
+// SetPolicy installs a policy engine consulted before every send. Call it
+// before serving traffic.
+func (rh *RemittanceHub) SetPolicy(policy PolicyEngine) {
+	rh.policy = policy
+}
+
+// checkPolicy turns the policy decision into a PolicyError. An engine
+// failure also stops the send: an unchecked transfer must not proceed.
+func (rh *RemittanceHub) checkPolicy(ctx context.Context, req TransactionRequest) error {
+	if rh.policy == nil {
+		return nil
+	}
+	decision, err := rh.policy.Evaluate(ctx, req)
+	if err != nil {
+		return fmt.Errorf("policy: evaluation failed: %w", err)
+	}
+	if decision.Block || len(decision.RequiredFields) > 0 {
+		return &PolicyError{Reason: decision.Reason, Blocked: decision.Block, RequiredFields: decision.RequiredFields}
+	}
+	return nil
+}
@@ -0,0 +1,54 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"strings"
+)
+
+// promotion is the promo-code outcome block Remitly, WorldRemit and Xoom
+// return on a quote when one was submitted. The quote's fee is before any
+// discount.
+type promotion struct {
+	Status   string  `json:"status"` // applied, invalid, expired
+	Discount float64 `json:"discount"`
+	Message  string  `json:"message"`
+}
+
+// applyPromotion takes an applied discount off quote.Fee and records it in
+// DiscountApplied. A rejected code is only a warning: the quote stays at
+// the undiscounted price so the user can still go ahead. Call it before
+// applyFeeModel, which derives TotalCost from Fee.
+func applyPromotion(quote *RemittanceQuote, code string, promo *promotion) {
+	if code == "" {
+		return
+	}
+	if promo == nil {
+		quote.Warnings = append(quote.Warnings, promoWarning(code, "not recognised by "+quote.Provider))
+		return
+	}
+	if !strings.EqualFold(promo.Status, "applied") {
+		reason := strings.ToLower(promo.Status)
+		if promo.Message != "" {
+			reason = promo.Message
+		}
+		quote.Warnings = append(quote.Warnings, promoWarning(code, reason))
+		return
+	}
+
+	discount := math.Min(promo.Discount, quote.Fee)
+	quote.Fee -= discount
+	quote.DiscountApplied = discount
+}
+
+// annotatePromo warns when a promo code was sent to a provider that can't
+// take one, so the user knows why no discount shows
+func annotatePromo(provider RemittanceProvider, quote *RemittanceQuote, req TransactionRequest) {
+	if req.PromoCode != "" && !provider.GetCapabilities().PromoCodes {
+		quote.Warnings = append(quote.Warnings, promoWarning(req.PromoCode, provider.GetName()+" does not accept promo codes"))
+	}
+}
+
+func promoWarning(code, reason string) string {
+	return fmt.Sprintf("Promo code %q not applied (%s); price shown without discount", code, reason)
+}
@@ -0,0 +1,177 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+// PaymentPurpose is a provider-neutral reason for a transfer. The hub maps
+// it to each provider's own code scheme.
+type PaymentPurpose string
+
+const (
+	PurposeFamilySupport    PaymentPurpose = "FAMILY_SUPPORT"
+	PurposeEducation        PaymentPurpose = "EDUCATION"
+	PurposeMedical          PaymentPurpose = "MEDICAL"
+	PurposePropertyPurchase PaymentPurpose = "PROPERTY_PURCHASE"
+	PurposeGift             PaymentPurpose = "GIFT"
+	PurposeSavings          PaymentPurpose = "SAVINGS"
+	PurposeSalary           PaymentPurpose = "SALARY"
+	PurposeBusiness         PaymentPurpose = "BUSINESS"
+)
+
+// PurposeCode is one entry in a provider's purpose-of-payment list
+type PurposeCode struct {
+	Code        string `json:"code"`
+	Description string `json:"description"`
+	// Purpose is the common purpose that maps to this code, if any
+	Purpose PaymentPurpose `json:"purpose,omitempty"`
+}
+
+// PurposeCodeProvider is implemented by providers that only accept a
+// purpose from a fixed list. The hub then requires a valid code on every
+// send (see TransactionRequest.PurposeCode).
+type PurposeCodeProvider interface {
+	GetPurposeCodes(ctx context.Context, route Route) ([]PurposeCode, error)
+}
+
+// GetPurposeCodes returns the purpose codes the named provider accepts on
+// route. Providers that take free text return ErrUnsupported.
+func (rh *RemittanceHub) GetPurposeCodes(ctx context.Context, providerName string, route Route) ([]PurposeCode, error) {
+	provider, err := rh.findProvider(providerName)
+	if err != nil {
+		return nil, err
+	}
+	lister, ok := provider.(PurposeCodeProvider)
+	if !ok {
+		return nil, &UnsupportedError{Provider: providerName, Operation: "purpose codes"}
+	}
+	return lister.GetPurposeCodes(ctx, route)
+}
+
+// purpose is what providers send as the transfer's purpose: the resolved
+// code when there is one, else the free text
+func (r TransactionRequest) purpose() string {
+	if r.PurposeCode != "" {
+		return r.PurposeCode
+	}
+	return r.Purpose
+}
+
+// checkPurposeCode sets req.PurposeCode to a code the provider accepts on
+// the request's route, or fails with a ValidationError listing the valid
+// codes. Providers without a code list are left alone.
+func checkPurposeCode(ctx context.Context, provider RemittanceProvider, req *TransactionRequest) error {
+	lister, ok := provider.(PurposeCodeProvider)
+	if !ok {
+		return nil
+	}
+	route := Route{From: req.FromCurrency, To: req.ToCurrency, Country: req.Recipient.Address.CountryCode}
+	codes, err := lister.GetPurposeCodes(ctx, route)
+	if err != nil {
+		return fmt.Errorf("%s purpose codes: %w", provider.GetName(), err)
+	}
+
+	code, ok := matchPurposeCode(codes, req.PurposeCode, req.Purpose)
+	if !ok {
+		valid := make([]string, len(codes))
+		for i, c := range codes {
+			valid[i] = c.Code
+		}
+		given := req.PurposeCode
+		if given == "" {
+			given = req.Purpose
+		}
+		return &ValidationError{
+			Field: "purpose_code",
+			Err:   fmt.Errorf("%q is not a %s purpose code (valid: %s)", given, provider.GetName(), strings.Join(valid, ", ")),
+		}
+	}
+	req.PurposeCode = code
+	return nil
+}
+
+// matchPurposeCode finds the provider code for an explicit code, or else
+// for a purpose given either as a common purpose ("Family support") or as
+// one of the provider's own codes
+func matchPurposeCode(codes []PurposeCode, code, purpose string) (string, bool) {
+	if code != "" {
+		return findPurposeCode(codes, code)
+	}
+	if purpose == "" {
+		return "", false
+	}
+	common := normalizePurpose(purpose)
+	for _, c := range codes {
+		if c.Purpose != "" && c.Purpose == common {
+			return c.Code, true
+		}
+	}
+	return findPurposeCode(codes, purpose)
+}
+
+func findPurposeCode(codes []PurposeCode, code string) (string, bool) {
+	for _, c := range codes {
+		if strings.EqualFold(c.Code, strings.TrimSpace(code)) {
+			return c.Code, true
+		}
+	}
+	return "", false
+}
+
+// normalizePurpose turns free text like "Family support" into the
+// PaymentPurpose spelling
+func normalizePurpose(purpose string) PaymentPurpose {
+	fields := strings.FieldsFunc(strings.ToUpper(purpose), func(r rune) bool {
+		return r == ' ' || r == '-' || r == '_'
+	})
+	return PaymentPurpose(strings.Join(fields, "_"))
+}
+
+var remitlyPurposeCodes = []PurposeCode{
+	{Code: "family_support", Description: "Family support", Purpose: PurposeFamilySupport},
+	{Code: "education", Description: "Education", Purpose: PurposeEducation},
+	{Code: "medical_expenses", Description: "Medical expenses", Purpose: PurposeMedical},
+	{Code: "real_estate", Description: "Property purchase", Purpose: PurposePropertyPurchase},
+	{Code: "gift", Description: "Gift", Purpose: PurposeGift},
+	{Code: "savings", Description: "Savings", Purpose: PurposeSavings},
+	{Code: "salary", Description: "Salary", Purpose: PurposeSalary},
+	{Code: "business", Description: "Business", Purpose: PurposeBusiness},
+	{Code: "travel", Description: "Travel"},
+}
+
+func (r *RemitlyProvider) GetPurposeCodes(ctx context.Context, route Route) ([]PurposeCode, error) {
+	return remitlyPurposeCodes, nil
+}
+
+var worldRemitPurposeCodes = []PurposeCode{
+	{Code: "FAM", Description: "Family support", Purpose: PurposeFamilySupport},
+	{Code: "EDU", Description: "Education", Purpose: PurposeEducation},
+	{Code: "MED", Description: "Medical", Purpose: PurposeMedical},
+	{Code: "PRP", Description: "Property purchase", Purpose: PurposePropertyPurchase},
+	{Code: "GFT", Description: "Gift", Purpose: PurposeGift},
+	{Code: "SAV", Description: "Savings", Purpose: PurposeSavings},
+	{Code: "SAL", Description: "Salary", Purpose: PurposeSalary},
+	{Code: "BUS", Description: "Business", Purpose: PurposeBusiness},
+	{Code: "DON", Description: "Donation"},
+}
+
+func (wr *WorldRemitProvider) GetPurposeCodes(ctx context.Context, route Route) ([]PurposeCode, error) {
+	return worldRemitPurposeCodes, nil
+}
+
+var xoomPurposeCodes = []PurposeCode{
+	{Code: "FAMILY_SUPPORT", Description: "Family support", Purpose: PurposeFamilySupport},
+	{Code: "EDUCATION", Description: "Education", Purpose: PurposeEducation},
+	{Code: "MEDICAL", Description: "Medical", Purpose: PurposeMedical},
+	{Code: "REAL_ESTATE", Description: "Property purchase", Purpose: PurposePropertyPurchase},
+	{Code: "GIFT", Description: "Gift", Purpose: PurposeGift},
+	{Code: "SAVINGS", Description: "Savings", Purpose: PurposeSavings},
+	{Code: "PAYROLL", Description: "Salary", Purpose: PurposeSalary},
+	{Code: "GOODS_AND_SERVICES", Description: "Goods and services", Purpose: PurposeBusiness},
+}
+
+func (x *XoomProvider) GetPurposeCodes(ctx context.Context, route Route) ([]PurposeCode, error) {
+	return xoomPurposeCodes, nil
+}
@@ -0,0 +1,100 @@
+package main
+
+import (
+	"context"
+	"sync"
+)
+
+// QuoteSet is one request's result from GetQuotesBatch
+type QuoteSet struct {
+	// Index is the request's position in the batch
+	Index     int
+	Reference string
+	// Quotes are sorted best value first, as GetQuotes returns them
+	Quotes []*RemittanceQuote
+	// Err is why the request has no quotes or, alongside some, why the
+	// rest are missing; it fails this request only
+	Err error
+}
+
+// GetQuotesBatch quotes many requests in one call, e.g. to compare
+// providers across a payroll run before sending it. Each request is quoted
+// as by GetQuotes, at most maxBatchConcurrency at a time, and requests in
+// the same corridor share their reference rate lookups.
+//
+// The returned sets line up with reqs. A request that fails records its
+// error in its own QuoteSet; the call itself only fails when ctx ends
+// before every request has been started, and then the sets not reached
+// carry ctx's error.
+func (rh *RemittanceHub) GetQuotesBatch(ctx context.Context, reqs []TransactionRequest) ([]QuoteSet, error) {
+	sets := make([]QuoteSet, len(reqs))
+	ctx = context.WithValue(ctx, sharedReferenceRatesKey{}, &sharedReferenceRates{})
+
+	sem := make(chan struct{}, maxBatchConcurrency)
+	var wg sync.WaitGroup
+	for i, req := range reqs {
+		sets[i] = QuoteSet{Index: i, Reference: req.Reference}
+		select {
+		case sem <- struct{}{}:
+		case <-ctx.Done():
+			for j := i; j < len(reqs); j++ {
+				sets[j] = QuoteSet{Index: j, Reference: reqs[j].Reference, Err: ctx.Err()}
+			}
+			wg.Wait()
+			return sets, ctx.Err()
+		}
+		wg.Add(1)
+		go func(i int, req TransactionRequest) {
+			defer func() { <-sem; wg.Done() }()
+			sets[i].Quotes, sets[i].Err = rh.GetQuotes(ctx, req)
+		}(i, req)
+	}
+	wg.Wait()
+	return sets, nil
+}
+
+type sharedReferenceRatesKey struct{}
+
+// sharedReferenceRates lets the requests in a batch share one reference
+// rate lookup per pair, including lookups still in flight
+type sharedReferenceRates struct {
+	mu      sync.Mutex
+	lookups map[CurrencyPair]*referenceLookup
+}
+
+type referenceLookup struct {
+	done chan struct{}
+	rate float64
+	err  error
+}
+
+func (s *sharedReferenceRates) get(ctx context.Context, source ReferenceRateProvider, pair CurrencyPair) (float64, error) {
+	s.mu.Lock()
+	if s.lookups == nil {
+		s.lookups = make(map[CurrencyPair]*referenceLookup)
+	}
+	lookup, ok := s.lookups[pair]
+	if !ok {
+		lookup = &referenceLookup{done: make(chan struct{})}
+		s.lookups[pair] = lookup
+	}
+	s.mu.Unlock()
+
+	if !ok {
+		lookup.rate, lookup.err = source.MidMarketRate(ctx, pair.From, pair.To)
+		if lookup.err != nil {
+			// Let a later request try again rather than share the failure
+			s.mu.Lock()
+			delete(s.lookups, pair)
+			s.mu.Unlock()
+		}
+		close(lookup.done)
+		return lookup.rate, lookup.err
+	}
+	select {
+	case <-lookup.done:
+		return lookup.rate, lookup.err
+	case <-ctx.Done():
+		return 0, ctx.Err()
+	}
+}
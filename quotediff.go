@@ -0,0 +1,118 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"strings"
+)
+
+// Direction says whether a change to a quote is good or bad for the user
+type Direction string
+
+const (
+	Unchanged Direction = "UNCHANGED"
+	Better    Direction = "BETTER"
+	Worse     Direction = "WORSE"
+)
+
+// FieldDelta is how one quote field moved between two quotes
+type FieldDelta struct {
+	Old   float64 `json:"old"`
+	New   float64 `json:"new"`
+	Delta float64 `json:"delta"`
+	// Percent is Delta as a percentage of Old (1.5 = 1.5%), 0 when Old is 0
+	Percent   float64   `json:"percent"`
+	Direction Direction `json:"direction"`
+}
+
+// QuoteDiff is what changed between two quotes for the same request, such
+// as a quote and its refreshed replacement
+type QuoteDiff struct {
+	Fee            FieldDelta `json:"fee"`
+	ExchangeRate   FieldDelta `json:"exchange_rate"`
+	TotalCost      FieldDelta `json:"total_cost"`
+	ReceivedAmount FieldDelta `json:"received_amount"`
+}
+
+// DiffQuotes compares new against old. Fee and TotalCost are better for
+// the user when they fall, ExchangeRate and ReceivedAmount when they rise.
+// A nil quote compares as all zeros.
+func DiffQuotes(old, new *RemittanceQuote) QuoteDiff {
+	if old == nil {
+		old = &RemittanceQuote{}
+	}
+	if new == nil {
+		new = &RemittanceQuote{}
+	}
+	return QuoteDiff{
+		Fee:            fieldDelta(old.Fee, new.Fee, false),
+		ExchangeRate:   fieldDelta(old.ExchangeRate, new.ExchangeRate, true),
+		TotalCost:      fieldDelta(old.TotalCost, new.TotalCost, false),
+		ReceivedAmount: fieldDelta(old.ReceivedAmount, new.ReceivedAmount, true),
+	}
+}
+
+func fieldDelta(old, new float64, higherIsBetter bool) FieldDelta {
+	d := FieldDelta{Old: old, New: new, Delta: new - old, Direction: Unchanged}
+	if math.Abs(d.Delta) < precisionEpsilon {
+		d.Delta = 0
+		return d
+	}
+	if old != 0 {
+		d.Percent = d.Delta / math.Abs(old) * 100
+	}
+	if (d.Delta > 0) == higherIsBetter {
+		d.Direction = Better
+	} else {
+		d.Direction = Worse
+	}
+	return d
+}
+
+func (d QuoteDiff) fields() []FieldDelta {
+	return []FieldDelta{d.Fee, d.ExchangeRate, d.TotalCost, d.ReceivedAmount}
+}
+
+// Changed reports whether any field moved
+func (d QuoteDiff) Changed() bool {
+	for _, f := range d.fields() {
+		if f.Direction != Unchanged {
+			return true
+		}
+	}
+	return false
+}
+
+// Worse reports whether any field moved against the user
+func (d QuoteDiff) Worse() bool {
+	for _, f := range d.fields() {
+		if f.Direction == Worse {
+			return true
+		}
+	}
+	return false
+}
+
+// Material reports whether TotalCost or ReceivedAmount, the figures the
+// user confirmed, moved by more than tolerance as a fraction of their old
+// value (0.005 = 0.5%). 0 treats any change as material.
+func (d QuoteDiff) Material(tolerance float64) bool {
+	return beyondTolerance(d.TotalCost.Old, d.TotalCost.New, tolerance) ||
+		beyondTolerance(d.ReceivedAmount.Old, d.ReceivedAmount.New, tolerance)
+}
+
+// String lists the fields that moved, e.g.
+// "fee 5.99 -> 6.49 (worse), exchange rate 83.1 -> 83.4 (better)"
+func (d QuoteDiff) String() string {
+	names := []string{"fee", "exchange rate", "total cost", "received amount"}
+	var changes []string
+	for i, f := range d.fields() {
+		if f.Direction != Unchanged {
+			changes = append(changes, fmt.Sprintf("%s %v -> %v (%s)", names[i], f.Old, f.New, strings.ToLower(string(f.Direction))))
+		}
+	}
+	if len(changes) == 0 {
+		return "unchanged"
+	}
+	return strings.Join(changes, ", ")
+}
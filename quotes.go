@@ -0,0 +1,68 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+)
+
+// Expired reports whether the quote's ValidUntil has passed. A quote with
+// no ValidUntil never expires.
+func (q *RemittanceQuote) Expired() bool {
+	return !q.ValidUntil.IsZero() && !time.Now().Before(q.ValidUntil)
+}
+
+// checkQuote validates a request's accepted Quote against the provider
+// about to send it
+func checkQuote(provider RemittanceProvider, req TransactionRequest) error {
+	quote := req.Quote
+	if quote.Provider != provider.GetName() {
+		return fmt.Errorf("quote belongs to %s, not %s", quote.Provider, provider.GetName())
+	}
+	if quote.Expired() && !req.AllowExpired {
+		return fmt.Errorf("%w: %s quote was valid until %s", ErrQuoteExpired, quote.Provider, quote.ValidUntil.Format(time.RFC3339))
+	}
+	return nil
+}
+
+// QuoteAndSend fetches a fresh quote from the provider and sends against
+// it, so the transfer is priced on exactly what was just quoted instead of a
+// stale or placeholder quote. Where the provider supports rate locking the
+// quote is locked first; if locking fails the fresh quote is still used.
+// A quote with RequiredActions stops the send, since it would be rejected.
+func (rh *RemittanceHub) QuoteAndSend(ctx context.Context, providerName string, req TransactionRequest) (*TransactionResponse, error) {
+	provider, err := rh.findProvider(providerName)
+	if err != nil {
+		return nil, err
+	}
+	if err := req.Validate(); err != nil {
+		return nil, err
+	}
+
+	quote, err := rh.quoteProvider(ctx, provider, req)
+	if err != nil {
+		return nil, fmt.Errorf("quote from %s: %w", providerName, err)
+	}
+	rh.annotateMidMarket(ctx, []*RemittanceQuote{quote}, req)
+	if len(quote.RequiredActions) > 0 {
+		return nil, fmt.Errorf("%s requires action before sending: %s", providerName, strings.Join(quote.RequiredActions, "; "))
+	}
+
+	req.Quote = quote
+	req.LockedQuote = nil
+	req.AllowExpired = false
+	if provider.GetCapabilities().RateLock && quote.QuoteID != "" {
+		if lock, err := rh.LockQuote(ctx, quote); err != nil {
+			log.Printf("Sending on unlocked %s quote %s: %v", providerName, quote.QuoteID, err)
+		} else {
+			req.LockedQuote = &lock
+		}
+	}
+
+	if err := rh.checkSend(ctx, provider, &req); err != nil {
+		return nil, err
+	}
+	return rh.sendWithProvider(ctx, provider, req)
+}
@@ -0,0 +1,94 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestSendRejectsExpiredQuote(t *testing.T) {
+	ctx := context.Background()
+	sim := newSimulatedRemitly()
+	hub := NewRemittanceHub()
+	hub.AddProvider(sim)
+
+	quote, err := sim.GetQuote(ctx, testRequest())
+	if err != nil {
+		t.Fatal(err)
+	}
+	quote.ValidUntil = time.Now().Add(-time.Minute)
+	req := testRequest()
+	req.Quote = quote
+
+	if _, err := hub.SendMoneyWithProvider(ctx, sim.GetName(), req); !errors.Is(err, ErrQuoteExpired) {
+		t.Fatalf("err = %v, want ErrQuoteExpired", err)
+	}
+
+	req.AllowExpired = true
+	if _, err := hub.SendMoneyWithProvider(ctx, sim.GetName(), req); err != nil {
+		t.Fatalf("AllowExpired send: %v", err)
+	}
+}
+
+func TestCheckQuote(t *testing.T) {
+	sim := newSimulatedRemitly()
+	tests := []struct {
+		name    string
+		quote   *RemittanceQuote
+		wantErr bool
+	}{
+		{"valid", &RemittanceQuote{Provider: "Remitly", ValidUntil: time.Now().Add(time.Minute)}, false},
+		{"no expiry", &RemittanceQuote{Provider: "Remitly"}, false},
+		{"expired", &RemittanceQuote{Provider: "Remitly", ValidUntil: time.Now().Add(-time.Second)}, true},
+		{"other provider", &RemittanceQuote{Provider: "WorldRemit"}, true},
+	}
+	for _, tt := range tests {
+		req := testRequest()
+		req.Quote = tt.quote
+		if err := checkQuote(sim, req); (err != nil) != tt.wantErr {
+			t.Errorf("%s: err = %v, want error %v", tt.name, err, tt.wantErr)
+		}
+	}
+}
+
+func TestWiseSendWithoutQuoteExecutesFreshQuote(t *testing.T) {
+	srv := newRecordingServer(t, map[string]string{
+		"/v1/quotes":    `{"id":"q-fresh","source":"USD","target":"INR","sourceAmount":500,"targetAmount":41085,"rate":83,"fee":5}`,
+		"/v1/transfers": `{"id":77,"quoteUuid":"q-fresh","status":"incoming_payment_waiting","rate":83}`,
+	})
+	w := NewWiseProvider("key", "profile")
+	w.BaseURL = srv.URL
+	req := testRequest()
+	req.Recipient.ID = "acct-1"
+
+	tx, err := w.SendMoney(context.Background(), req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := srv.body("/v1/transfers")["quote"]; got != "q-fresh" {
+		t.Errorf("transfer executed quote %v, want the fresh q-fresh", got)
+	}
+	if tx.Fee != 5 {
+		t.Errorf("fee = %v, want the fresh quote's 5", tx.Fee)
+	}
+}
+
+func TestWiseSendRejectsQuoteWithoutID(t *testing.T) {
+	srv := newRecordingServer(t, map[string]string{
+		"/v1/quotes": `{"source":"USD","target":"INR","sourceAmount":500,"targetAmount":41085,"rate":83,"fee":5}`,
+	})
+	w := NewWiseProvider("key", "profile")
+	w.BaseURL = srv.URL
+	req := testRequest()
+	req.Recipient.ID = "acct-1"
+
+	_, err := w.SendMoney(context.Background(), req)
+	var invalid *ValidationError
+	if !errors.As(err, &invalid) || invalid.Field != "quote" {
+		t.Fatalf("err = %v, want a quote ValidationError", err)
+	}
+	if srv.body("/v1/transfers") != nil {
+		t.Error("a transfer was posted without a quote id")
+	}
+}
@@ -0,0 +1,134 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sync"
+	"time"
+)
+
+// QuoteSession holds one provider's quote for a request across a
+// multi-step checkout. When the held quote expires, the next Quote call
+// fetches a fresh one. If the price has moved by more than Tolerance,
+// OnPriceChange fires so the UI can ask the user to confirm again.
+type QuoteSession struct {
+	// Tolerance is how far TotalCost or ReceivedAmount may move, as a
+	// fraction of the old value (0.005 = 0.5%), before a change is
+	// material. 0 treats any change as material.
+	Tolerance float64
+	// MinRefreshInterval is the shortest gap allowed between fetches
+	// (default 10s). It stops a client from polling the provider through
+	// the session.
+	MinRefreshInterval time.Duration
+	// OnPriceChange is called after a refresh that changed the price
+	// materially. It runs without the session locked; DiffQuotes(old, new)
+	// says what moved.
+	OnPriceChange func(old, new *RemittanceQuote)
+
+	hub      *RemittanceHub
+	provider RemittanceProvider
+	req      TransactionRequest
+
+	mu          sync.Mutex
+	quote       *RemittanceQuote
+	lastFetched time.Time
+}
+
+// NewQuoteSession validates req and fetches the first quote from the named
+// provider
+func (rh *RemittanceHub) NewQuoteSession(ctx context.Context, providerName string, req TransactionRequest) (*QuoteSession, error) {
+	provider, err := rh.findProvider(providerName)
+	if err != nil {
+		return nil, err
+	}
+	if err := req.Validate(); err != nil {
+		return nil, err
+	}
+	s := &QuoteSession{hub: rh, provider: provider, req: req}
+	if _, err := s.Refresh(ctx); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// Quote returns the held quote, fetching a new one first if it has expired
+func (s *QuoteSession) Quote(ctx context.Context) (*RemittanceQuote, error) {
+	s.mu.Lock()
+	quote := s.quote
+	s.mu.Unlock()
+	if !quote.Expired() {
+		return quote, nil
+	}
+	return s.Refresh(ctx)
+}
+
+// Refresh fetches a new quote now, whether or not the held one has
+// expired. It fails with ErrQuoteRefreshLimited within MinRefreshInterval
+// of the last fetch.
+func (s *QuoteSession) Refresh(ctx context.Context) (*RemittanceQuote, error) {
+	s.mu.Lock()
+	if wait := s.refreshInterval() - time.Since(s.lastFetched); !s.lastFetched.IsZero() && wait > 0 {
+		s.mu.Unlock()
+		return nil, fmt.Errorf("%w: next refresh allowed in %s", ErrQuoteRefreshLimited, wait.Round(time.Millisecond))
+	}
+	s.lastFetched = time.Now()
+	s.mu.Unlock()
+
+	quote, err := s.hub.quoteProvider(ctx, s.provider, s.req)
+	if err != nil {
+		return nil, fmt.Errorf("quote from %s: %w", s.provider.GetName(), err)
+	}
+	s.hub.annotateMidMarket(ctx, []*RemittanceQuote{quote}, s.req)
+
+	s.mu.Lock()
+	old := s.quote
+	s.quote = quote
+	s.mu.Unlock()
+
+	if old != nil && s.OnPriceChange != nil && s.changed(old, quote) {
+		s.OnPriceChange(old, quote)
+	}
+	return quote, nil
+}
+
+// Send sends the request against the current quote, provided its price is
+// still within Tolerance of confirmed, the quote the user agreed to.
+// Otherwise it fails with ErrQuoteChanged and sends nothing.
+func (s *QuoteSession) Send(ctx context.Context, confirmed *RemittanceQuote) (*TransactionResponse, error) {
+	quote, err := s.Quote(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if confirmed != nil && s.changed(confirmed, quote) {
+		return nil, fmt.Errorf("%w: %s %s", ErrQuoteChanged, quote.Provider, DiffQuotes(confirmed, quote))
+	}
+
+	req := s.req
+	req.Quote = quote
+	req.LockedQuote = nil
+	req.AllowExpired = false
+	if err := s.hub.checkSend(ctx, s.provider, &req); err != nil {
+		return nil, err
+	}
+	return s.hub.sendWithProvider(ctx, s.provider, req)
+}
+
+func (s *QuoteSession) refreshInterval() time.Duration {
+	if s.MinRefreshInterval > 0 {
+		return s.MinRefreshInterval
+	}
+	return 10 * time.Second
+}
+
+// changed reports whether new differs materially from old
+func (s *QuoteSession) changed(old, new *RemittanceQuote) bool {
+	return DiffQuotes(old, new).Material(s.Tolerance)
+}
+
+func beyondTolerance(old, new, tolerance float64) bool {
+	if old == 0 {
+		return new != 0
+	}
+	return math.Abs(new-old)/math.Abs(old) > tolerance
+}
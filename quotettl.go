@@ -0,0 +1,82 @@
+package main
+
+import "time"
+
+// Validity assumed when a provider's response carries no expiry of its
+// own. Responses that do are always honoured.
+const (
+	wiseQuoteValidity       = 24 * time.Hour
+	wiseRateValidity        = time.Hour
+	remitlyQuoteValidity    = 30 * time.Minute
+	worldRemitQuoteValidity = 15 * time.Minute
+	xoomQuoteValidity       = 30 * time.Minute
+)
+
+// expiresAt returns the expiry a provider reported, or fallback from now
+// when it reported none
+func expiresAt(reported time.Time, fallback time.Duration) time.Time {
+	if reported.IsZero() {
+		return time.Now().Add(fallback)
+	}
+	return reported
+}
+
+// setExpiry sets ValidUntil as expiresAt does, noting when the validity is
+// assumed rather than reported so QuoteTTL.Min knows it may lengthen it
+func (q *RemittanceQuote) setExpiry(reported time.Time, fallback time.Duration) {
+	q.ValidUntil = expiresAt(reported, fallback)
+	q.validityAssumed = reported.IsZero()
+}
+
+// parseExpiry parses an RFC 3339 expiry from a response, returning the zero
+// time when it is missing or malformed
+func parseExpiry(s string) time.Time {
+	t, err := time.Parse(time.RFC3339, s)
+	if err != nil {
+		return time.Time{}
+	}
+	return t
+}
+
+// QuoteTTL adjusts the validity of every quote the hub receives, measured
+// from when it arrives. Zero fields impose nothing. It is meant for tests
+// and for operators who want a safety margin under provider expiries.
+type QuoteTTL struct {
+	// Fixed replaces each quote's validity with exactly this long, so
+	// ValidUntil is deterministic under a fixed Now
+	Fixed time.Duration
+	// Min lengthens a validity the hub assumed for want of a reported
+	// expiry. It never extends an expiry the provider reported, since the
+	// provider drops the quote then whatever ValidUntil says.
+	Min time.Duration
+	// Max shortens any validity, reported or assumed
+	Max time.Duration
+	// Now is the clock validity is measured from; nil uses time.Now
+	Now func() time.Time
+}
+
+// SetQuoteTTL overrides or clamps the validity of quotes from every
+// provider. Call it before serving traffic.
+func (rh *RemittanceHub) SetQuoteTTL(ttl QuoteTTL) {
+	rh.quoteTTL = ttl
+}
+
+func (t QuoteTTL) apply(quote *RemittanceQuote) {
+	if t.Fixed <= 0 && t.Min <= 0 && t.Max <= 0 {
+		return
+	}
+	now := time.Now()
+	if t.Now != nil {
+		now = t.Now()
+	}
+	if t.Fixed > 0 {
+		quote.ValidUntil = now.Add(t.Fixed)
+		return
+	}
+	if t.Min > 0 && quote.validityAssumed && quote.ValidUntil.Before(now.Add(t.Min)) {
+		quote.ValidUntil = now.Add(t.Min)
+	}
+	if t.Max > 0 && quote.ValidUntil.After(now.Add(t.Max)) {
+		quote.ValidUntil = now.Add(t.Max)
+	}
+}
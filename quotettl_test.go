@@ -0,0 +1,34 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func TestQuoteTTLMinNeverExtendsReportedExpiry(t *testing.T) {
+	now := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	ttl := QuoteTTL{Min: 10 * time.Minute, Now: func() time.Time { return now }}
+
+	reported := &RemittanceQuote{}
+	reported.setExpiry(now.Add(time.Minute), time.Hour)
+	ttl.apply(reported)
+	if want := now.Add(time.Minute); !reported.ValidUntil.Equal(want) {
+		t.Errorf("reported expiry moved to %v, want %v", reported.ValidUntil, want)
+	}
+
+	assumed := &RemittanceQuote{ValidUntil: now.Add(time.Minute), validityAssumed: true}
+	ttl.apply(assumed)
+	if want := now.Add(10 * time.Minute); !assumed.ValidUntil.Equal(want) {
+		t.Errorf("assumed expiry = %v, want it lengthened to %v", assumed.ValidUntil, want)
+	}
+}
+
+func TestQuoteTTLMaxShortensReportedExpiry(t *testing.T) {
+	now := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	quote := &RemittanceQuote{}
+	quote.setExpiry(now.Add(time.Hour), time.Hour)
+	QuoteTTL{Max: 5 * time.Minute, Now: func() time.Time { return now }}.apply(quote)
+	if want := now.Add(5 * time.Minute); !quote.ValidUntil.Equal(want) {
+		t.Errorf("ValidUntil = %v, want %v", quote.ValidUntil, want)
+	}
+}
@@ -0,0 +1,221 @@
+package main
+
+import (
+	"context"
+	"math/rand"
+	"sync"
+	"time"
+)
+
+// RateCacheOptions tunes the hub's exchange rate cache. Zero fields take
+// the defaults given.
+type RateCacheOptions struct {
+	// TTL is how long a rate without a ValidUntil is kept (default 1m)
+	TTL time.Duration
+	// RefreshAhead is how long before expiry a read starts refreshing the
+	// entry in the background, keeping the old rate meanwhile (default 10s)
+	RefreshAhead time.Duration
+	// Jitter moves each entry's refresh point up to this much earlier
+	// still, picked at random per entry, so pairs cached together don't
+	// all refresh together (default RefreshAhead)
+	Jitter time.Duration
+	// StaleFor is how long past expiry an entry may still be served while
+	// its refresh is in flight (default 5s). After that, reads wait for
+	// the refresh.
+	StaleFor time.Duration
+	// RefreshTimeout bounds each upstream fetch (default 10s)
+	RefreshTimeout time.Duration
+}
+
+func (o RateCacheOptions) withDefaults() RateCacheOptions {
+	if o.TTL <= 0 {
+		o.TTL = time.Minute
+	}
+	if o.RefreshAhead <= 0 {
+		o.RefreshAhead = 10 * time.Second
+	}
+	if o.Jitter <= 0 {
+		o.Jitter = o.RefreshAhead
+	}
+	if o.StaleFor <= 0 {
+		o.StaleFor = 5 * time.Second
+	}
+	if o.RefreshTimeout <= 0 {
+		o.RefreshTimeout = 10 * time.Second
+	}
+	return o
+}
+
+type rateCacheKey struct {
+	provider string
+	pair     CurrencyPair
+}
+
+type rateEntry struct {
+	rate      *ExchangeRate
+	expires   time.Time
+	refreshAt time.Time
+	// fetch is the call in flight for this key, if any
+	fetch *rateFetch
+}
+
+type rateFetch struct {
+	done chan struct{}
+	rate *ExchangeRate
+	err  error
+}
+
+// rateCache keeps each provider's GetExchangeRates answers until their
+// ValidUntil. Reads close to expiry trigger a background refresh, so busy
+// pairs are never fetched on the request path once warm; concurrent misses
+// for a key share one upstream call.
+type rateCache struct {
+	opts RateCacheOptions
+	now  func() time.Time
+	// ctx is cancelled by close, aborting fetches in flight
+	ctx    context.Context
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+
+	mu      sync.Mutex
+	entries map[rateCacheKey]*rateEntry
+}
+
+func newRateCache(opts RateCacheOptions) *rateCache {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &rateCache{
+		opts:    opts.withDefaults(),
+		now:     time.Now,
+		ctx:     ctx,
+		cancel:  cancel,
+		entries: make(map[rateCacheKey]*rateEntry),
+	}
+}
+
+// close cancels fetches in flight and waits for their goroutines to exit
+func (c *rateCache) close() {
+	c.mu.Lock()
+	c.cancel()
+	c.mu.Unlock()
+	c.wg.Wait()
+}
+
+// SetRateCache makes the hub cache provider exchange rates, refreshing
+// them ahead of expiry with jitter so many pairs expiring together don't
+// stampede the providers. SubscribeRates polls always go upstream. Calling
+// it again replaces the cache, stopping the old one's refreshes. Call it
+// before serving traffic.
+func (rh *RemittanceHub) SetRateCache(opts RateCacheOptions) {
+	if rh.rates != nil {
+		rh.rates.close()
+	}
+	rh.rates = newRateCache(opts)
+}
+
+// exchangeRate returns provider's rate for from->to, through the rate
+// cache when one is set
+func (rh *RemittanceHub) exchangeRate(ctx context.Context, provider RemittanceProvider, from, to Currency) (*ExchangeRate, error) {
+	if rh.rates == nil {
+		return provider.GetExchangeRates(ctx, from, to)
+	}
+	return rh.rates.get(ctx, provider, CurrencyPair{From: from, To: to})
+}
+
+func (c *rateCache) get(ctx context.Context, provider RemittanceProvider, pair CurrencyPair) (*ExchangeRate, error) {
+	key := rateCacheKey{provider: provider.GetName(), pair: pair}
+	now := c.now()
+
+	c.mu.Lock()
+	if c.ctx.Err() != nil {
+		c.mu.Unlock()
+		return nil, ErrClosed
+	}
+	entry := c.entries[key]
+	if entry == nil {
+		entry = &rateEntry{}
+		c.entries[key] = entry
+	}
+	if entry.rate != nil {
+		switch {
+		case now.Before(entry.refreshAt):
+			rate := *entry.rate
+			c.mu.Unlock()
+			return &rate, nil
+		case now.Before(entry.expires):
+			// Due for refresh but still valid: serve it and refresh behind
+			if entry.fetch == nil {
+				c.startFetch(ctx, key, entry, provider)
+			}
+			rate := *entry.rate
+			c.mu.Unlock()
+			return &rate, nil
+		case entry.fetch != nil && now.Before(entry.expires.Add(c.opts.StaleFor)):
+			rate := *entry.rate
+			c.mu.Unlock()
+			return &rate, nil
+		}
+	}
+	fetch := entry.fetch
+	if fetch == nil {
+		fetch = c.startFetch(ctx, key, entry, provider)
+	}
+	c.mu.Unlock()
+
+	select {
+	case <-fetch.done:
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+	if fetch.err != nil {
+		return nil, fetch.err
+	}
+	rate := *fetch.rate
+	return &rate, nil
+}
+
+// startFetch calls the provider in a new goroutine and stores the result
+// under key. The call keeps ctx's values but not its cancellation, since
+// other readers share it; RefreshTimeout and closing the cache bound it
+// instead. c.mu must be held.
+func (c *rateCache) startFetch(ctx context.Context, key rateCacheKey, entry *rateEntry, provider RemittanceProvider) *rateFetch {
+	fetch := &rateFetch{done: make(chan struct{})}
+	entry.fetch = fetch
+
+	c.wg.Add(1)
+	go func() {
+		defer c.wg.Done()
+		ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), c.opts.RefreshTimeout)
+		defer cancel()
+		stop := context.AfterFunc(c.ctx, cancel)
+		defer stop()
+		fetch.rate, fetch.err = provider.GetExchangeRates(ctx, key.pair.From, key.pair.To)
+
+		c.mu.Lock()
+		entry.fetch = nil
+		if fetch.err == nil && fetch.rate != nil {
+			entry.rate = fetch.rate
+			entry.expires, entry.refreshAt = c.schedule(fetch.rate)
+		} else if fetch.err == nil {
+			fetch.err = errNoExchangeRate
+		}
+		c.mu.Unlock()
+		close(fetch.done)
+	}()
+	return fetch
+}
+
+// schedule returns when a freshly fetched rate expires and when reads
+// should start refreshing it: RefreshAhead before expiry, less a random
+// share of Jitter, but never before the midpoint of its lifetime
+func (c *rateCache) schedule(rate *ExchangeRate) (expires, refreshAt time.Time) {
+	now := c.now()
+	expires = rate.ValidUntil
+	if expires.IsZero() {
+		expires = now.Add(c.opts.TTL)
+	}
+	lead := c.opts.RefreshAhead + time.Duration(rand.Int63n(int64(c.opts.Jitter)+1))
+	if lifetime := expires.Sub(now); lead > lifetime/2 {
+		lead = lifetime / 2
+	}
+	return expires, expires.Add(-lead)
+}
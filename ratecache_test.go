@@ -0,0 +1,44 @@
+package main
+
+import (
+	"context"
+	"runtime"
+	"testing"
+	"time"
+)
+
+// hangingRates never answers a rate request until its context ends
+type hangingRates struct {
+	*SimulatedProvider
+}
+
+func (h hangingRates) GetExchangeRates(ctx context.Context, from, to Currency) (*ExchangeRate, error) {
+	<-ctx.Done()
+	return nil, ctx.Err()
+}
+
+func TestSetRateCacheTwiceLeavesNoGoroutines(t *testing.T) {
+	before := runtime.NumGoroutine()
+	hub := NewRemittanceHub()
+	provider := hangingRates{newSimulatedRemitly()}
+	opts := RateCacheOptions{RefreshTimeout: time.Minute}
+
+	// Each cache is left with a fetch in flight after its reader gives up
+	for i := 0; i < 2; i++ {
+		hub.SetRateCache(opts)
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+		if _, err := hub.exchangeRate(ctx, provider, USD, INR); err == nil {
+			t.Fatal("want the reader's deadline")
+		}
+		cancel()
+	}
+	hub.Close()
+
+	deadline := time.Now().Add(time.Second)
+	for runtime.NumGoroutine() > before {
+		if time.Now().After(deadline) {
+			t.Fatalf("%d goroutines left running after Close, had %d before", runtime.NumGoroutine(), before)
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
@@ -0,0 +1,65 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// LockedQuote is a quote whose rate and fee the provider has agreed to honour
+// until LockedUntil, typically well past the quote's own ValidUntil. Pass it
+// as TransactionRequest.LockedQuote to send at the locked price.
+type LockedQuote struct {
+	Quote       *RemittanceQuote `json:"quote"`
+	LockToken   string           `json:"lock_token"`
+	LockedUntil time.Time        `json:"locked_until"`
+}
+
+// Expired reports whether the lock has lapsed
+func (l *LockedQuote) Expired() bool {
+	return !time.Now().Before(l.LockedUntil)
+}
+
+// RateLocker is implemented by providers with Capabilities.RateLock
+type RateLocker interface {
+	LockQuote(ctx context.Context, quote *RemittanceQuote) (LockedQuote, error)
+}
+
+// LockQuote asks the quote's provider to hold its rate. Providers without
+// rate locking return an *UnsupportedError (errors.Is ErrUnsupported).
+func (rh *RemittanceHub) LockQuote(ctx context.Context, quote *RemittanceQuote) (LockedQuote, error) {
+	if quote == nil {
+		return LockedQuote{}, errors.New("quote is required")
+	}
+	provider, err := rh.findProvider(quote.Provider)
+	if err != nil {
+		return LockedQuote{}, err
+	}
+	locker, ok := provider.(RateLocker)
+	if !ok || !provider.GetCapabilities().RateLock {
+		return LockedQuote{}, &UnsupportedError{Provider: quote.Provider, Operation: "rate lock"}
+	}
+	if quote.QuoteID == "" {
+		return LockedQuote{}, fmt.Errorf("provider %s: quote has no QuoteID to lock", quote.Provider)
+	}
+	return locker.LockQuote(ctx, quote)
+}
+
+// checkLockedQuote validates a request's LockedQuote against the provider
+// that is about to send it.
+func checkLockedQuote(provider RemittanceProvider, lock *LockedQuote) error {
+	if lock.Quote == nil || lock.LockToken == "" {
+		return errors.New("locked quote is missing its quote or lock token")
+	}
+	if lock.Quote.Provider != provider.GetName() {
+		return fmt.Errorf("locked quote belongs to %s, not %s", lock.Quote.Provider, provider.GetName())
+	}
+	if !provider.GetCapabilities().RateLock {
+		return &UnsupportedError{Provider: provider.GetName(), Operation: "rate lock"}
+	}
+	if lock.Expired() {
+		return fmt.Errorf("rate lock with %s expired at %s", provider.GetName(), lock.LockedUntil.Format(time.RFC3339))
+	}
+	return nil
+}
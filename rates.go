@@ -0,0 +1,132 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"sync"
+)
+
+// CurrencyPair identifies a directed conversion, e.g. USD->PHP
+type CurrencyPair struct {
+	From Currency `json:"from"`
+	To   Currency `json:"to"`
+}
+
+func (p CurrencyPair) String() string {
+	return string(p.From) + "->" + string(p.To)
+}
+
+var errNoExchangeRate = errors.New("no exchange rate found")
+
+// InverseRate derives the reverse of a quoted rate (to->from at 1/rate) and
+// marks the result Derived. Fee is denominated in the source currency of a
+// pair, so the original fee is converted into the new source currency at the
+// quoted rate rather than copied across. Validity is inherited unchanged.
+func InverseRate(r *ExchangeRate) *ExchangeRate {
+	if r == nil || r.Rate == 0 {
+		return nil
+	}
+	return &ExchangeRate{
+		From:       r.To,
+		To:         r.From,
+		Rate:       1 / r.Rate,
+		Fee:        r.Fee * r.Rate,
+		ValidUntil: r.ValidUntil,
+		Derived:    true,
+	}
+}
+
+// fetchRatesConcurrently is the GetExchangeRatesBatch fallback for providers
+// without a bulk rates endpoint: one GetExchangeRates call per pair, in parallel.
+func fetchRatesConcurrently(ctx context.Context, provider RemittanceProvider, pairs []CurrencyPair) (map[CurrencyPair]*ExchangeRate, error) {
+	var (
+		mu     sync.Mutex
+		wg     sync.WaitGroup
+		rates  = make(map[CurrencyPair]*ExchangeRate, len(pairs))
+		failed = make(map[CurrencyPair]error)
+	)
+
+	for _, pair := range pairs {
+		wg.Add(1)
+		go func(pair CurrencyPair) {
+			defer wg.Done()
+			rate, err := provider.GetExchangeRates(ctx, pair.From, pair.To)
+
+			mu.Lock()
+			defer mu.Unlock()
+			if err != nil {
+				failed[pair] = err
+				return
+			}
+			rates[pair] = rate
+		}(pair)
+	}
+	wg.Wait()
+
+	return rates, rateBatchError(provider.GetName(), len(pairs), failed)
+}
+
+// rateBatchError summarises the pairs that failed in a batch rate lookup.
+// It returns nil when nothing failed; otherwise the individual errors are
+// joined so callers can still use errors.Is on them.
+func rateBatchError(provider string, total int, failed map[CurrencyPair]error) error {
+	if len(failed) == 0 {
+		return nil
+	}
+
+	pairs := make([]CurrencyPair, 0, len(failed))
+	for pair := range failed {
+		pairs = append(pairs, pair)
+	}
+	sort.Slice(pairs, func(i, j int) bool {
+		return pairs[i].String() < pairs[j].String()
+	})
+
+	errs := make([]error, 0, len(pairs))
+	for _, pair := range pairs {
+		errs = append(errs, fmt.Errorf("%s: %w", pair, failed[pair]))
+	}
+	return fmt.Errorf("%s: %d of %d rate lookups failed: %w", provider, len(failed), total, errors.Join(errs...))
+}
+
+// decodeWiseRate extracts the from/to rate from a Wise /v1/rates body without
+// trusting its shape. An object instead of the expected array is treated as
+// an error envelope and its message returned; a rate sent as a numeric
+// string is accepted. Rows for other pairs are skipped, so a body that
+// ignores the source/target filter can't pass off another pair's rate.
+func decodeWiseRate(body io.Reader, from, to Currency) (float64, error) {
+	var raw json.RawMessage
+	if err := json.NewDecoder(body).Decode(&raw); err != nil {
+		return 0, fmt.Errorf("malformed response: %w", err)
+	}
+	raw = bytes.TrimSpace(raw)
+
+	if len(raw) > 0 && raw[0] == '{' {
+		var envelope errorEnvelope
+		if err := json.Unmarshal(raw, &envelope); err == nil {
+			if code, message := envelope.codeAndMessage(); message != "" {
+				if code != "" {
+					return 0, fmt.Errorf("provider error: %s (%s)", message, code)
+				}
+				return 0, fmt.Errorf("provider error: %s", message)
+			}
+		}
+		return 0, errors.New("malformed response: expected an array of rates, got an object")
+	}
+
+	var rates []WiseRate
+	if err := json.Unmarshal(raw, &rates); err != nil {
+		return 0, fmt.Errorf("malformed response: expected an array of rates: %w", err)
+	}
+	for _, r := range rates {
+		if Currency(r.Source) == from && Currency(r.Target) == to {
+			return r.value()
+		}
+	}
+	return 0, errNoExchangeRate
+}
@@ -0,0 +1,53 @@
+package main
+
+import (
+	"context"
+	"testing"
+)
+
+// wiseRatesWithUnknown is an unfiltered /v1/rates body: Wise lists pairs in
+// currencies this package doesn't register alongside the ones it does
+const wiseRatesWithUnknown = `[
+	{"source":"AUD","target":"USD","rate":0.66,"time":"2026-10-16T10:00:00+0000"},
+	{"source":"USD","target":"PHP","rate":56.1,"time":"2026-10-16T10:00:00+0000"},
+	{"source":"CAD","target":"PHP","rate":"41.2","time":"2026-10-16T10:00:00+0000"}
+]`
+
+func TestWiseRatesSkipUnregisteredCurrencies(t *testing.T) {
+	ctx := context.Background()
+	srv := newRecordingServer(t, map[string]string{"/v1/rates": wiseRatesWithUnknown})
+	w := NewWiseProvider("key", "profile")
+	w.BaseURL = srv.URL
+
+	rate, err := w.GetExchangeRates(ctx, USD, PHP)
+	if err != nil {
+		t.Fatalf("GetExchangeRates: %v", err)
+	}
+	if rate.Rate != 56.1 {
+		t.Errorf("GetExchangeRates rate = %v, want 56.1", rate.Rate)
+	}
+
+	rates, err := w.GetExchangeRatesBatch(ctx, []CurrencyPair{{From: USD, To: PHP}})
+	if err != nil {
+		t.Fatalf("GetExchangeRatesBatch: %v", err)
+	}
+	if got := rates[CurrencyPair{From: USD, To: PHP}]; got == nil || got.Rate != 56.1 {
+		t.Errorf("GetExchangeRatesBatch USD/PHP = %+v, want rate 56.1", got)
+	}
+}
+
+func TestWiseTransferWithUnregisteredCurrency(t *testing.T) {
+	srv := newRecordingServer(t, map[string]string{
+		"/v1/transfers/42": `{"id":42,"status":"outgoing_payment_sent","sourceCurrency":"AUD","sourceValue":100,"targetCurrency":"USD","targetValue":66,"rate":0.66}`,
+	})
+	w := NewWiseProvider("key", "profile")
+	w.BaseURL = srv.URL
+
+	tx, err := w.GetTransactionStatus(context.Background(), "42")
+	if err != nil {
+		t.Fatalf("GetTransactionStatus: %v", err)
+	}
+	if tx.Status != StatusCompleted || tx.Amount != 100 {
+		t.Errorf("status %s amount %v, want %s 100", tx.Status, tx.Amount, StatusCompleted)
+	}
+}
@@ -0,0 +1,158 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Receipt is the record of a sent transfer given to the sender and to
+// support. Account numbers are masked to their last four characters.
+type Receipt struct {
+	TransactionID    string            `json:"transaction_id"`
+	Provider         string            `json:"provider,omitempty"`
+	Status           TransactionStatus `json:"status"`
+	SenderID         string            `json:"sender_id"`
+	RecipientName    string            `json:"recipient_name"`
+	RecipientAccount string            `json:"recipient_account,omitempty"`
+	RecipientCountry string            `json:"recipient_country,omitempty"`
+	Amount           float64           `json:"amount"`
+	Fee              float64           `json:"fee"`
+	// TotalCost is what the sender was charged, which depends on who bore
+	// the fee (see FeePaidBy)
+	TotalCost        float64    `json:"total_cost"`
+	FromCurrency     Currency   `json:"from_currency"`
+	ExchangeRate     float64    `json:"exchange_rate"`
+	ReceivedAmount   float64    `json:"received_amount"`
+	ToCurrency       Currency   `json:"to_currency"`
+	Reference        string     `json:"reference,omitempty"`
+	IssuedAt         time.Time  `json:"issued_at"`
+	ScheduledFor     *time.Time `json:"scheduled_for,omitempty"`
+	EstimatedTime    string     `json:"estimated_time,omitempty"`
+	EstimatedArrival *time.Time `json:"estimated_arrival,omitempty"`
+	TrackingURL      string     `json:"tracking_url,omitempty"`
+}
+
+// GenerateReceipt builds a receipt for tx, sent for req. quote is the
+// quote the send was made against; nil derives the figures from tx alone.
+// Failed transfers get no receipt.
+func GenerateReceipt(tx *TransactionResponse, req TransactionRequest, quote *RemittanceQuote) (Receipt, error) {
+	if tx == nil {
+		return Receipt{}, errors.New("no transaction to receipt")
+	}
+	if tx.Status == StatusFailed {
+		return Receipt{}, fmt.Errorf("transaction %s failed: %s", tx.TransactionID, tx.Error)
+	}
+
+	r := Receipt{
+		TransactionID:    tx.TransactionID,
+		Status:           tx.Status,
+		SenderID:         req.SenderID,
+		RecipientName:    req.Recipient.Name,
+		RecipientAccount: maskedAccount(req.Recipient.BankDetails),
+		RecipientCountry: req.Recipient.Address.Country,
+		Amount:           tx.Amount,
+		Fee:              tx.Fee,
+		FromCurrency:     req.FromCurrency,
+		ExchangeRate:     tx.ExchangeRate,
+		ToCurrency:       req.ToCurrency,
+		Reference:        req.Reference,
+		IssuedAt:         time.Now().UTC(),
+		EstimatedTime:    tx.EstimatedTime,
+		TrackingURL:      tx.TrackingURL,
+	}
+	r.ScheduledFor = tx.ScheduledFor
+
+	// The provider's executed figures win over the quote's; the quote's
+	// totals are only reused when the rate and fee held
+	paidBy := req.FeePaidBy
+	if quote != nil {
+		r.Provider = quote.Provider
+		if !quote.EstimatedArrival.IsZero() {
+			arrival := quote.EstimatedArrival
+			r.EstimatedArrival = &arrival
+		}
+		paidBy = quote.FeePaidBy
+	}
+	if quote != nil && quote.ExchangeRate == tx.ExchangeRate && quote.Fee == tx.Fee && quote.Amount == tx.Amount {
+		r.TotalCost, r.ReceivedAmount = quote.TotalCost, quote.ReceivedAmount
+	} else {
+		executed := &RemittanceQuote{Amount: tx.Amount, Fee: tx.Fee, ExchangeRate: tx.ExchangeRate}
+		applyFeeModel(executed, paidBy)
+		r.TotalCost = RoundReceivedAmount(executed.TotalCost, req.FromCurrency, "", RoundingNearest)
+		r.ReceivedAmount = RoundReceivedAmount(executed.ReceivedAmount, req.ToCurrency, req.payoutMethod(), req.RoundingMode)
+	}
+	return r, nil
+}
+
+// JSON renders the receipt as indented JSON
+func (r Receipt) JSON() ([]byte, error) {
+	return json.MarshalIndent(r, "", "  ")
+}
+
+// Text renders the receipt for email or a support ticket
+func (r Receipt) Text() string {
+	var b strings.Builder
+	line := func(label, format string, args ...interface{}) {
+		fmt.Fprintf(&b, "%-18s %s\n", label+":", fmt.Sprintf(format, args...))
+	}
+
+	b.WriteString("Transfer Receipt\n")
+	line("Transaction ID", "%s", r.TransactionID)
+	if r.Provider != "" {
+		line("Provider", "%s", r.Provider)
+	}
+	line("Status", "%s", r.Status)
+	line("Issued", "%s", r.IssuedAt.Format(time.RFC3339))
+	line("Sender", "%s", r.SenderID)
+	recipient := r.RecipientName
+	if r.RecipientAccount != "" {
+		recipient += " (" + r.RecipientAccount + ")"
+	}
+	if r.RecipientCountry != "" {
+		recipient += ", " + r.RecipientCountry
+	}
+	line("Recipient", "%s", recipient)
+	line("Amount sent", "%.2f %s", r.Amount, r.FromCurrency)
+	line("Fee", "%.2f %s", r.Fee, r.FromCurrency)
+	line("Total charged", "%.2f %s", r.TotalCost, r.FromCurrency)
+	line("Exchange rate", "1 %s = %.4f %s", r.FromCurrency, r.ExchangeRate, r.ToCurrency)
+	line("Recipient gets", "%.2f %s", r.ReceivedAmount, r.ToCurrency)
+	if r.Reference != "" {
+		line("Reference", "%s", r.Reference)
+	}
+	if r.ScheduledFor != nil {
+		line("Scheduled for", "%s", r.ScheduledFor.Format(time.RFC3339))
+	}
+	if r.EstimatedArrival != nil {
+		line("Arrives by", "%s", r.EstimatedArrival.Format("Monday, Jan 2"))
+	} else if r.EstimatedTime != "" {
+		line("Delivery", "%s", r.EstimatedTime)
+	}
+	if r.TrackingURL != "" {
+		line("Track at", "%s", r.TrackingURL)
+	}
+	return b.String()
+}
+
+// receiptAccountFields are the bank detail keys that identify the account,
+// in order of preference
+var receiptAccountFields = []string{BankFieldIBAN, BankFieldAccountNumber}
+
+// maskedAccount returns the recipient's account identifier with all but
+// the last four characters masked
+func maskedAccount(details map[string]string) string {
+	for _, field := range receiptAccountFields {
+		account := details[field]
+		if account == "" {
+			continue
+		}
+		if len(account) <= 4 {
+			return strings.Repeat("*", len(account))
+		}
+		return strings.Repeat("*", len(account)-4) + account[len(account)-4:]
+	}
+	return ""
+}
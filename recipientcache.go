@@ -0,0 +1,80 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// recipientCache remembers the provider's recipient ID for each recipient
+// it has found or created, so repeat sends to the same person skip the
+// lookup. Entries are keyed by a hash of the recipient's name, country,
+// currency and bank details; a send to the same person with different bank
+// details drops the old entry. The zero value is ready to use.
+type recipientCache struct {
+	mu  sync.Mutex
+	ids map[string]string
+	// accounts maps each person's key to the account key cached for them
+	accounts map[string]string
+}
+
+// recipientKeys hashes r into a key for the person and one for the person
+// at their current bank account. Formatting differences (case, spacing)
+// that FindRecipient ignores don't change either key.
+func recipientKeys(r Recipient) (person, account string) {
+	h := sha256.New()
+	h.Write([]byte(normalizeName(r.Name) + "\x00" + strings.ToUpper(r.Address.CountryCode) + "\x00" + string(r.Currency)))
+	person = hex.EncodeToString(h.Sum(nil))
+
+	fields := make([]string, 0, len(r.BankDetails))
+	for key, value := range r.BankDetails {
+		fields = append(fields, strings.ToLower(key)+"="+strings.ToUpper(strings.ReplaceAll(value, " ", "")))
+	}
+	sort.Strings(fields)
+	h.Write([]byte("\x00" + strings.Join(fields, "\x00")))
+	account = hex.EncodeToString(h.Sum(nil))
+	return person, account
+}
+
+// get returns the cached ID for r. If the person is cached with other bank
+// details, that entry is dropped and nothing is returned.
+func (c *recipientCache) get(r Recipient) (string, bool) {
+	person, account := recipientKeys(r)
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if cached, ok := c.accounts[person]; ok && cached != account {
+		delete(c.ids, cached)
+		delete(c.accounts, person)
+		return "", false
+	}
+	id, ok := c.ids[account]
+	return id, ok
+}
+
+func (c *recipientCache) put(r Recipient, id string) {
+	person, account := recipientKeys(r)
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.ids == nil {
+		c.ids = make(map[string]string)
+		c.accounts = make(map[string]string)
+	}
+	if cached, ok := c.accounts[person]; ok && cached != account {
+		delete(c.ids, cached)
+	}
+	c.ids[account] = id
+	c.accounts[person] = account
+}
+
+// forget drops r's entry, e.g. when the provider no longer knows the ID
+func (c *recipientCache) forget(r Recipient) {
+	person, account := recipientKeys(r)
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	delete(c.ids, account)
+	if c.accounts[person] == account {
+		delete(c.accounts, person)
+	}
+}
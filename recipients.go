@@ -0,0 +1,191 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// RecipientRegistry is implemented by providers that only pay recipient
+// accounts registered with them beforehand (Capabilities.RegisteredRecipients).
+// Recipient.ID in a send must then be an ID returned here.
+type RecipientRegistry interface {
+	// CreateRecipient registers r and returns the provider's ID for it. It
+	// does not check for an existing match; call FindRecipient first to
+	// avoid duplicate accounts.
+	CreateRecipient(ctx context.Context, r Recipient) (recipientID string, err error)
+	// FindRecipient returns the ID of a registered account matching
+	// criteria, or ErrRecipientNotFound
+	FindRecipient(ctx context.Context, criteria RecipientCriteria) (recipientID string, err error)
+}
+
+// RecipientCriteria matches registered recipient accounts. Every BankDetails
+// entry given must match; Name, when set, matches case-insensitively.
+type RecipientCriteria struct {
+	Currency    Currency
+	Name        string
+	BankDetails map[string]string
+}
+
+// CriteriaFor matches accounts for the same person and bank account as r
+func CriteriaFor(r Recipient) RecipientCriteria {
+	return RecipientCriteria{Currency: r.Currency, Name: r.Name, BankDetails: r.BankDetails}
+}
+
+func (c RecipientCriteria) matches(name string, details map[string]string) bool {
+	if c.Name != "" && !strings.EqualFold(strings.TrimSpace(c.Name), strings.TrimSpace(name)) {
+		return false
+	}
+	for key, want := range c.BankDetails {
+		if !strings.EqualFold(strings.ReplaceAll(details[key], " ", ""), strings.ReplaceAll(want, " ", "")) {
+			return false
+		}
+	}
+	return true
+}
+
+// CreateRecipient registers a recipient with the named provider
+func (rh *RemittanceHub) CreateRecipient(ctx context.Context, providerName string, r Recipient) (string, error) {
+	registry, err := rh.recipientRegistry(providerName)
+	if err != nil {
+		return "", err
+	}
+	return registry.CreateRecipient(ctx, r)
+}
+
+// FindRecipient looks up a registered recipient with the named provider
+func (rh *RemittanceHub) FindRecipient(ctx context.Context, providerName string, criteria RecipientCriteria) (string, error) {
+	registry, err := rh.recipientRegistry(providerName)
+	if err != nil {
+		return "", err
+	}
+	return registry.FindRecipient(ctx, criteria)
+}
+
+func (rh *RemittanceHub) recipientRegistry(providerName string) (RecipientRegistry, error) {
+	provider, err := rh.findProvider(providerName)
+	if err != nil {
+		return nil, err
+	}
+	registry, ok := provider.(RecipientRegistry)
+	if !ok {
+		return nil, &UnsupportedError{Provider: providerName, Operation: "recipient registration"}
+	}
+	return registry, nil
+}
+
+// findOrCreateRecipient returns the ID of an account matching r, creating
+// one only if none exists, so repeated sends don't pile up duplicates.
+// IDs are cached, so repeat sends to the same account make no calls.
+func findOrCreateRecipient(ctx context.Context, registry RecipientRegistry, cache *recipientCache, r Recipient) (string, error) {
+	if id, ok := cache.get(r); ok {
+		return id, nil
+	}
+	id, err := registry.FindRecipient(ctx, CriteriaFor(r))
+	if errors.Is(err, ErrRecipientNotFound) {
+		id, err = registry.CreateRecipient(ctx, r)
+	}
+	if err != nil {
+		return "", err
+	}
+	cache.put(r, id)
+	return id, nil
+}
+
+// wiseDetailKeys maps Recipient.BankDetails keys to Wise account details
+var wiseDetailKeys = map[string]string{
+	BankFieldAccountNumber: "accountNumber",
+	BankFieldRoutingNumber: "abartn",
+	BankFieldIFSC:          "ifscCode",
+	BankFieldIBAN:          "iban",
+	BankFieldBIC:           "bic",
+}
+
+// wiseAccountType picks Wise's account requirements type for r
+func wiseAccountType(r Recipient) string {
+	switch {
+	case r.BankDetails[BankFieldIBAN] != "":
+		return "iban"
+	case r.Currency == INR:
+		return "indian"
+	case r.Currency == USD:
+		return "aba"
+	case r.Currency == GBP:
+		return "sort_code"
+	}
+	return strings.ToLower(r.Address.CountryCode)
+}
+
+type wiseAccount struct {
+	ID                json.Number       `json:"id"`
+	AccountHolderName string            `json:"accountHolderName"`
+	Currency          string            `json:"currency"`
+	Details           map[string]string `json:"details"`
+}
+
+func (w *WiseProvider) CreateRecipient(ctx context.Context, r Recipient) (string, error) {
+	if r.Currency == "" {
+		return "", &ValidationError{Field: "recipient.currency", Err: errors.New("required to register a Wise account")}
+	}
+	details := make(map[string]string)
+	for key, value := range r.BankDetails {
+		if wiseKey, ok := wiseDetailKeys[key]; ok {
+			details[wiseKey] = value
+		}
+	}
+	if r.Email != "" {
+		details["email"] = r.Email
+	}
+
+	resp, err := w.makeRequest(ctx, "POST", "/v1/accounts", map[string]interface{}{
+		"profile":           w.ProfileID,
+		"accountHolderName": r.Name,
+		"currency":          r.Currency,
+		"type":              wiseAccountType(r),
+		"details":           details,
+	})
+	if err != nil {
+		return "", fmt.Errorf("wise create recipient: %w", err)
+	}
+	defer resp.Body.Close()
+
+	var account wiseAccount
+	if err := json.NewDecoder(resp.Body).Decode(&account); err != nil {
+		return "", fmt.Errorf("wise create recipient: %w", err)
+	}
+	return account.ID.String(), nil
+}
+
+func (w *WiseProvider) FindRecipient(ctx context.Context, criteria RecipientCriteria) (string, error) {
+	query := url.Values{"profile": {w.ProfileID}}
+	if criteria.Currency != "" {
+		query.Set("currency", string(criteria.Currency))
+	}
+	resp, err := w.makeRequest(ctx, "GET", "/v1/accounts?"+query.Encode(), nil)
+	if err != nil {
+		return "", fmt.Errorf("wise find recipient: %w", err)
+	}
+	defer resp.Body.Close()
+
+	var accounts []wiseAccount
+	if err := json.NewDecoder(resp.Body).Decode(&accounts); err != nil {
+		return "", fmt.Errorf("wise find recipient: %w", err)
+	}
+
+	for _, account := range accounts {
+		// Translate Wise's detail names back to BankDetails keys
+		details := make(map[string]string)
+		for key, wiseKey := range wiseDetailKeys {
+			if value, ok := account.Details[wiseKey]; ok {
+				details[key] = value
+			}
+		}
+		if criteria.matches(account.AccountHolderName, details) {
+			return account.ID.String(), nil
+		}
+	}
+	return "", ErrRecipientNotFound
+}
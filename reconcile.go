@@ -0,0 +1,92 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// StatusChange is a stored transaction whose provider status moved on
+type StatusChange struct {
+	TransactionID string
+	Provider      string
+	Reference     string
+	From          TransactionStatus
+	To            TransactionStatus
+}
+
+// ReconcileFailure is a stored transaction whose status could not be
+// refreshed, e.g. its provider is no longer registered or the lookup failed.
+type ReconcileFailure struct {
+	TransactionID string
+	Provider      string
+	Reference     string
+	Err           error
+}
+
+// ReconcileReport summarises one Reconcile pass
+type ReconcileReport struct {
+	Checked    int
+	Changes    []StatusChange
+	Unresolved []ReconcileFailure
+}
+
+// Reconcile refreshes every non-terminal transaction in store from its
+// provider, writes the new status back and reports what diverged. Per-record
+// failures land in Unresolved; the error is only set when the store can't be
+// listed or ctx is done, in which case the partial report is still returned.
+func (rh *RemittanceHub) Reconcile(ctx context.Context, store TransactionStore) (ReconcileReport, error) {
+	var report ReconcileReport
+
+	records, err := store.List(ctx, TransactionFilter{})
+	if err != nil {
+		return report, fmt.Errorf("listing transactions: %w", err)
+	}
+
+	for _, record := range records {
+		if record.Response.Status.IsTerminal() {
+			continue
+		}
+		if err := ctx.Err(); err != nil {
+			return report, err
+		}
+		report.Checked++
+
+		fail := func(err error) {
+			report.Unresolved = append(report.Unresolved, ReconcileFailure{
+				TransactionID: record.TransactionID,
+				Provider:      record.Provider,
+				Reference:     record.Reference,
+				Err:           err,
+			})
+		}
+
+		provider, err := rh.findProvider(record.Provider)
+		if err != nil {
+			fail(err)
+			continue
+		}
+		tx, err := rh.transactionStatus(ctx, provider, record.TransactionID)
+		if err != nil {
+			fail(err)
+			continue
+		}
+
+		previous := record.Response.Status
+		record.applyStatus(tx, time.Now())
+		if err := store.Save(ctx, record); err != nil {
+			fail(fmt.Errorf("saving status: %w", err))
+			continue
+		}
+		if tx.Status != previous {
+			report.Changes = append(report.Changes, StatusChange{
+				TransactionID: record.TransactionID,
+				Provider:      record.Provider,
+				Reference:     record.Reference,
+				From:          previous,
+				To:            tx.Status,
+			})
+		}
+	}
+	return report, nil
+}
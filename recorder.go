@@ -0,0 +1,259 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// Interaction is one recorded provider HTTP exchange. Secret headers, body
+// fields and query parameters are redacted before it reaches a sink.
+type Interaction struct {
+	Provider       string      `json:"provider"`
+	Method         string      `json:"method"`
+	URL            string      `json:"url"`
+	RequestHeader  http.Header `json:"request_header,omitempty"`
+	RequestBody    string      `json:"request_body,omitempty"`
+	StatusCode     int         `json:"status_code,omitempty"`
+	ResponseHeader http.Header `json:"response_header,omitempty"`
+	ResponseBody   string      `json:"response_body,omitempty"`
+	// Error is set instead of a response when the call failed in transport
+	Error      string        `json:"error,omitempty"`
+	RecordedAt time.Time     `json:"recorded_at"`
+	Duration   time.Duration `json:"duration"`
+}
+
+// InteractionSink stores recorded interactions. Record is called from
+// concurrent requests and must be safe for that.
+type InteractionSink interface {
+	Record(interaction Interaction) error
+}
+
+// Recorder returns middleware that captures every provider HTTP exchange
+// into sink, for incident debugging and building Replayer fixtures. Bodies
+// are buffered in full, so record only while investigating. A sink failure
+// is logged and never fails the call.
+func Recorder(sink InteractionSink) Middleware {
+	return func(next RoundTripFunc) RoundTripFunc {
+		return func(req *http.Request) (*http.Response, error) {
+			interaction := Interaction{
+				Provider:      ProviderNameFromContext(req.Context()),
+				Method:        req.Method,
+				URL:           RedactURL(req.URL),
+				RequestHeader: RedactHeaders(req.Header),
+				RecordedAt:    time.Now(),
+			}
+			body, err := peekRequestBody(req)
+			if err != nil {
+				return nil, err
+			}
+			interaction.RequestBody = string(RedactBody(req.Header.Get("Content-Type"), []byte(body)))
+
+			resp, err := next(req)
+			interaction.Duration = time.Since(interaction.RecordedAt)
+			if err != nil {
+				interaction.Error = err.Error()
+			} else {
+				interaction.StatusCode = resp.StatusCode
+				interaction.ResponseHeader = RedactHeaders(resp.Header)
+				body, err := peekResponseBody(resp)
+				if err != nil {
+					return nil, err
+				}
+				interaction.ResponseBody = string(RedactBody(resp.Header.Get("Content-Type"), []byte(body)))
+			}
+
+			if recErr := sink.Record(interaction); recErr != nil {
+				log.Printf("Error recording %s %s: %v", req.Method, req.URL.Path, recErr)
+			}
+			return resp, err
+		}
+	}
+}
+
+// peekRequestBody reads the request body without consuming it
+func peekRequestBody(req *http.Request) (string, error) {
+	if req.Body == nil || req.Body == http.NoBody {
+		return "", nil
+	}
+	if req.GetBody != nil {
+		body, err := req.GetBody()
+		if err != nil {
+			return "", err
+		}
+		defer body.Close()
+		data, err := io.ReadAll(body)
+		return string(data), err
+	}
+	data, err := io.ReadAll(req.Body)
+	req.Body.Close()
+	if err != nil {
+		return "", err
+	}
+	req.Body = io.NopCloser(bytes.NewReader(data))
+	return string(data), nil
+}
+
+// peekResponseBody reads the response body and puts back an unread copy
+func peekResponseBody(resp *http.Response) (string, error) {
+	data, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return "", err
+	}
+	resp.Body = io.NopCloser(bytes.NewReader(data))
+	return string(data), nil
+}
+
+// JSONLinesSink writes each interaction as one line of JSON, the format
+// LoadInteractions reads back
+type JSONLinesSink struct {
+	mu sync.Mutex
+	w  io.Writer
+}
+
+func NewJSONLinesSink(w io.Writer) *JSONLinesSink {
+	return &JSONLinesSink{w: w}
+}
+
+func (s *JSONLinesSink) Record(interaction Interaction) error {
+	line, err := json.Marshal(interaction)
+	if err != nil {
+		return err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	_, err = s.w.Write(append(line, '\n'))
+	return err
+}
+
+// MemorySink keeps interactions in memory, e.g. to hand to NewReplayer
+type MemorySink struct {
+	mu           sync.Mutex
+	interactions []Interaction
+}
+
+func (s *MemorySink) Record(interaction Interaction) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.interactions = append(s.interactions, interaction)
+	return nil
+}
+
+// Interactions returns a copy of everything recorded so far, in order
+func (s *MemorySink) Interactions() []Interaction {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]Interaction(nil), s.interactions...)
+}
+
+// LoadInteractions reads a JSONLinesSink recording
+func LoadInteractions(r io.Reader) ([]Interaction, error) {
+	var interactions []Interaction
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+	for line := 1; scanner.Scan(); line++ {
+		if len(bytes.TrimSpace(scanner.Bytes())) == 0 {
+			continue
+		}
+		var interaction Interaction
+		if err := json.Unmarshal(scanner.Bytes(), &interaction); err != nil {
+			return nil, fmt.Errorf("recording line %d: %w", line, err)
+		}
+		interactions = append(interactions, interaction)
+	}
+	return interactions, scanner.Err()
+}
+
+// ErrNoRecording is returned in replay mode for a request with no unused
+// recorded interaction
+var ErrNoRecording = errors.New("no recorded interaction for request")
+
+// Replayer serves recorded interactions instead of calling providers,
+// VCR-style. A request matches the first unused interaction with the same
+// provider, method, path and query; the host is ignored so recordings taken
+// against one environment replay against another. Each interaction is
+// served once, so repeated calls replay in recorded order.
+type Replayer struct {
+	mu           sync.Mutex
+	interactions []Interaction
+	used         []bool
+}
+
+func NewReplayer(interactions []Interaction) *Replayer {
+	return &Replayer{interactions: interactions, used: make([]bool, len(interactions))}
+}
+
+// Middleware returns the replay middleware; it never calls the network
+func (r *Replayer) Middleware() Middleware {
+	return func(next RoundTripFunc) RoundTripFunc {
+		return func(req *http.Request) (*http.Response, error) {
+			interaction, ok := r.take(req)
+			if !ok {
+				return nil, fmt.Errorf("%w: %s %s", ErrNoRecording, req.Method, req.URL.RequestURI())
+			}
+			if interaction.Error != "" {
+				return nil, fmt.Errorf("replayed error: %s", interaction.Error)
+			}
+			return &http.Response{
+				Status:        fmt.Sprintf("%d %s", interaction.StatusCode, http.StatusText(interaction.StatusCode)),
+				StatusCode:    interaction.StatusCode,
+				Proto:         "HTTP/1.1",
+				ProtoMajor:    1,
+				ProtoMinor:    1,
+				Header:        interaction.ResponseHeader.Clone(),
+				Body:          io.NopCloser(bytes.NewReader([]byte(interaction.ResponseBody))),
+				ContentLength: int64(len(interaction.ResponseBody)),
+				Request:       req,
+			}, nil
+		}
+	}
+}
+
+// Remaining reports how many recorded interactions have not been served
+func (r *Replayer) Remaining() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	n := 0
+	for _, used := range r.used {
+		if !used {
+			n++
+		}
+	}
+	return n
+}
+
+func (r *Replayer) take(req *http.Request) (Interaction, bool) {
+	provider := ProviderNameFromContext(req.Context())
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for i, interaction := range r.interactions {
+		if r.used[i] || interaction.Method != req.Method {
+			continue
+		}
+		if interaction.Provider != "" && provider != "" && interaction.Provider != provider {
+			continue
+		}
+		if !sameRequestURI(interaction.URL, req) {
+			continue
+		}
+		r.used[i] = true
+		return interaction, true
+	}
+	return Interaction{}, false
+}
+
+func sameRequestURI(recorded string, req *http.Request) bool {
+	u, err := req.URL.Parse(recorded)
+	if err != nil {
+		return false
+	}
+	return u.Path == req.URL.Path && u.Query().Encode() == req.URL.Query().Encode()
+}
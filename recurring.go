@@ -0,0 +1,123 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// Frequency is how often a recurring transfer repeats
+type Frequency string
+
+const (
+	FrequencyWeekly   Frequency = "WEEKLY"
+	FrequencyBiweekly Frequency = "BIWEEKLY"
+	FrequencyMonthly  Frequency = "MONTHLY"
+)
+
+// RecurringSchedule describes when a recurring transfer runs. At most one of
+// EndDate and Occurrences may be set; with neither it runs until cancelled.
+type RecurringSchedule struct {
+	Frequency   Frequency `json:"frequency"`
+	StartDate   time.Time `json:"start_date"`
+	EndDate     time.Time `json:"end_date"`
+	Occurrences int       `json:"occurrences,omitempty"`
+}
+
+// Validate checks the schedule is well formed and starts in the future
+func (s RecurringSchedule) Validate() error {
+	switch s.Frequency {
+	case FrequencyWeekly, FrequencyBiweekly, FrequencyMonthly:
+	default:
+		return fmt.Errorf("unknown recurrence frequency %q", s.Frequency)
+	}
+	if !s.StartDate.After(time.Now()) {
+		return errors.New("recurring schedule must start in the future")
+	}
+	if !s.EndDate.IsZero() && s.Occurrences > 0 {
+		return errors.New("recurring schedule takes an end date or an occurrence count, not both")
+	}
+	if !s.EndDate.IsZero() && !s.EndDate.After(s.StartDate) {
+		return errors.New("recurring schedule end date must be after its start date")
+	}
+	if s.Occurrences < 0 {
+		return errors.New("recurring schedule occurrences cannot be negative")
+	}
+	return nil
+}
+
+// RecurringTransfer is a provider-side standing order
+type RecurringTransfer struct {
+	ID            string            `json:"id"`
+	Provider      string            `json:"provider"`
+	Schedule      RecurringSchedule `json:"schedule"`
+	Amount        float64           `json:"amount"`
+	FromCurrency  Currency          `json:"from_currency"`
+	ToCurrency    Currency          `json:"to_currency"`
+	RecipientID   string            `json:"recipient_id"`
+	NextExecution time.Time         `json:"next_execution"`
+	Active        bool              `json:"active"`
+}
+
+// RecurringTransferProvider is implemented by providers with native
+// recurrence (Capabilities.RecurringTransfers)
+type RecurringTransferProvider interface {
+	CreateRecurringTransfer(ctx context.Context, req TransactionRequest, schedule RecurringSchedule) (*RecurringTransfer, error)
+	ListRecurringTransfers(ctx context.Context) ([]*RecurringTransfer, error)
+	CancelRecurringTransfer(ctx context.Context, recurringID string) error
+}
+
+func (rh *RemittanceHub) recurringProvider(providerName string) (RecurringTransferProvider, error) {
+	provider, err := rh.findProvider(providerName)
+	if err != nil {
+		return nil, err
+	}
+	recurring, ok := provider.(RecurringTransferProvider)
+	if !ok || !provider.GetCapabilities().RecurringTransfers {
+		return nil, &UnsupportedError{Provider: providerName, Operation: "recurring transfers"}
+	}
+	return recurring, nil
+}
+
+// CreateRecurringTransfer sets up a standing order with a provider that
+// supports recurrence natively. Providers without it return ErrUnsupported;
+// the hub does not emulate recurrence with repeated one-off sends because it
+// has no scheduler of its own that survives restarts.
+func (rh *RemittanceHub) CreateRecurringTransfer(ctx context.Context, providerName string, req TransactionRequest, schedule RecurringSchedule) (*RecurringTransfer, error) {
+	if err := req.Validate(); err != nil {
+		return nil, err
+	}
+	if err := schedule.Validate(); err != nil {
+		return nil, err
+	}
+	if err := rh.screen(ctx, req.Recipient); err != nil {
+		return nil, err
+	}
+	if err := rh.checkPolicy(ctx, req); err != nil {
+		return nil, err
+	}
+	recurring, err := rh.recurringProvider(providerName)
+	if err != nil {
+		return nil, err
+	}
+	return recurring.CreateRecurringTransfer(ctx, req, schedule)
+}
+
+// ListRecurringTransfers lists a provider's standing orders
+func (rh *RemittanceHub) ListRecurringTransfers(ctx context.Context, providerName string) ([]*RecurringTransfer, error) {
+	recurring, err := rh.recurringProvider(providerName)
+	if err != nil {
+		return nil, err
+	}
+	return recurring.ListRecurringTransfers(ctx)
+}
+
+// CancelRecurringTransfer stops future executions of a standing order
+func (rh *RemittanceHub) CancelRecurringTransfer(ctx context.Context, providerName, recurringID string) error {
+	recurring, err := rh.recurringProvider(providerName)
+	if err != nil {
+		return err
+	}
+	return recurring.CancelRecurringTransfer(ctx, recurringID)
+}
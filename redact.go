@@ -0,0 +1,196 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+)
+
+// Redacted replaces secret values in anything logged, recorded or put in
+// an error
+const Redacted = "[REDACTED]"
+
+// secretNameParts mark a header, JSON field, form field or query parameter
+// as secret when they appear anywhere in its lower-cased name
+var secretNameParts = []string{
+	"authorization", "api-key", "api_key", "apikey", "secret", "password",
+	"token", "signature", "cookie", "private-key", "private_key", "credential",
+}
+
+// IsSecretName reports whether a header or field called name holds a
+// secret, e.g. Authorization, X-API-Key, X-Signature or client_secret
+func IsSecretName(name string) bool {
+	name = strings.ToLower(name)
+	for _, part := range secretNameParts {
+		if strings.Contains(name, part) {
+			return true
+		}
+	}
+	return false
+}
+
+// RedactHeaders returns a copy of h with secret header values masked
+func RedactHeaders(h http.Header) http.Header {
+	redacted := h.Clone()
+	for name, values := range redacted {
+		if IsSecretName(name) {
+			for i := range values {
+				values[i] = Redacted
+			}
+		}
+	}
+	return redacted
+}
+
+// RedactURL returns u as a string with any userinfo and secret query
+// parameters masked
+func RedactURL(u *url.URL) string {
+	if u == nil {
+		return ""
+	}
+	copied := *u
+	if copied.User != nil {
+		copied.User = url.User(Redacted)
+	}
+	if copied.RawQuery != "" {
+		query := copied.Query()
+		changed := false
+		for name := range query {
+			if IsSecretName(name) {
+				query.Set(name, Redacted)
+				changed = true
+			}
+		}
+		if changed {
+			copied.RawQuery = query.Encode()
+		}
+	}
+	return copied.String()
+}
+
+// RedactBody masks secret fields in a JSON or form-encoded body. Other
+// bodies, and bodies with nothing to mask, are returned unchanged.
+func RedactBody(contentType string, body []byte) []byte {
+	trimmed := bytes.TrimSpace(body)
+	switch {
+	case strings.Contains(contentType, "json") || bytes.HasPrefix(trimmed, []byte("{")) || bytes.HasPrefix(trimmed, []byte("[")):
+		decoder := json.NewDecoder(bytes.NewReader(trimmed))
+		decoder.UseNumber() // keep large IDs exact
+		var doc interface{}
+		if decoder.Decode(&doc) != nil || !redactJSON(doc) {
+			return body
+		}
+		redacted, err := json.Marshal(doc)
+		if err != nil {
+			return body
+		}
+		return redacted
+	case strings.Contains(contentType, "x-www-form-urlencoded"):
+		form, err := url.ParseQuery(string(trimmed))
+		if err != nil {
+			return body
+		}
+		changed := false
+		for name := range form {
+			if IsSecretName(name) {
+				form.Set(name, Redacted)
+				changed = true
+			}
+		}
+		if !changed {
+			return body
+		}
+		return []byte(form.Encode())
+	}
+	return body
+}
+
+// redactJSON masks secret fields in a decoded JSON document in place,
+// reporting whether it changed anything
+func redactJSON(doc interface{}) bool {
+	changed := false
+	switch v := doc.(type) {
+	case map[string]interface{}:
+		for key, value := range v {
+			if IsSecretName(key) {
+				if _, isObject := value.(map[string]interface{}); !isObject {
+					v[key] = Redacted
+					changed = true
+					continue
+				}
+			}
+			if redactJSON(value) {
+				changed = true
+			}
+		}
+	case []interface{}:
+		for _, value := range v {
+			if redactJSON(value) {
+				changed = true
+			}
+		}
+	}
+	return changed
+}
+
+// RedactSecrets replaces every occurrence of the given secret values in s,
+// for text that may echo a credential back (e.g. a provider error message)
+func RedactSecrets(s string, secrets ...string) string {
+	for _, secret := range secrets {
+		if len(secret) >= 4 {
+			s = strings.ReplaceAll(s, secret, Redacted)
+		}
+	}
+	return s
+}
+
+// DumpRequest renders req for logs with every secret masked: the URL,
+// headers and body go through the Redact helpers. The body is left
+// readable for sending.
+func DumpRequest(req *http.Request) (string, error) {
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s %s\n", req.Method, RedactURL(req.URL))
+
+	headers := RedactHeaders(req.Header)
+	names := make([]string, 0, len(headers))
+	for name := range headers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		fmt.Fprintf(&b, "%s: %s\n", name, strings.Join(headers[name], ", "))
+	}
+
+	body, err := peekRequestBody(req)
+	if err != nil {
+		return "", err
+	}
+	if body != "" {
+		b.WriteString("\n")
+		b.Write(RedactBody(req.Header.Get("Content-Type"), []byte(body)))
+	}
+	return b.String(), nil
+}
+
+// redactError masks the URL in transport errors, which net/http reports
+// with the full request URL
+func redactError(err error) error {
+	urlErr, ok := err.(*url.Error)
+	if !ok {
+		return err
+	}
+	parsed, parseErr := url.Parse(urlErr.URL)
+	if parseErr != nil {
+		return err
+	}
+	redacted := *urlErr
+	redacted.URL = RedactURL(parsed)
+	if redacted.URL == urlErr.URL {
+		return err
+	}
+	return &redacted
+}
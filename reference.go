@@ -0,0 +1,163 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+)
+
+// ReferenceRateProvider supplies independent mid-market rates, used to
+// measure each provider's exchange-rate margin. It is optional: without one
+// the hub falls back to the best rate quoted (see cost.go).
+type ReferenceRateProvider interface {
+	// MidMarketRate returns units of to per unit of from
+	MidMarketRate(ctx context.Context, from, to Currency) (float64, error)
+}
+
+// StaticReferenceRates is a fixed ReferenceRateProvider for tests and
+// offline use
+type StaticReferenceRates map[CurrencyPair]float64
+
+func (s StaticReferenceRates) MidMarketRate(ctx context.Context, from, to Currency) (float64, error) {
+	rate, ok := s[CurrencyPair{From: from, To: to}]
+	if !ok || rate <= 0 {
+		return 0, fmt.Errorf("no reference rate for %s", CurrencyPair{From: from, To: to})
+	}
+	return rate, nil
+}
+
+// defaultReferenceRateTTL matches the ECB's once-a-day publication closely
+// enough while still picking up a new fixing within the hour
+const defaultReferenceRateTTL = time.Hour
+
+// ECBReferenceRates reads ECB reference rates through the free Frankfurter
+// API (no key needed) and caches them per pair for TTL.
+type ECBReferenceRates struct {
+	BaseURL string
+	TTL     time.Duration
+	client  *http.Client
+
+	mu    sync.Mutex
+	cache map[CurrencyPair]cachedReferenceRate
+}
+
+type cachedReferenceRate struct {
+	rate      float64
+	fetchedAt time.Time
+}
+
+func NewECBReferenceRates() *ECBReferenceRates {
+	return &ECBReferenceRates{
+		BaseURL: "https://api.frankfurter.app",
+		TTL:     defaultReferenceRateTTL,
+		client:  newProviderClient(10 * time.Second),
+		cache:   make(map[CurrencyPair]cachedReferenceRate),
+	}
+}
+
+func (e *ECBReferenceRates) MidMarketRate(ctx context.Context, from, to Currency) (float64, error) {
+	pair := CurrencyPair{From: from, To: to}
+
+	e.mu.Lock()
+	cached, ok := e.cache[pair]
+	e.mu.Unlock()
+	if ok && time.Since(cached.fetchedAt) < e.TTL {
+		return cached.rate, nil
+	}
+
+	rate, err := e.fetch(ctx, from, to)
+	if err != nil {
+		return 0, err
+	}
+
+	e.mu.Lock()
+	e.cache[pair] = cachedReferenceRate{rate: rate, fetchedAt: time.Now()}
+	e.mu.Unlock()
+	return rate, nil
+}
+
+func (e *ECBReferenceRates) fetch(ctx context.Context, from, to Currency) (float64, error) {
+	endpoint := fmt.Sprintf("%s/latest?from=%s&to=%s", e.BaseURL, url.QueryEscape(string(from)), url.QueryEscape(string(to)))
+	req, err := http.NewRequestWithContext(ctx, "GET", endpoint, nil)
+	if err != nil {
+		return 0, err
+	}
+	resp, err := e.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return 0, fmt.Errorf("reference rate %s: status %d", CurrencyPair{From: from, To: to}, resp.StatusCode)
+	}
+	var body struct {
+		Rates map[string]float64 `json:"rates"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return 0, err
+	}
+	rate := body.Rates[string(to)]
+	if rate <= 0 {
+		return 0, fmt.Errorf("no reference rate for %s", CurrencyPair{From: from, To: to})
+	}
+	return rate, nil
+}
+
+// SetReferenceRates makes the hub value quotes against an independent
+// mid-market source and annotate them with their margin. Call it before
+// serving traffic; nil reverts to the best-quoted-rate fallback.
+func (rh *RemittanceHub) SetReferenceRates(source ReferenceRateProvider) {
+	rh.reference = source
+}
+
+// referenceRate returns the mid-market rate for req's pair, or 0 if no
+// source is configured or it fails
+func (rh *RemittanceHub) referenceRate(ctx context.Context, req TransactionRequest) float64 {
+	if rh.reference == nil {
+		return 0
+	}
+	rate, err := rh.midMarketRate(ctx, req.FromCurrency, req.ToCurrency)
+	if err != nil {
+		log.Printf("Reference rate unavailable for %s: %v", CurrencyPair{From: req.FromCurrency, To: req.ToCurrency}, err)
+		return 0
+	}
+	return rate
+}
+
+// midMarketRate asks the reference source for a rate, through the batch's
+// shared lookups when ctx carries them (see GetQuotesBatch)
+func (rh *RemittanceHub) midMarketRate(ctx context.Context, from, to Currency) (float64, error) {
+	if shared, ok := ctx.Value(sharedReferenceRatesKey{}).(*sharedReferenceRates); ok {
+		return shared.get(ctx, rh.reference, CurrencyPair{From: from, To: to})
+	}
+	return rh.reference.MidMarketRate(ctx, from, to)
+}
+
+// annotateMidMarket fetches the mid-market rate for req's pair and
+// annotates quotes with it, returning it (0 if unavailable)
+func (rh *RemittanceHub) annotateMidMarket(ctx context.Context, quotes []*RemittanceQuote, req TransactionRequest) float64 {
+	midMarket := rh.referenceRate(ctx, req)
+	annotateMargin(quotes, midMarket)
+	return midMarket
+}
+
+// annotateMargin records the mid-market rate on each quote and the
+// provider's margin below it as a percentage, or flags the margin as
+// unavailable when midMarket is 0
+func annotateMargin(quotes []*RemittanceQuote, midMarket float64) {
+	for _, quote := range quotes {
+		if midMarket <= 0 {
+			quote.MidMarketRate, quote.MarginPercent, quote.MarginUnavailable = 0, 0, true
+			continue
+		}
+		quote.MarginUnavailable = false
+		quote.MidMarketRate = midMarket
+		quote.MarginPercent = (midMarket - quote.ExchangeRate) / midMarket * 100
+	}
+}
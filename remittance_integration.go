@@ -3,18 +3,17 @@ package main
 import (
 	"bytes"
 	"context"
-	"crypto/hmac"
-	"crypto/sha256"
-	"encoding/hex"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
 	"log"
 	"net/http"
+	"net/url"
 	"sort"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
 )
 
@@ -31,12 +30,15 @@ const (
 	INR Currency = "INR"
 	PHP Currency = "PHP"
 	MXN Currency = "MXN"
+	JPY Currency = "JPY"
 	
 	// Transaction Status
-	StatusPending   TransactionStatus = "PENDING"
-	StatusCompleted TransactionStatus = "COMPLETED"
-	StatusFailed    TransactionStatus = "FAILED"
-	StatusCancelled TransactionStatus = "CANCELLED"
+	StatusPending       TransactionStatus = "PENDING"
+	StatusCompleted     TransactionStatus = "COMPLETED"
+	StatusFailed        TransactionStatus = "FAILED"
+	StatusCancelled     TransactionStatus = "CANCELLED"
+	StatusRefundPending TransactionStatus = "REFUND_PENDING"
+	StatusRefunded      TransactionStatus = "REFUNDED"
 	
 	// Payment Methods
 	PaymentBankTransfer PaymentMethod = "BANK_TRANSFER"
@@ -45,6 +47,11 @@ const (
 	PaymentCash         PaymentMethod = "CASH"
 )
 
+// IsTerminal reports whether no further status transitions are expected
+func (s TransactionStatus) IsTerminal() bool {
+	return s == StatusCompleted || s == StatusFailed || s == StatusCancelled || s == StatusRefunded
+}
+
 // Common structures
 type Recipient struct {
 	ID          string            `json:"id"`
@@ -53,6 +60,10 @@ type Recipient struct {
 	Phone       string            `json:"phone"`
 	Address     Address           `json:"address"`
 	BankDetails map[string]string `json:"bank_details,omitempty"`
+	// Currency is the currency the account receives, needed to register it
+	// with providers that require it (see RecipientRegistry); sends default
+	// it to ToCurrency
+	Currency Currency `json:"currency,omitempty"`
 }
 
 type Address struct {
@@ -70,6 +81,9 @@ type ExchangeRate struct {
 	Rate       float64   `json:"rate"`
 	Fee        float64   `json:"fee"`
 	ValidUntil time.Time `json:"valid_until"`
+	// Derived is set when the rate was computed from the reverse pair
+	// rather than quoted by the provider (see InverseRate)
+	Derived bool `json:"derived,omitempty"`
 }
 
 type TransactionRequest struct {
@@ -78,9 +92,58 @@ type TransactionRequest struct {
 	Amount         float64       `json:"amount"`
 	FromCurrency   Currency      `json:"from_currency"`
 	ToCurrency     Currency      `json:"to_currency"`
+	// PaymentMethod is how the recipient is paid out (bank deposit, cash
+	// pickup, wallet); FundingMethod is how the sender pays
 	PaymentMethod  PaymentMethod `json:"payment_method"`
 	Purpose        string        `json:"purpose"`
 	Reference      string        `json:"reference"`
+	// ScheduledFor, if set, asks the provider to execute the transfer at
+	// that time instead of immediately (see Capabilities.ScheduledTransfers)
+	ScheduledFor *time.Time `json:"scheduled_for,omitempty"`
+	// LockedQuote, if set, commits SendMoney to the locked rate and fee
+	LockedQuote *LockedQuote `json:"locked_quote,omitempty"`
+	// IdempotencyKey is passed to providers that deduplicate sends, so a
+	// retried SendMoney can't create a second transfer. Defaults to
+	// Reference; with neither set the hub generates one per send, which
+	// the caller can't look up afterwards, so set one to be able to recover.
+	IdempotencyKey string `json:"idempotency_key,omitempty"`
+	// ComplianceInfo carries extra due diligence answers (source of funds
+	// etc.) when a PolicyEngine requires them
+	ComplianceInfo map[string]string `json:"compliance_info,omitempty"`
+	// Quote is the quote the user accepted. The send is refused once its
+	// ValidUntil has passed unless AllowExpired is set, since the provider
+	// would apply a different rate and fee.
+	Quote        *RemittanceQuote `json:"quote,omitempty"`
+	AllowExpired bool             `json:"allow_expired,omitempty"`
+	// FeePaidBy chooses who bears the fee; empty means FeePaidBySender
+	FeePaidBy FeePaidBy `json:"fee_paid_by,omitempty"`
+	// PromoCode is forwarded to providers with Capabilities.PromoCodes. A
+	// code the provider rejects becomes a quote warning, not an error.
+	PromoCode string `json:"promo_code,omitempty"`
+	// PayoutNetwork, if set, restricts quoting and sending to providers
+	// that can pay out over that network in the recipient's country
+	PayoutNetwork PayoutNetwork `json:"payout_network,omitempty"`
+	// RoundingMode controls how quotes round ReceivedAmount; the zero
+	// value rounds by payout method (see RoundingAuto)
+	RoundingMode RoundingMode `json:"rounding_mode,omitempty"`
+	// FundingCurrency is the sender's balance the transfer is paid from;
+	// empty means FromCurrency. A different currency adds a conversion leg
+	// and needs Capabilities.MultiCurrencyFunding.
+	FundingCurrency Currency `json:"funding_currency,omitempty"`
+	// FundingMethod is how the sender pays, BANK_TRANSFER or CARD; empty
+	// leaves it to the provider. Providers that list
+	// Capabilities.FundingMethods are skipped when it isn't one of them.
+	FundingMethod PaymentMethod `json:"funding_method,omitempty"`
+	// Sender, if set, excludes providers whose eligibility rules the
+	// sender doesn't meet (see Capabilities.Eligibility)
+	Sender *SenderProfile `json:"sender,omitempty"`
+	// PurposeCode is the provider's code for Purpose. Providers with a
+	// fixed list (PurposeCodeProvider) need one; if empty the hub derives
+	// it from Purpose when that names a PaymentPurpose ("Family support").
+	PurposeCode string `json:"purpose_code,omitempty"`
+	// TargetAmount is what the recipient should receive, in ToCurrency.
+	// Only GetBestQuoteForTarget reads it; it solves for Amount.
+	TargetAmount float64 `json:"target_amount,omitempty"`
 }
 
 type TransactionResponse struct {
@@ -91,29 +154,197 @@ type TransactionResponse struct {
 	ExchangeRate  float64           `json:"exchange_rate"`
 	EstimatedTime string            `json:"estimated_time"`
 	TrackingURL   string            `json:"tracking_url,omitempty"`
+	// Error is the provider's failure reason. It is set exactly when Status
+	// is StatusFailed (with a generic reason if the provider gave none) and
+	// is empty otherwise. It describes the transfer, not the call: a call
+	// that fails returns a nil response and a non-nil error, so the two
+	// never coexist. A provider accepting a send and reporting it failed
+	// straight away is a successful call returning a StatusFailed response.
 	Error         string            `json:"error,omitempty"`
+	// ScheduledFor is set for transfers waiting on a future execution date
+	ScheduledFor *time.Time `json:"scheduled_for,omitempty"`
+	// Warnings are caveats from the provider; RequiredActions are steps the
+	// sender must complete before the transfer proceeds
+	Warnings        []string `json:"warnings,omitempty"`
+	RequiredActions []string `json:"required_actions,omitempty"`
+	// RequiredDocuments are compliance documents the provider is holding
+	// the transfer for; each also has a RequiredActions entry. See
+	// RemittanceHub.UploadComplianceDocument.
+	RequiredDocuments []DocumentType `json:"required_documents,omitempty"`
+	// FeeUnknown is set when the provider accepted the transfer without
+	// saying what it charged, so Fee is zero for want of a figure
+	FeeUnknown bool `json:"fee_unknown,omitempty"`
+	// FundingFee is the part of Fee charged for the funding method (e.g. a
+	// card surcharge), when the provider breaks it out
+	FundingFee float64 `json:"funding_fee,omitempty"`
+	// TotalDebit is exactly what leaves the sender's account, in
+	// DebitCurrency; set by the hub on send, see setTotalDebit
+	TotalDebit    float64  `json:"total_debit,omitempty"`
+	DebitCurrency Currency `json:"debit_currency,omitempty"`
+	// Progress is where the transfer is on its way to the recipient, from
+	// the provider's own state; nil once it won't get there. See
+	// TrackingStep.
+	Progress *TrackingProgress `json:"progress,omitempty"`
 }
 
 type RemittanceQuote struct {
 	Provider      string    `json:"provider"`
+	// QuoteID is the provider's identifier for the quote, when it issues one
+	QuoteID       string    `json:"quote_id,omitempty"`
 	Amount        float64   `json:"amount"`
 	Fee           float64   `json:"fee"`
 	ExchangeRate  float64   `json:"exchange_rate"`
+	// RateImplied is set when the provider gave no rate and ExchangeRate
+	// was derived from the amounts; see fillImpliedRate
+	RateImplied   bool      `json:"rate_implied,omitempty"`
 	TotalCost     float64   `json:"total_cost"`
 	ReceivedAmount float64  `json:"received_amount"`
 	EstimatedTime string    `json:"estimated_time"`
+	EstimatedMin  time.Duration `json:"estimated_min"`
+	EstimatedMax  time.Duration `json:"estimated_max"`
 	ValidUntil    time.Time `json:"valid_until"`
+	// Warnings are caveats to show before the user commits (e.g. receiving
+	// bank fees); RequiredActions are steps needed before a send can succeed
+	Warnings        []string `json:"warnings,omitempty"`
+	RequiredActions []string `json:"required_actions,omitempty"`
+	// validityAssumed is set when the provider reported no expiry and
+	// ValidUntil is the hub's assumption; see setExpiry
+	validityAssumed bool
+	// EffectiveCost is filled in by the hub; see effectiveCost
+	EffectiveCost float64 `json:"effective_cost"`
+	// MidMarketRate and MarginPercent are set when the hub has a
+	// ReferenceRateProvider: MarginPercent is (MidMarketRate -
+	// ExchangeRate) / MidMarketRate as a percentage (1.5 = 1.5%). When no
+	// reference rate could be had they stay zero and MarginUnavailable is
+	// set, so a zero margin is never mistaken for a perfect rate.
+	MidMarketRate     float64 `json:"mid_market_rate,omitempty"`
+	MarginPercent     float64 `json:"margin_percent,omitempty"`
+	MarginUnavailable bool    `json:"margin_unavailable,omitempty"`
+	// FeePaidBy is the fee model the quote was priced with, which may differ
+	// from the one requested if the provider supports only one
+	FeePaidBy FeePaidBy `json:"fee_paid_by"`
+	// DiscountApplied is the amount a promo code took off Fee
+	DiscountApplied float64 `json:"discount_applied,omitempty"`
+	// EstimatedArrival is when the money should reach the recipient, in
+	// their country's timezone; set by the hub, see EstimateArrival
+	EstimatedArrival time.Time `json:"estimated_arrival,omitempty"`
+	// FundingLeg is the conversion from the requested FundingCurrency
+	// balance into the send currency; its Amount is what leaves that
+	// balance to cover TotalCost
+	FundingLeg *ConversionLeg `json:"funding_leg,omitempty"`
+	// FundingFee is the part of Fee charged for the funding method (e.g. a
+	// card surcharge), when the provider breaks it out
+	FundingFee float64 `json:"funding_fee,omitempty"`
+	// NormalizedCost is what the sender pays in the request's FromCurrency,
+	// counting any funding conversion; set by the hub, see cost.go
+	NormalizedCost float64 `json:"normalized_cost"`
+}
+
+// DeliveryWindow returns the structured delivery range, falling back to
+// parsing EstimatedTime for quotes that didn't populate it.
+func (q *RemittanceQuote) DeliveryWindow() (min, max time.Duration, ok bool) {
+	if q.EstimatedMax > 0 {
+		return q.EstimatedMin, q.EstimatedMax, true
+	}
+	return ParseEstimatedTime(q.EstimatedTime)
 }
 
 // RemittanceProvider interface that all providers must implement
 type RemittanceProvider interface {
 	GetName() string
 	GetSupportedCurrencies() []Currency
-	GetSupportedCountries() []string
+	// GetSendingCountries lists the ISO codes of countries the provider
+	// accepts transfers from and GetReceivingCountries those it pays out
+	// in; the two sets usually differ. An empty list means unrestricted.
+	GetSendingCountries() []string
+	GetReceivingCountries() []string
+	// GetSupportedRoutes lists the exact currency/country combinations the
+	// provider serves; the flat lists above don't say which go together
+	GetSupportedRoutes() []Route
+	// SupportsCorridor reports whether the provider sends from one currency
+	// to the other, in that direction, for payout in country ("" = any)
+	SupportsCorridor(from, to Currency, country string) bool
+	GetCapabilities() Capabilities
 	GetQuote(ctx context.Context, req TransactionRequest) (*RemittanceQuote, error)
 	SendMoney(ctx context.Context, req TransactionRequest) (*TransactionResponse, error)
 	GetTransactionStatus(ctx context.Context, transactionID string) (*TransactionResponse, error)
+	// GetTransactionEvents returns the transfer's timeline (created, funds
+	// received, paid out, ...) mapped from the provider's event feed
+	GetTransactionEvents(ctx context.Context, transactionID string) ([]TransactionEvent, error)
 	GetExchangeRates(ctx context.Context, from, to Currency) (*ExchangeRate, error)
+	GetCorridorLimits(ctx context.Context, from, to Currency, country string) (min, max float64, err error)
+	// GetExchangeRatesBatch returns rates for many pairs at once. On partial
+	// failure the successful pairs are returned along with a non-nil error.
+	GetExchangeRatesBatch(ctx context.Context, pairs []CurrencyPair) (map[CurrencyPair]*ExchangeRate, error)
+	// RefundTransaction asks the provider to return a failed or bounced
+	// transfer to the sender. Providers that refund automatically just
+	// return the current status, which will show the refund once it lands.
+	RefundTransaction(ctx context.Context, transactionID, reason string) (*TransactionResponse, error)
+}
+
+// Capabilities advertises optional behaviour a provider supports, so the
+// hub can reject unsupported requests before calling the provider.
+type Capabilities struct {
+	// ScheduledTransfers: SendMoney honours TransactionRequest.ScheduledFor
+	// and the provider implements TransferScheduler
+	ScheduledTransfers bool
+	// RecurringTransfers: the provider implements RecurringTransferProvider
+	RecurringTransfers bool
+	// RateLock: the provider implements RateLocker and SendMoney honours
+	// TransactionRequest.LockedQuote
+	RateLock bool
+	// FeeModels lists the TransactionRequest.FeePaidBy values the provider
+	// can price; nil means sender-pays only
+	FeeModels []FeePaidBy
+	// PromoCodes: GetQuote and SendMoney forward TransactionRequest.PromoCode
+	PromoCodes bool
+	// PayoutNetworks lists, per destination country, the networks the
+	// provider can pay out over (see TransactionRequest.PayoutNetwork)
+	PayoutNetworks map[string][]PayoutNetwork
+	// MultiCurrencyFunding: the provider holds multi-currency balances and
+	// honours TransactionRequest.FundingCurrency, quoting the conversion
+	// as RemittanceQuote.FundingLeg
+	MultiCurrencyFunding bool
+	// FundingMethods lists the ways a sender can pay (BANK_TRANSFER, CARD);
+	// empty means the provider doesn't say and any method is quoted
+	FundingMethods []PaymentMethod
+	// Eligibility lists residency and age requirements on the sender
+	Eligibility []EligibilityRule
+	// RegisteredRecipients: SendMoney only pays accounts registered through
+	// RecipientRegistry, identified by Recipient.ID
+	RegisteredRecipients bool
+}
+
+// TransferScheduler is implemented by providers with ScheduledTransfers
+type TransferScheduler interface {
+	GetScheduledTransfers(ctx context.Context) ([]*TransactionResponse, error)
+	CancelScheduledTransfer(ctx context.Context, transactionID string) error
+}
+
+// corridorLimit is the per-transfer send window in the source currency.
+// A zero max means the provider publishes no upper bound. Limit tables are
+// keyed by Route; an entry with no Country covers the currency pair in
+// every destination country, and one with a Country overrides it there.
+type corridorLimit struct {
+	min float64
+	max float64
+}
+
+func (l corridorLimit) allows(amount float64) bool {
+	return amount >= l.min && (l.max == 0 || amount <= l.max)
+}
+
+func lookupCorridorLimit(provider string, limits map[Route]corridorLimit, from, to Currency, country string) (float64, float64, error) {
+	if country != "" {
+		if limit, ok := limits[Route{From: from, To: to, Country: strings.ToUpper(country)}]; ok {
+			return limit.min, limit.max, nil
+		}
+	}
+	limit, ok := limits[Route{From: from, To: to}]
+	if !ok {
+		return 0, 0, fmt.Errorf("%s has no corridor limits for %s", provider, CurrencyPair{From: from, To: to})
+	}
+	return limit.min, limit.max, nil
 }
 
 // Wise (formerly TransferWise) Provider
@@ -121,7 +352,22 @@ type WiseProvider struct {
 	APIKey    string
 	BaseURL   string
 	ProfileID string
+	// DeriveInverseRates opts in to answering a pair Wise doesn't quote
+	// directly by inverting the reverse pair
+	DeriveInverseRates bool
+	// TokenSource, when set, supplies OAuth2 tokens in place of APIKey.
+	// Each token is reused until it nears expiry.
+	TokenSource TokenSource
+	tokenOnce   sync.Once
+	// AutoCreateRecipients lets SendMoney register a recipient with no ID,
+	// reusing a matching account if one is already registered
+	AutoCreateRecipients bool
+	// recipients caches the account IDs AutoCreateRecipients resolved
+	recipients recipientCache
+	// Identity is sent with every request (User-Agent, client ID headers)
+	Identity ClientIdentity
 	client    *http.Client
+	middleware []Middleware
 }
 
 func NewWiseProvider(apiKey, profileID string) *WiseProvider {
@@ -129,7 +375,7 @@ func NewWiseProvider(apiKey, profileID string) *WiseProvider {
 		APIKey:    apiKey,
 		BaseURL:   "https://api.transferwise.com",
 		ProfileID: profileID,
-		client:    &http.Client{Timeout: 30 * time.Second},
+		client:    newProviderClient(30 * time.Second),
 	}
 }
 
@@ -141,11 +387,65 @@ func (w *WiseProvider) GetSupportedCurrencies() []Currency {
 	return []Currency{USD, EUR, GBP, INR, PHP}
 }
 
-func (w *WiseProvider) GetSupportedCountries() []string {
+func (w *WiseProvider) GetSendingCountries() []string {
+	return []string{"US", "GB", "DE", "FR", "ES"}
+}
+
+func (w *WiseProvider) GetReceivingCountries() []string {
 	return []string{"US", "GB", "IN", "PH", "DE", "FR", "ES"}
 }
 
+var wiseRoutes = routeTable(
+	[]Currency{USD, EUR, GBP},
+	map[string][]Currency{
+		"US": {USD},
+		"GB": {GBP},
+		"IN": {INR},
+		"PH": {PHP},
+		"DE": {EUR},
+		"FR": {EUR},
+		"ES": {EUR},
+	},
+)
+
+func (w *WiseProvider) GetSupportedRoutes() []Route {
+	return wiseRoutes
+}
+
+func (w *WiseProvider) SupportsCorridor(from, to Currency, country string) bool {
+	return hasRoute(wiseRoutes, from, to, country)
+}
+
+// Wise takes its fee out of the source amount, so the recipient bears it
+func (w *WiseProvider) GetCapabilities() Capabilities {
+	return Capabilities{
+		FeeModels:            []FeePaidBy{FeePaidByRecipient},
+		PayoutNetworks:       wiseNetworks,
+		MultiCurrencyFunding: true,
+		FundingMethods:       cardOrBank,
+		Eligibility:          adultsOnly,
+		RegisteredRecipients: true,
+	}
+}
+
+// Use appends middleware run around every HTTP call this provider makes
+func (w *WiseProvider) Use(middleware ...Middleware) {
+	w.middleware = append(w.middleware, middleware...)
+}
+
+// tokens wraps TokenSource on first use so tokens are cached rather than
+// fetched for every request. It returns nil when APIKey is used instead.
+func (w *WiseProvider) tokens() TokenSource {
+	w.tokenOnce.Do(func() {
+		if w.TokenSource != nil {
+			w.TokenSource = ReuseTokenSource(w.TokenSource)
+		}
+	})
+	return w.TokenSource
+}
+
 func (w *WiseProvider) makeRequest(ctx context.Context, method, endpoint string, body interface{}) (*http.Response, error) {
+	ctx = withProviderName(ctx, w.GetName())
 	var reqBody io.Reader
 	if body != nil {
 		jsonBody, err := json.Marshal(body)
@@ -160,10 +460,29 @@ func (w *WiseProvider) makeRequest(ctx context.Context, method, endpoint string,
 		return nil, err
 	}
 	
-	req.Header.Set("Authorization", "Bearer "+w.APIKey)
+	// secret is whichever credential this request carries, masked if the
+	// provider echoes it back in an error
+	secret, authorization := w.APIKey, "Bearer "+w.APIKey
+	if tokens := w.tokens(); tokens != nil {
+		token, err := tokens.Token(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("wise token: %w", err)
+		}
+		secret, authorization = token.AccessToken, token.AuthorizationHeader()
+	}
+	w.Identity.apply(req)
+	req.Header.Set("Authorization", authorization)
 	req.Header.Set("Content-Type", "application/json")
+	setCorrelationID(req)
 	
-	return w.client.Do(req)
+	resp, err := chainMiddleware(w.client.Do, w.middleware)(req)
+	if err != nil {
+		return nil, redactError(err)
+	}
+	if err := checkResponse(w.GetName(), resp, secret); err != nil {
+		return nil, err
+	}
+	return resp, nil
 }
 
 func (w *WiseProvider) GetQuote(ctx context.Context, req TransactionRequest) (*RemittanceQuote, error) {
@@ -174,6 +493,9 @@ func (w *WiseProvider) GetQuote(ctx context.Context, req TransactionRequest) (*R
 		"sourceAmount":   req.Amount,
 		"type":           "REGULAR",
 	}
+	if payIn, ok := wisePayIn[req.fundingMethod()]; ok {
+		quoteReq["payIn"] = payIn
+	}
 	
 	resp, err := w.makeRequest(ctx, "POST", "/v1/quotes", quoteReq)
 	if err != nil {
@@ -181,58 +503,127 @@ func (w *WiseProvider) GetQuote(ctx context.Context, req TransactionRequest) (*R
 	}
 	defer resp.Body.Close()
 	
-	var quoteResp map[string]interface{}
+	var quoteResp WiseQuoteResponse
 	if err := json.NewDecoder(resp.Body).Decode(&quoteResp); err != nil {
-		return nil, err
+		return nil, fmt.Errorf("wise quote: %w", err)
 	}
+	if err := quoteResp.validate(); err != nil {
+		return nil, fmt.Errorf("wise quote: %w", err)
+	}
+	warnings, actions := quoteResp.notices()
 	
-	fee := quoteResp["fee"].(float64)
-	rate := quoteResp["rate"].(float64)
-	targetAmount := quoteResp["targetAmount"].(float64)
-	
-	return &RemittanceQuote{
+	quote := &RemittanceQuote{
 		Provider:       w.GetName(),
+		QuoteID:        string(quoteResp.ID),
 		Amount:         req.Amount,
-		Fee:            fee,
-		ExchangeRate:   rate,
-		TotalCost:      req.Amount + fee,
-		ReceivedAmount: targetAmount,
+		Fee:            quoteResp.Fee,
+		ExchangeRate:   quoteResp.Rate,
+		TotalCost:      req.Amount,
+		ReceivedAmount: quoteResp.TargetAmount,
+		FeePaidBy:      FeePaidByRecipient,
 		EstimatedTime:  "1-2 business days",
-		ValidUntil:     time.Now().Add(24 * time.Hour),
-	}, nil
+		EstimatedMin:   24 * time.Hour,
+		EstimatedMax:   48 * time.Hour,
+		Warnings:        warnings,
+		RequiredActions: actions,
+	}
+	quote.setExpiry(parseExpiry(quoteResp.RateExpirationTime), wiseQuoteValidity)
+	if quote.ExchangeRate == 0 && !quote.fillImpliedRate() {
+		return nil, fmt.Errorf("wise quote: no rate, and none implied by targetAmount %v and fee %v", quoteResp.TargetAmount, quoteResp.Fee)
+	}
+	roundCost(quote, req.FromCurrency)
+	if req.fundingConversion() {
+		leg, err := w.quoteFunding(ctx, req, quote.TotalCost)
+		if err != nil {
+			return nil, err
+		}
+		quote.FundingLeg = leg
+	}
+	return quote, nil
 }
 
 func (w *WiseProvider) SendMoney(ctx context.Context, req TransactionRequest) (*TransactionResponse, error) {
-	// In real implementation, this would create a transfer
+	var resolved *Recipient
+	if req.Recipient.ID == "" {
+		if !w.AutoCreateRecipients {
+			return nil, &ValidationError{Field: "recipient.id", Err: errors.New("wise pays registered recipients only; use CreateRecipient or enable AutoCreateRecipients")}
+		}
+		recipient := req.Recipient
+		if recipient.Currency == "" {
+			recipient.Currency = req.ToCurrency
+		}
+		id, err := findOrCreateRecipient(ctx, w, &w.recipients, recipient)
+		if err != nil {
+			return nil, err
+		}
+		req.Recipient.ID, resolved = id, &recipient
+	}
+	
+	// A Wise transfer executes a quote. Without the caller's, take a fresh
+	// one, as QuoteAndSend would, so the fee is the one actually charged.
+	quote := req.Quote
+	if quote == nil || quote.QuoteID == "" {
+		fresh, err := w.GetQuote(ctx, req)
+		if err != nil {
+			return nil, fmt.Errorf("wise transfer quote: %w", err)
+		}
+		quote = fresh
+	}
+	if quote.QuoteID == "" {
+		return nil, &ValidationError{Field: "quote", Err: errors.New("wise returned a quote without an id")}
+	}
+	
 	transferReq := map[string]interface{}{
-		"targetAccount": req.Recipient.ID,
-		"quote":         "quote-id", // Would be from previous quote
-		"customerTransactionId": req.Reference,
+		"targetAccount":         req.Recipient.ID,
+		"quote":                 quote.QuoteID,
+		"customerTransactionId": req.idempotencyKey(),
 		"details": map[string]interface{}{
 			"reference": req.Purpose,
 		},
 	}
+	if source := req.ComplianceInfo[ComplianceSourceOfFunds]; source != "" {
+		transferReq["details"].(map[string]interface{})["sourceOfFunds"] = source
+	}
+	// Fill the send-currency balance first; a conversion that succeeds
+	// before a failed transfer just leaves the money in that balance
+	if req.fundingConversion() {
+		if err := w.convertFunding(ctx, req); err != nil {
+			return nil, err
+		}
+	}
 	
 	resp, err := w.makeRequest(ctx, "POST", "/v1/transfers", transferReq)
 	if err != nil {
+		if resolved != nil && isNotFound(err) {
+			// The account may have been deleted since it was cached
+			w.recipients.forget(*resolved)
+		}
 		return nil, err
 	}
 	defer resp.Body.Close()
 	
-	var transferResp map[string]interface{}
-	if err := json.NewDecoder(resp.Body).Decode(&transferResp); err != nil {
-		return nil, err
+	var transfer WiseTransferResponse
+	if err := json.NewDecoder(resp.Body).Decode(&transfer); err != nil {
+		return nil, fmt.Errorf("wise transfer: %w", err)
+	}
+	if err := transfer.validate(); err != nil {
+		return nil, fmt.Errorf("wise transfer: %w", err)
 	}
 	
-	return &TransactionResponse{
-		TransactionID: transferResp["id"].(string),
+	// Transfers don't report the fee; it is the executed quote's
+	tx := &TransactionResponse{
+		TransactionID: string(transfer.ID),
 		Status:        StatusPending,
 		Amount:        req.Amount,
-		Fee:           10.0, // Would be from quote
-		ExchangeRate:  1.2,  // Would be from quote
+		Fee:           quote.Fee,
+		ExchangeRate:  transfer.Rate,
 		EstimatedTime: "1-2 business days",
-		TrackingURL:   fmt.Sprintf("https://wise.com/track/%s", transferResp["id"]),
-	}, nil
+		TrackingURL:   fmt.Sprintf("https://wise.com/track/%s", transfer.ID),
+	}
+	if tx.ExchangeRate == 0 {
+		tx.ExchangeRate = quote.ExchangeRate
+	}
+	return tx, nil
 }
 
 func (w *WiseProvider) GetTransactionStatus(ctx context.Context, transactionID string) (*TransactionResponse, error) {
@@ -242,63 +633,245 @@ func (w *WiseProvider) GetTransactionStatus(ctx context.Context, transactionID s
 	}
 	defer resp.Body.Close()
 	
-	var statusResp map[string]interface{}
-	if err := json.NewDecoder(resp.Body).Decode(&statusResp); err != nil {
-		return nil, err
+	var transfer WiseTransferResponse
+	if err := json.NewDecoder(resp.Body).Decode(&transfer); err != nil {
+		return nil, fmt.Errorf("wise status: %w", err)
 	}
-	
-	status := StatusPending
-	if statusResp["status"].(string) == "outgoing_payment_sent" {
-		status = StatusCompleted
+	if transfer.Status == "" {
+		return nil, errors.New("wise status: missing status")
 	}
 	
 	return &TransactionResponse{
 		TransactionID: transactionID,
-		Status:        status,
+		Status:        mapWiseStatus(transfer.Status),
+		Amount:        transfer.SourceValue,
+		ExchangeRate:  transfer.Rate,
 		TrackingURL:   fmt.Sprintf("https://wise.com/track/%s", transactionID),
+		Progress:      trackingProgress(wiseTrackingSteps, transfer.Status),
 	}, nil
 }
 
-func (w *WiseProvider) GetExchangeRates(ctx context.Context, from, to Currency) (*ExchangeRate, error) {
-	endpoint := fmt.Sprintf("/v1/rates?source=%s&target=%s", from, to)
+func mapWiseStatus(status string) TransactionStatus {
+	switch status {
+	case "outgoing_payment_sent":
+		return StatusCompleted
+	case "bounced_back":
+		return StatusRefundPending
+	case "funds_refunded":
+		return StatusRefunded
+	}
+	return StatusPending
+}
+
+// FindTransactionByIdempotencyKey finds the transfer created with the given
+// customerTransactionId
+func (w *WiseProvider) FindTransactionByIdempotencyKey(ctx context.Context, key string) (*TransactionResponse, error) {
+	if err := checkIdempotencyKey(key); err != nil {
+		return nil, err
+	}
+	endpoint := fmt.Sprintf("/v1/transfers?profile=%s&customerTransactionId=%s", url.QueryEscape(w.ProfileID), url.QueryEscape(key))
 	resp, err := w.makeRequest(ctx, "GET", endpoint, nil)
 	if err != nil {
 		return nil, err
 	}
 	defer resp.Body.Close()
 	
-	var rates []map[string]interface{}
-	if err := json.NewDecoder(resp.Body).Decode(&rates); err != nil {
+	var transfers []WiseTransferResponse
+	if err := json.NewDecoder(resp.Body).Decode(&transfers); err != nil {
+		return nil, fmt.Errorf("wise transfer lookup: %w", err)
+	}
+	// Only a transfer created with this key will do, whatever else the
+	// filter lets through
+	for _, transfer := range transfers {
+		if transfer.CustomerTransactionID != "" && transfer.CustomerTransactionID != key {
+			continue
+		}
+		if err := transfer.validate(); err != nil {
+			return nil, fmt.Errorf("wise transfer lookup: %w", err)
+		}
+		return w.GetTransactionStatus(ctx, string(transfer.ID))
+	}
+	return nil, ErrTransactionNotFound
+}
+
+// GetTransactionHistory lists the profile's transfers, newest first. Wise
+// paginates by offset, which the cursor carries.
+func (w *WiseProvider) GetTransactionHistory(ctx context.Context, opts HistoryOptions) (*HistoryPage, error) {
+	offset, err := decodeOffsetCursor(opts.Cursor)
+	if err != nil {
+		return nil, err
+	}
+	query := url.Values{
+		"profile": {w.ProfileID},
+		"offset":  {strconv.Itoa(offset)},
+		"limit":   {strconv.Itoa(opts.limit())},
+	}
+	if !opts.Since.IsZero() {
+		query.Set("createdDateStart", opts.Since.UTC().Format(time.RFC3339))
+	}
+	if !opts.Until.IsZero() {
+		query.Set("createdDateEnd", opts.Until.UTC().Format(time.RFC3339))
+	}
+	
+	resp, err := w.makeRequest(ctx, "GET", "/v1/transfers?"+query.Encode(), nil)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	
+	var transfers []WiseTransferResponse
+	if err := json.NewDecoder(resp.Body).Decode(&transfers); err != nil {
 		return nil, err
 	}
 	
-	if len(rates) == 0 {
-		return nil, errors.New("no exchange rate found")
+	page := &HistoryPage{NextCursor: nextOffsetCursor(offset, len(transfers), opts.limit())}
+	for _, t := range transfers {
+		if t.validate() != nil {
+			continue
+		}
+		id := string(t.ID)
+		page.Transactions = append(page.Transactions, &TransactionResponse{
+			TransactionID: id,
+			Status:        mapWiseStatus(t.Status),
+			Amount:        t.SourceValue,
+			ExchangeRate:  t.Rate,
+			TrackingURL:   fmt.Sprintf("https://wise.com/track/%s", id),
+		})
+	}
+	return page, nil
+}
+
+// RefundTransaction: Wise refunds bounced transfers to the source account
+// on its own, so this only reports where that refund has got to.
+func (w *WiseProvider) RefundTransaction(ctx context.Context, transactionID, reason string) (*TransactionResponse, error) {
+	return w.GetTransactionStatus(ctx, transactionID)
+}
+
+func (w *WiseProvider) GetExchangeRates(ctx context.Context, from, to Currency) (*ExchangeRate, error) {
+	rate, err := w.fetchExchangeRate(ctx, from, to)
+	if errors.Is(err, errNoExchangeRate) && w.DeriveInverseRates {
+		if reverse, revErr := w.fetchExchangeRate(ctx, to, from); revErr == nil {
+			return InverseRate(reverse), nil
+		}
+	}
+	return rate, err
+}
+
+func (w *WiseProvider) fetchExchangeRate(ctx context.Context, from, to Currency) (*ExchangeRate, error) {
+	endpoint := fmt.Sprintf("/v1/rates?source=%s&target=%s", from, to)
+	resp, err := w.makeRequest(ctx, "GET", endpoint, nil)
+	if err != nil {
+		return nil, err
 	}
+	defer resp.Body.Close()
 	
-	rate := rates[0]["rate"].(float64)
+	rate, err := decodeWiseRate(resp.Body, from, to)
+	if err != nil {
+		return nil, fmt.Errorf("wise rate %s: %w", CurrencyPair{From: from, To: to}, err)
+	}
 	
 	return &ExchangeRate{
 		From:       from,
 		To:         to,
 		Rate:       rate,
 		Fee:        5.0, // Example fee
-		ValidUntil: time.Now().Add(1 * time.Hour),
+		ValidUntil: time.Now().Add(wiseRateValidity),
 	}, nil
 }
 
+// GetExchangeRatesBatch uses a single unfiltered /v1/rates call, which returns
+// every pair Wise quotes, and picks out the requested ones.
+func (w *WiseProvider) GetExchangeRatesBatch(ctx context.Context, pairs []CurrencyPair) (map[CurrencyPair]*ExchangeRate, error) {
+	resp, err := w.makeRequest(ctx, "GET", "/v1/rates", nil)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	
+	var rates []WiseRate
+	if err := json.NewDecoder(resp.Body).Decode(&rates); err != nil {
+		return nil, fmt.Errorf("wise rates: %w", err)
+	}
+	
+	available := make(map[CurrencyPair]float64, len(rates))
+	for _, r := range rates {
+		from, to := Currency(r.Source), Currency(r.Target)
+		if !from.IsRegistered() || !to.IsRegistered() {
+			continue
+		}
+		rate, err := r.value()
+		if err != nil {
+			continue
+		}
+		available[CurrencyPair{From: from, To: to}] = rate
+	}
+	
+	result := make(map[CurrencyPair]*ExchangeRate, len(pairs))
+	failed := make(map[CurrencyPair]error)
+	validUntil := time.Now().Add(wiseRateValidity)
+	for _, pair := range pairs {
+		rate, ok := available[pair]
+		derived := false
+		if !ok && w.DeriveInverseRates {
+			rate, derived = available[CurrencyPair{From: pair.To, To: pair.From}]
+			ok = derived
+		}
+		if !ok {
+			failed[pair] = errNoExchangeRate
+			continue
+		}
+		
+		quoted := &ExchangeRate{
+			From:       pair.From,
+			To:         pair.To,
+			Rate:       rate,
+			Fee:        5.0, // Example fee
+			ValidUntil: validUntil,
+		}
+		if derived {
+			quoted = InverseRate(&ExchangeRate{From: pair.To, To: pair.From, Rate: rate, Fee: 5.0, ValidUntil: validUntil})
+		}
+		result[pair] = quoted
+	}
+	
+	return result, rateBatchError(w.GetName(), len(pairs), failed)
+}
+
+// Wise caps INR and PHP payouts well below its currency-account transfers
+var wiseCorridorLimits = map[Route]corridorLimit{
+	{From: USD, To: EUR}: {min: 1, max: 1000000},
+	{From: USD, To: GBP}: {min: 1, max: 1000000},
+	{From: USD, To: INR}: {min: 1, max: 1000000},
+	{From: USD, To: PHP}: {min: 1, max: 50000},
+	{From: EUR, To: USD}: {min: 1, max: 1000000},
+	{From: EUR, To: GBP}: {min: 1, max: 1000000},
+	{From: EUR, To: INR}: {min: 1, max: 900000},
+	{From: EUR, To: PHP}: {min: 1, max: 45000},
+	{From: GBP, To: USD}: {min: 1, max: 1000000},
+	{From: GBP, To: EUR}: {min: 1, max: 1000000},
+	{From: GBP, To: INR}: {min: 1, max: 800000},
+	{From: GBP, To: PHP}: {min: 1, max: 40000},
+}
+
+func (w *WiseProvider) GetCorridorLimits(ctx context.Context, from, to Currency, country string) (min, max float64, err error) {
+	return lookupCorridorLimit(w.GetName(), wiseCorridorLimits, from, to, country)
+}
+
 // Remitly Provider
 type RemitlyProvider struct {
-	APIKey  string
-	BaseURL string
-	client  *http.Client
+	APIKey     string
+	BaseURL    string
+	// Identity is sent with every request (User-Agent, client ID headers)
+	Identity   ClientIdentity
+	client     *http.Client
+	middleware []Middleware
 }
 
 func NewRemitlyProvider(apiKey string) *RemitlyProvider {
 	return &RemitlyProvider{
 		APIKey:  apiKey,
 		BaseURL: "https://api.remitly.com",
-		client:  &http.Client{Timeout: 30 * time.Second},
+		client:  newProviderClient(30 * time.Second),
 	}
 }
 
@@ -310,8 +883,40 @@ func (r *RemitlyProvider) GetSupportedCurrencies() []Currency {
 	return []Currency{USD, EUR, PHP, INR, MXN}
 }
 
-func (r *RemitlyProvider) GetSupportedCountries() []string {
-	return []string{"US", "PH", "IN", "MX", "GB"}
+var remitlySendingCountries = []string{"US", "GB"}
+
+func (r *RemitlyProvider) GetSendingCountries() []string {
+	return remitlySendingCountries
+}
+
+func (r *RemitlyProvider) GetReceivingCountries() []string {
+	return []string{"PH", "IN", "MX"}
+}
+
+var remitlyRoutes = routeTable(
+	[]Currency{USD, EUR},
+	map[string][]Currency{
+		"PH": {PHP},
+		"IN": {INR},
+		"MX": {MXN, USD},
+	},
+)
+
+func (r *RemitlyProvider) GetSupportedRoutes() []Route {
+	return remitlyRoutes
+}
+
+func (r *RemitlyProvider) SupportsCorridor(from, to Currency, country string) bool {
+	return hasRoute(remitlyRoutes, from, to, country)
+}
+
+func (r *RemitlyProvider) GetCapabilities() Capabilities {
+	return Capabilities{FeeModels: allFeeModels, PromoCodes: true, PayoutNetworks: remitlyNetworks, FundingMethods: cardOrBank, Eligibility: adultsOnly}
+}
+
+// Use appends middleware run around every HTTP call this provider makes
+func (r *RemitlyProvider) Use(middleware ...Middleware) {
+	r.middleware = append(r.middleware, middleware...)
 }
 
 func (r *RemitlyProvider) makeRequest(ctx context.Context, method, endpoint string, body interface{}) (*http.Response, error) {
@@ -323,84 +928,294 @@ func (r *RemitlyProvider) makeRequest(ctx context.Context, method, endpoint stri
 		}
 		reqBody = bytes.NewBuffer(jsonBody)
 	}
-	
+	return r.send(ctx, method, endpoint, reqBody, "application/json")
+}
+
+// send makes a request with a body already encoded as contentType
+func (r *RemitlyProvider) send(ctx context.Context, method, endpoint string, reqBody io.Reader, contentType string) (*http.Response, error) {
+	ctx = withProviderName(ctx, r.GetName())
 	req, err := http.NewRequestWithContext(ctx, method, r.BaseURL+endpoint, reqBody)
 	if err != nil {
 		return nil, err
 	}
 	
+	r.Identity.apply(req)
 	req.Header.Set("Authorization", "Bearer "+r.APIKey)
-	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Content-Type", contentType)
+	setCorrelationID(req)
 	
-	return r.client.Do(req)
+	resp, err := chainMiddleware(r.client.Do, r.middleware)(req)
+	if err != nil {
+		return nil, redactError(err)
+	}
+	if err := checkResponse(r.GetName(), resp, r.APIKey); err != nil {
+		return nil, err
+	}
+	return resp, nil
 }
 
 func (r *RemitlyProvider) GetQuote(ctx context.Context, req TransactionRequest) (*RemittanceQuote, error) {
-	// Simulate Remitly quote API call
-	fee := req.Amount * 0.02 // 2% fee
-	rate := 1.15 // Example rate
-	receivedAmount := req.Amount * rate
+	quoteReq := map[string]interface{}{
+		"source_currency":      req.FromCurrency,
+		"destination_currency": req.ToCurrency,
+		"destination_country":  req.Recipient.Address.CountryCode,
+		"send_amount":          req.Amount,
+		"payment_method":       req.PaymentMethod,
+	}
+	if method := req.fundingMethod(); method != "" {
+		quoteReq["funding_method"] = method
+	}
+	if req.PromoCode != "" {
+		quoteReq["promo_code"] = req.PromoCode
+	}
+	if req.PayoutNetwork != "" {
+		quoteReq["payout_network"] = req.PayoutNetwork
+	}
 	
-	return &RemittanceQuote{
-		Provider:       r.GetName(),
-		Amount:         req.Amount,
-		Fee:            fee,
-		ExchangeRate:   rate,
-		TotalCost:      req.Amount + fee,
-		ReceivedAmount: receivedAmount,
-		EstimatedTime:  "Minutes to hours",
-		ValidUntil:     time.Now().Add(30 * time.Minute),
-	}, nil
+	resp, err := r.makeRequest(ctx, "POST", "/v1/quotes", quoteReq)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	
+	var quoteResp struct {
+		QuoteID          string     `json:"quote_id"`
+		ExchangeRate     float64    `json:"exchange_rate"`
+		Fee              float64    `json:"fee"`
+		FundingFee       float64    `json:"funding_fee"`
+		DeliveryEstimate string     `json:"delivery_estimate"`
+		ExpiresAt        time.Time  `json:"expires_at"`
+		Promotion        *promotion `json:"promotion"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&quoteResp); err != nil {
+		return nil, err
+	}
+	
+	quote := &RemittanceQuote{
+		Provider:      r.GetName(),
+		QuoteID:       quoteResp.QuoteID,
+		Amount:        req.Amount,
+		Fee:           quoteResp.Fee,
+		FundingFee:    quoteResp.FundingFee,
+		ExchangeRate:  quoteResp.ExchangeRate,
+		EstimatedTime: "Minutes to hours",
+		EstimatedMin:  time.Minute,
+		EstimatedMax:  24 * time.Hour,
+	}
+	quote.setExpiry(quoteResp.ExpiresAt, remitlyQuoteValidity)
+	if min, max, ok := ParseEstimatedTime(quoteResp.DeliveryEstimate); ok {
+		quote.EstimatedTime, quote.EstimatedMin, quote.EstimatedMax = quoteResp.DeliveryEstimate, min, max
+	}
+	applyPromotion(quote, req.PromoCode, quoteResp.Promotion)
+	applyFeeModel(quote, req.FeePaidBy)
+	roundCost(quote, req.FromCurrency)
+	return quote, nil
 }
 
 func (r *RemitlyProvider) SendMoney(ctx context.Context, req TransactionRequest) (*TransactionResponse, error) {
-	// Simulate Remitly transfer API call
-	transactionID := fmt.Sprintf("REM_%d", time.Now().Unix())
+	transferReq := map[string]interface{}{
+		"recipient_id":         req.Recipient.ID,
+		"source_currency":      req.FromCurrency,
+		"destination_currency": req.ToCurrency,
+		"send_amount":          req.Amount,
+		"payment_method":       req.PaymentMethod,
+		"reference":            req.Reference,
+		"purpose":              req.purpose(),
+		"idempotency_key":      req.idempotencyKey(),
+	}
+	if req.Quote != nil && req.Quote.QuoteID != "" {
+		transferReq["quote_id"] = req.Quote.QuoteID
+	}
+	if method := req.fundingMethod(); method != "" {
+		transferReq["funding_method"] = method
+	}
+	if req.PromoCode != "" {
+		transferReq["promo_code"] = req.PromoCode
+	}
+	if req.PayoutNetwork != "" {
+		transferReq["payout_network"] = req.PayoutNetwork
+	}
 	
-	return &TransactionResponse{
-		TransactionID: transactionID,
-		Status:        StatusPending,
+	resp, err := r.makeRequest(ctx, "POST", "/v1/transfers", transferReq)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	
+	var transferResp struct {
+		TransferID       string  `json:"transfer_id"`
+		Status           string  `json:"status"`
+		Fee              float64 `json:"fee"`
+		FundingFee       float64 `json:"funding_fee"`
+		ExchangeRate     float64 `json:"exchange_rate"`
+		DeliveryEstimate string  `json:"delivery_estimate"`
+		FailureReason    string  `json:"failure_reason"`
+		RequiredDocuments []string `json:"required_documents"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&transferResp); err != nil {
+		return nil, err
+	}
+	
+	status := mapRemitlyStatus(transferResp.Status)
+	tx := &TransactionResponse{
+		TransactionID: transferResp.TransferID,
+		Status:        status,
+		Error:         failureReason(status, transferResp.FailureReason),
 		Amount:        req.Amount,
-		Fee:           req.Amount * 0.02,
-		ExchangeRate:  1.15,
-		EstimatedTime: "Minutes to hours",
-		TrackingURL:   fmt.Sprintf("https://remitly.com/track/%s", transactionID),
-	}, nil
+		Fee:           transferResp.Fee,
+		FundingFee:    transferResp.FundingFee,
+		ExchangeRate:  transferResp.ExchangeRate,
+		EstimatedTime: transferResp.DeliveryEstimate,
+		TrackingURL:   fmt.Sprintf("https://remitly.com/track/%s", transferResp.TransferID),
+	}
+	tx.requireDocuments(documentTypes(transferResp.RequiredDocuments))
+	return tx, nil
+}
+
+// UploadComplianceDocument attaches doc to a transfer Remitly is holding
+// for documents (status on_hold, with required_documents listed)
+func (r *RemitlyProvider) UploadComplianceDocument(ctx context.Context, transactionID string, doc Document) error {
+	body, contentType, err := documentForm(doc)
+	if err != nil {
+		return err
+	}
+	resp, err := r.send(ctx, "POST", "/v1/transfers/"+url.PathEscape(transactionID)+"/documents", body, contentType)
+	if err != nil {
+		if isNotFound(err) {
+			return fmt.Errorf("remitly transfer %s: %w", transactionID, ErrTransactionNotFound)
+		}
+		return err
+	}
+	resp.Body.Close()
+	return nil
 }
 
 func (r *RemitlyProvider) GetTransactionStatus(ctx context.Context, transactionID string) (*TransactionResponse, error) {
-	// Simulate status check
+	resp, err := r.makeRequest(ctx, "GET", "/v1/transfers/"+url.PathEscape(transactionID), nil)
+	if err != nil {
+		if isNotFound(err) {
+			return nil, fmt.Errorf("remitly transfer %s: %w", transactionID, ErrTransactionNotFound)
+		}
+		return nil, err
+	}
+	defer resp.Body.Close()
+	
+	var statusResp struct {
+		Status        string `json:"status"`
+		FailureReason string `json:"failure_reason"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&statusResp); err != nil {
+		return nil, err
+	}
+	
+	status := mapRemitlyStatus(statusResp.Status)
 	return &TransactionResponse{
 		TransactionID: transactionID,
-		Status:        StatusCompleted,
+		Status:        status,
 		TrackingURL:   fmt.Sprintf("https://remitly.com/track/%s", transactionID),
+		Error:         failureReason(status, statusResp.FailureReason),
+		Progress:      trackingProgress(remitlyTrackingSteps, statusResp.Status),
 	}, nil
 }
 
-func (r *RemitlyProvider) GetExchangeRates(ctx context.Context, from, to Currency) (*ExchangeRate, error) {
-	return &ExchangeRate{
-		From:       from,
-		To:         to,
-		Rate:       1.15, // Example rate
-		Fee:        3.0,
-		ValidUntil: time.Now().Add(30 * time.Minute),
-	}, nil
+func mapRemitlyStatus(status string) TransactionStatus {
+	switch strings.ToLower(status) {
+	case "delivered", "completed":
+		return StatusCompleted
+	case "failed", "rejected":
+		return StatusFailed
+	case "cancelled", "canceled":
+		return StatusCancelled
+	case "refund_pending":
+		return StatusRefundPending
+	case "refunded":
+		return StatusRefunded
+	}
+	// created, processing, in_transit, on_hold
+	return StatusPending
 }
 
-// WorldRemit Provider
-type WorldRemitProvider struct {
-	APIKey    string
-	APISecret string
-	BaseURL   string
-	client    *http.Client
+// RefundTransaction: Remitly refunds failed transfers automatically, so
+// this returns the current status.
+func (r *RemitlyProvider) RefundTransaction(ctx context.Context, transactionID, reason string) (*TransactionResponse, error) {
+	return r.GetTransactionStatus(ctx, transactionID)
 }
 
-func NewWorldRemitProvider(apiKey, apiSecret string) *WorldRemitProvider {
-	return &WorldRemitProvider{
-		APIKey:    apiKey,
-		APISecret: apiSecret,
+func (r *RemitlyProvider) GetExchangeRates(ctx context.Context, from, to Currency) (*ExchangeRate, error) {
+	query := url.Values{
+		"source_currency":      {string(from)},
+		"destination_currency": {string(to)},
+	}
+	resp, err := r.makeRequest(ctx, "GET", "/v1/rates?"+query.Encode(), nil)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	
+	var rateResp struct {
+		Rate      float64   `json:"rate"`
+		Fee       float64   `json:"fee"`
+		ExpiresAt time.Time `json:"expires_at"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&rateResp); err != nil {
+		return nil, err
+	}
+	
+	rate := &ExchangeRate{
+		From:       from,
+		To:         to,
+		Rate:       rateResp.Rate,
+		Fee:        rateResp.Fee,
+		ValidUntil: expiresAt(rateResp.ExpiresAt, remitlyQuoteValidity),
+	}
+	return rate, nil
+}
+
+func (r *RemitlyProvider) GetExchangeRatesBatch(ctx context.Context, pairs []CurrencyPair) (map[CurrencyPair]*ExchangeRate, error) {
+	return fetchRatesConcurrently(ctx, r, pairs)
+}
+
+// remitlyFeeRate is Remitly's published percentage fee
+const remitlyFeeRate = 0.02
+
+// EstimateFee applies Remitly's published fee formula locally. It is an
+// estimate for display, not a binding quote.
+func (r *RemitlyProvider) EstimateFee(from, to Currency, amount float64, method PaymentMethod) (float64, error) {
+	return amount * remitlyFeeRate, nil
+}
+
+// Remitly's Mexico payouts carry a lower cap than its Asian corridors
+var remitlyCorridorLimits = map[Route]corridorLimit{
+	{From: USD, To: INR}: {min: 10, max: 10000},
+	{From: USD, To: PHP}: {min: 10, max: 10000},
+	{From: USD, To: MXN}: {min: 10, max: 6000},
+	{From: EUR, To: INR}: {min: 10, max: 9000},
+	{From: EUR, To: PHP}: {min: 10, max: 9000},
+	{From: EUR, To: MXN}: {min: 10, max: 5000},
+	{From: EUR, To: USD}: {min: 10, max: 5000},
+}
+
+func (r *RemitlyProvider) GetCorridorLimits(ctx context.Context, from, to Currency, country string) (min, max float64, err error) {
+	return lookupCorridorLimit(r.GetName(), remitlyCorridorLimits, from, to, country)
+}
+
+// WorldRemit Provider
+type WorldRemitProvider struct {
+	APIKey     string
+	APISecret  string
+	BaseURL    string
+	// Identity is sent with every request (User-Agent, client ID headers)
+	Identity   ClientIdentity
+	client     *http.Client
+	middleware []Middleware
+}
+
+func NewWorldRemitProvider(apiKey, apiSecret string) *WorldRemitProvider {
+	return &WorldRemitProvider{
+		APIKey:    apiKey,
+		APISecret: apiSecret,
 		BaseURL:   "https://api.worldremit.com",
-		client:    &http.Client{Timeout: 30 * time.Second},
+		client:    newProviderClient(30 * time.Second),
 	}
 }
 
@@ -412,18 +1227,49 @@ func (wr *WorldRemitProvider) GetSupportedCurrencies() []Currency {
 	return []Currency{USD, EUR, GBP, INR, PHP}
 }
 
-func (wr *WorldRemitProvider) GetSupportedCountries() []string {
-	return []string{"US", "GB", "IN", "PH", "KE", "GH"}
+var worldRemitSendingCountries = []string{"US", "GB"}
+
+func (wr *WorldRemitProvider) GetSendingCountries() []string {
+	return worldRemitSendingCountries
+}
+
+func (wr *WorldRemitProvider) GetReceivingCountries() []string {
+	return []string{"IN", "PH", "KE", "GH"}
+}
+
+// KE and GH pay out in KES and GHS, which Currency doesn't model yet
+var worldRemitRoutes = routeTable(
+	[]Currency{USD, EUR, GBP},
+	map[string][]Currency{
+		"IN": {INR},
+		"PH": {PHP},
+	},
+)
+
+func (wr *WorldRemitProvider) GetSupportedRoutes() []Route {
+	return worldRemitRoutes
+}
+
+func (wr *WorldRemitProvider) SupportsCorridor(from, to Currency, country string) bool {
+	return hasRoute(worldRemitRoutes, from, to, country)
+}
+
+func (wr *WorldRemitProvider) GetCapabilities() Capabilities {
+	return Capabilities{FeeModels: allFeeModels, PromoCodes: true, PayoutNetworks: worldRemitNetworks, FundingMethods: cardOrBank, Eligibility: adultsOnly}
+}
+
+// signer is built per request so a rotated APISecret takes effect at once
+func (wr *WorldRemitProvider) signer() RequestSigner {
+	return &HMACSigner{Secret: wr.APISecret, Layout: NewlineLayout}
 }
 
-func (wr *WorldRemitProvider) generateSignature(method, endpoint, timestamp, body string) string {
-	message := method + "\n" + endpoint + "\n" + timestamp + "\n" + body
-	h := hmac.New(sha256.New, []byte(wr.APISecret))
-	h.Write([]byte(message))
-	return hex.EncodeToString(h.Sum(nil))
+// Use appends middleware run around every HTTP call this provider makes
+func (wr *WorldRemitProvider) Use(middleware ...Middleware) {
+	wr.middleware = append(wr.middleware, middleware...)
 }
 
 func (wr *WorldRemitProvider) makeRequest(ctx context.Context, method, endpoint string, body interface{}) (*http.Response, error) {
+	ctx = withProviderName(ctx, wr.GetName())
 	var reqBody string
 	if body != nil {
 		jsonBody, err := json.Marshal(body)
@@ -433,157 +1279,1048 @@ func (wr *WorldRemitProvider) makeRequest(ctx context.Context, method, endpoint
 		reqBody = string(jsonBody)
 	}
 	
-	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
-	signature := wr.generateSignature(method, endpoint, timestamp, reqBody)
-	
 	req, err := http.NewRequestWithContext(ctx, method, wr.BaseURL+endpoint, strings.NewReader(reqBody))
 	if err != nil {
 		return nil, err
 	}
 	
+	wr.Identity.apply(req)
 	req.Header.Set("X-API-Key", wr.APIKey)
-	req.Header.Set("X-Timestamp", timestamp)
-	req.Header.Set("X-Signature", signature)
 	req.Header.Set("Content-Type", "application/json")
+	if err := wr.signer().Sign(req, []byte(reqBody)); err != nil {
+		return nil, err
+	}
+	setCorrelationID(req)
 	
-	return wr.client.Do(req)
+	resp, err := chainMiddleware(wr.client.Do, wr.middleware)(req)
+	if err != nil {
+		return nil, redactError(err)
+	}
+	if err := checkResponse(wr.GetName(), resp, wr.APIKey, wr.APISecret); err != nil {
+		return nil, err
+	}
+	return resp, nil
 }
 
 func (wr *WorldRemitProvider) GetQuote(ctx context.Context, req TransactionRequest) (*RemittanceQuote, error) {
-	// Simulate WorldRemit quote
-	fee := 5.99 // Fixed fee
-	rate := 1.18
-	receivedAmount := req.Amount * rate
+	payout, err := wr.payoutMethod(req)
+	if err != nil {
+		return nil, err
+	}
+	quoteReq := map[string]interface{}{
+		"sendCurrency":    req.FromCurrency,
+		"receiveCurrency": req.ToCurrency,
+		"receiveCountry":  req.Recipient.Address.CountryCode,
+		"sendAmount":      req.Amount,
+		"payoutMethod":    payout,
+	}
+	if method := req.fundingMethod(); method != "" {
+		quoteReq["paymentMethod"] = method
+	}
+	if req.PromoCode != "" {
+		quoteReq["promoCode"] = req.PromoCode
+	}
+	if req.PayoutNetwork != "" {
+		quoteReq["payoutNetwork"] = req.PayoutNetwork
+	}
 	
-	return &RemittanceQuote{
-		Provider:       wr.GetName(),
-		Amount:         req.Amount,
-		Fee:            fee,
-		ExchangeRate:   rate,
-		TotalCost:      req.Amount + fee,
-		ReceivedAmount: receivedAmount,
-		EstimatedTime:  "Minutes",
-		ValidUntil:     time.Now().Add(15 * time.Minute),
-	}, nil
+	resp, err := wr.makeRequest(ctx, "POST", "/v1/quotes", quoteReq)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	
+	var quoteResp struct {
+		QuoteID      string     `json:"quoteId"`
+		ExchangeRate float64    `json:"exchangeRate"`
+		Fee          float64    `json:"fee"`
+		FundingFee   float64    `json:"paymentMethodFee"`
+		DeliveryTime string     `json:"deliveryTime"`
+		ExpiresAt    time.Time  `json:"expiresAt"`
+		Promotion    *promotion `json:"promotion"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&quoteResp); err != nil {
+		return nil, err
+	}
+	
+	quote := &RemittanceQuote{
+		Provider:      wr.GetName(),
+		QuoteID:       quoteResp.QuoteID,
+		Amount:        req.Amount,
+		Fee:           quoteResp.Fee,
+		FundingFee:    quoteResp.FundingFee,
+		ExchangeRate:  quoteResp.ExchangeRate,
+		EstimatedTime: "Minutes",
+		EstimatedMin:  time.Minute,
+		EstimatedMax:  time.Hour,
+	}
+	quote.setExpiry(quoteResp.ExpiresAt, worldRemitQuoteValidity)
+	if min, max, ok := ParseEstimatedTime(quoteResp.DeliveryTime); ok {
+		quote.EstimatedTime, quote.EstimatedMin, quote.EstimatedMax = quoteResp.DeliveryTime, min, max
+	}
+	applyPromotion(quote, req.PromoCode, quoteResp.Promotion)
+	applyFeeModel(quote, req.FeePaidBy)
+	roundCost(quote, req.FromCurrency)
+	return quote, nil
 }
 
 func (wr *WorldRemitProvider) SendMoney(ctx context.Context, req TransactionRequest) (*TransactionResponse, error) {
-	transactionID := fmt.Sprintf("WR_%d", time.Now().Unix())
+	payout, err := wr.payoutMethod(req)
+	if err != nil {
+		return nil, err
+	}
+	transactionReq := map[string]interface{}{
+		"recipientId":     req.Recipient.ID,
+		"sendCurrency":    req.FromCurrency,
+		"receiveCurrency": req.ToCurrency,
+		"sendAmount":      req.Amount,
+		"payoutMethod":    payout,
+		"reference":       req.Reference,
+		"purpose":         req.purpose(),
+		"idempotencyKey":  req.idempotencyKey(),
+	}
+	if method := req.fundingMethod(); method != "" {
+		transactionReq["paymentMethod"] = method
+	}
+	if req.Quote != nil && req.Quote.QuoteID != "" {
+		transactionReq["quoteId"] = req.Quote.QuoteID
+	}
+	if req.PromoCode != "" {
+		transactionReq["promoCode"] = req.PromoCode
+	}
+	if req.PayoutNetwork != "" {
+		transactionReq["payoutNetwork"] = req.PayoutNetwork
+	}
 	
+	resp, err := wr.makeRequest(ctx, "POST", "/v1/transactions", transactionReq)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	
+	var transactionResp struct {
+		ID           string  `json:"id"`
+		Status       string  `json:"status"`
+		Fee          float64 `json:"fee"`
+		FundingFee   float64 `json:"paymentMethodFee"`
+		ExchangeRate float64 `json:"exchangeRate"`
+		DeliveryTime string  `json:"deliveryTime"`
+		StatusReason string  `json:"statusReason"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&transactionResp); err != nil {
+		return nil, err
+	}
+	
+	status := mapWorldRemitStatus(transactionResp.Status)
 	return &TransactionResponse{
-		TransactionID: transactionID,
-		Status:        StatusPending,
+		TransactionID: transactionResp.ID,
+		Status:        status,
+		Error:         failureReason(status, transactionResp.StatusReason),
 		Amount:        req.Amount,
-		Fee:           5.99,
-		ExchangeRate:  1.18,
-		EstimatedTime: "Minutes",
-		TrackingURL:   fmt.Sprintf("https://worldremit.com/track/%s", transactionID),
+		Fee:           transactionResp.Fee,
+		FundingFee:    transactionResp.FundingFee,
+		ExchangeRate:  transactionResp.ExchangeRate,
+		EstimatedTime: transactionResp.DeliveryTime,
+		TrackingURL:   fmt.Sprintf("https://worldremit.com/track/%s", transactionResp.ID),
 	}, nil
 }
 
 func (wr *WorldRemitProvider) GetTransactionStatus(ctx context.Context, transactionID string) (*TransactionResponse, error) {
+	resp, err := wr.makeRequest(ctx, "GET", "/v1/transactions/"+url.PathEscape(transactionID), nil)
+	if err != nil {
+		if isNotFound(err) {
+			return nil, fmt.Errorf("worldremit transaction %s: %w", transactionID, ErrTransactionNotFound)
+		}
+		return nil, err
+	}
+	defer resp.Body.Close()
+	
+	var statusResp struct {
+		Status       string `json:"status"`
+		StatusReason string `json:"statusReason"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&statusResp); err != nil {
+		return nil, err
+	}
+	
+	status := mapWorldRemitStatus(statusResp.Status)
 	return &TransactionResponse{
 		TransactionID: transactionID,
-		Status:        StatusCompleted,
+		Status:        status,
 		TrackingURL:   fmt.Sprintf("https://worldremit.com/track/%s", transactionID),
+		Error:         failureReason(status, statusResp.StatusReason),
+		Progress:      trackingProgress(worldRemitTrackingSteps, statusResp.Status),
 	}, nil
 }
 
+// worldRemitPayoutMethods maps payout methods to WorldRemit's payoutMethod
+var worldRemitPayoutMethods = map[PaymentMethod]string{
+	PaymentBankTransfer: "BANK_DEPOSIT",
+	PaymentCash:         "CASH_PICKUP",
+	PaymentWallet:       "MOBILE_MONEY",
+}
+
+// payoutMethod is WorldRemit's payoutMethod for req, a bank deposit when
+// the request names none. WorldRemit can't pay out to a card.
+func (wr *WorldRemitProvider) payoutMethod(req TransactionRequest) (string, error) {
+	if req.PaymentMethod == "" {
+		return worldRemitPayoutMethods[PaymentBankTransfer], nil
+	}
+	payout, ok := worldRemitPayoutMethods[req.PaymentMethod]
+	if !ok {
+		return "", &UnsupportedError{Provider: wr.GetName(), Operation: fmt.Sprintf("%s payout", req.PaymentMethod)}
+	}
+	return payout, nil
+}
+
+func mapWorldRemitStatus(status string) TransactionStatus {
+	switch strings.ToUpper(status) {
+	case "PAID", "COLLECTED", "COMPLETED":
+		return StatusCompleted
+	case "FAILED", "REJECTED":
+		return StatusFailed
+	case "CANCELLED":
+		return StatusCancelled
+	case "REFUNDING":
+		return StatusRefundPending
+	case "REFUNDED":
+		return StatusRefunded
+	}
+	// CREATED, FUNDED, PROCESSING, READY_FOR_COLLECTION
+	return StatusPending
+}
+
+// RefundTransaction: WorldRemit refunds failed transfers automatically, so
+// this returns the current status.
+func (wr *WorldRemitProvider) RefundTransaction(ctx context.Context, transactionID, reason string) (*TransactionResponse, error) {
+	return wr.GetTransactionStatus(ctx, transactionID)
+}
+
 func (wr *WorldRemitProvider) GetExchangeRates(ctx context.Context, from, to Currency) (*ExchangeRate, error) {
-	return &ExchangeRate{
+	query := url.Values{
+		"sendCurrency":    {string(from)},
+		"receiveCurrency": {string(to)},
+	}
+	resp, err := wr.makeRequest(ctx, "GET", "/v1/rates?"+query.Encode(), nil)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	
+	var rateResp struct {
+		Rate      float64   `json:"rate"`
+		Fee       float64   `json:"fee"`
+		ExpiresAt time.Time `json:"expiresAt"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&rateResp); err != nil {
+		return nil, err
+	}
+	
+	rate := &ExchangeRate{
 		From:       from,
 		To:         to,
-		Rate:       1.18,
-		Fee:        5.99,
-		ValidUntil: time.Now().Add(15 * time.Minute),
-	}, nil
+		Rate:       rateResp.Rate,
+		Fee:        rateResp.Fee,
+		ValidUntil: expiresAt(rateResp.ExpiresAt, worldRemitQuoteValidity),
+	}
+	return rate, nil
+}
+
+func (wr *WorldRemitProvider) GetExchangeRatesBatch(ctx context.Context, pairs []CurrencyPair) (map[CurrencyPair]*ExchangeRate, error) {
+	return fetchRatesConcurrently(ctx, wr, pairs)
+}
+
+// worldRemitFlatFee is WorldRemit's published fixed fee
+const worldRemitFlatFee = 5.99
+
+// EstimateFee applies WorldRemit's published flat fee locally. It is an
+// estimate for display, not a binding quote.
+func (wr *WorldRemitProvider) EstimateFee(from, to Currency, amount float64, method PaymentMethod) (float64, error) {
+	return worldRemitFlatFee, nil
+}
+
+var worldRemitCorridorLimits = map[Route]corridorLimit{
+	{From: USD, To: INR}: {min: 1, max: 5000},
+	{From: USD, To: PHP}: {min: 1, max: 3000},
+	{From: EUR, To: INR}: {min: 1, max: 5000},
+	{From: EUR, To: PHP}: {min: 1, max: 3000},
+	{From: GBP, To: INR}: {min: 1, max: 4000},
+	{From: GBP, To: PHP}: {min: 1, max: 2500},
+}
+
+func (wr *WorldRemitProvider) GetCorridorLimits(ctx context.Context, from, to Currency, country string) (min, max float64, err error) {
+	return lookupCorridorLimit(wr.GetName(), worldRemitCorridorLimits, from, to, country)
 }
 
 // Remittance Hub - Main orchestrator
+//
+// Provider registration is safe to call concurrently with quoting and
+// sending; in-flight operations keep using the provider set they started with.
 type RemittanceHub struct {
-	providers []RemittanceProvider
+	mu          sync.RWMutex // guards providers and middleware
+	providers   []RemittanceProvider
+	middleware  []Middleware
+	tracer      Tracer
+	metrics     Metrics
+	metricsOnce sync.Once // installs the upstream metrics middleware
+	fees        *feeCache
+	quotes      *quoteFlight
+	store       TransactionStore
+	reference   ReferenceRateProvider
+	quoteBudget time.Duration
+	weights     ProviderWeights
+	screener    Screener
+	policy      PolicyEngine
+	retryPolicy RetryPolicy
+	rateSource  string
+	holidays    HolidayCalendar
+	balancer    *LoadBalancer
+	walletPrecheck bool
+	rates       *rateCache
+	quoteTTL    QuoteTTL
+	done        chan struct{} // closed by Close
+	closeOnce   sync.Once
 }
 
 func NewRemittanceHub() *RemittanceHub {
 	return &RemittanceHub{
-		providers: make([]RemittanceProvider, 0),
+		providers:   make([]RemittanceProvider, 0),
+		tracer:      noopTracer{},
+		metrics:     noopMetrics{},
+		fees:        newFeeCache(feeCacheTTL),
+		quotes:      newQuoteFlight(),
+		quoteBudget: defaultQuoteBudget,
+		done:        make(chan struct{}),
 	}
 }
 
-func (rh *RemittanceHub) AddProvider(provider RemittanceProvider) {
-	rh.providers = append(rh.providers, provider)
+// SetMetrics enables metrics for hub operations and, through middleware,
+// for every provider HTTP call. Calling it again redirects both to the new
+// metrics rather than reporting twice. Call it before serving traffic.
+func (rh *RemittanceHub) SetMetrics(metrics Metrics) {
+	if metrics == nil {
+		return
+	}
+	rh.metrics = metrics
+	rh.metricsOnce.Do(func() {
+		rh.Use(metricsMiddleware(func() Metrics { return rh.metrics }))
+	})
 }
 
-func (rh *RemittanceHub) GetAvailableProviders(fromCountry, toCountry string, fromCurrency, toCurrency Currency) []RemittanceProvider {
-	var available []RemittanceProvider
+// SetTracer enables tracing of provider operations; nil restores the no-op
+// tracer. Call it before serving traffic.
+func (rh *RemittanceHub) SetTracer(tracer Tracer) {
+	if tracer == nil {
+		tracer = noopTracer{}
+	}
+	rh.tracer = tracer
+}
+
+// AddProvider registers a provider. Routing (SendMoneyWithProvider, quotes'
+// Provider field) is by GetName, so names must be unique; registering a
+// second provider with the same name is an error. Use ReplaceProvider to
+// swap an instance.
+func (rh *RemittanceHub) AddProvider(provider RemittanceProvider) error {
+	rh.mu.Lock()
+	defer rh.mu.Unlock()
 	
-	for _, provider := range rh.providers {
-		// Check if provider supports the currencies
-		supportsCurrencies := false
-		for _, currency := range provider.GetSupportedCurrencies() {
-			if currency == fromCurrency || currency == toCurrency {
-				supportsCurrencies = true
-				break
-			}
+	if rh.closed() {
+		return ErrClosed
+	}
+	for _, existing := range rh.providers {
+		if existing.GetName() == provider.GetName() {
+			return fmt.Errorf("provider %s already registered", provider.GetName())
 		}
-		
-		// Check if provider supports the countries
-		supportsCountries := false
-		for _, country := range provider.GetSupportedCountries() {
-			if country == fromCountry || country == toCountry {
-				supportsCountries = true
-				break
-			}
+	}
+	
+	if mu, ok := provider.(MiddlewareUser); ok && len(rh.middleware) > 0 {
+		mu.Use(rh.middleware...)
+	}
+	// Copy on write so snapshots handed to readers are never mutated
+	providers := make([]RemittanceProvider, len(rh.providers), len(rh.providers)+1)
+	copy(providers, rh.providers)
+	rh.providers = append(providers, provider)
+	return nil
+}
+
+// RemoveProvider unregisters the provider with the given name, reporting
+// whether it was registered.
+func (rh *RemittanceHub) RemoveProvider(name string) bool {
+	rh.mu.Lock()
+	defer rh.mu.Unlock()
+	
+	providers := make([]RemittanceProvider, 0, len(rh.providers))
+	for _, provider := range rh.providers {
+		if provider.GetName() != name {
+			providers = append(providers, provider)
 		}
-		
-		if supportsCurrencies && supportsCountries {
-			available = append(available, provider)
+	}
+	removed := len(providers) != len(rh.providers)
+	rh.providers = providers
+	return removed
+}
+
+// ReplaceProvider swaps in a new instance for the registered provider with
+// the same GetName, keeping its position (e.g. to rotate credentials). If
+// none is registered under that name, the provider is added.
+func (rh *RemittanceHub) ReplaceProvider(provider RemittanceProvider) {
+	rh.mu.Lock()
+	defer rh.mu.Unlock()
+	
+	if rh.closed() {
+		return
+	}
+	if mu, ok := provider.(MiddlewareUser); ok && len(rh.middleware) > 0 {
+		mu.Use(rh.middleware...)
+	}
+	providers := make([]RemittanceProvider, len(rh.providers), len(rh.providers)+1)
+	copy(providers, rh.providers)
+	for i, existing := range providers {
+		if existing.GetName() == provider.GetName() {
+			providers[i] = provider
+			rh.providers = providers
+			return
 		}
 	}
+	rh.providers = append(providers, provider)
+}
+
+// Use installs middleware on every registered provider that accepts it, and
+// on providers added afterwards.
+func (rh *RemittanceHub) Use(middleware ...Middleware) {
+	rh.mu.Lock()
+	defer rh.mu.Unlock()
 	
+	rh.middleware = append(rh.middleware, middleware...)
+	for _, provider := range rh.providers {
+		if mu, ok := provider.(MiddlewareUser); ok {
+			mu.Use(middleware...)
+		}
+	}
+}
+
+// snapshot returns the current providers. The slice is never modified after
+// being published, so callers may iterate it without holding the lock.
+func (rh *RemittanceHub) snapshot() []RemittanceProvider {
+	rh.mu.RLock()
+	defer rh.mu.RUnlock()
+	return rh.providers
+}
+
+// GetAvailableProviders returns the providers serving the route.
+// fromCountry is checked against each provider's sending countries and
+// toCountry against its receiving countries; either may be empty to skip
+// the check.
+func (rh *RemittanceHub) GetAvailableProviders(fromCountry, toCountry string, fromCurrency, toCurrency Currency) []RemittanceProvider {
+	available, _ := rh.GetEligibleProviders(fromCountry, toCountry, fromCurrency, toCurrency, "", nil)
 	return available
 }
 
-func (rh *RemittanceHub) GetQuotes(ctx context.Context, req TransactionRequest) ([]*RemittanceQuote, error) {
-	providers := rh.GetAvailableProviders("US", req.Recipient.Address.CountryCode, req.FromCurrency, req.ToCurrency)
-	quotes := make([]*RemittanceQuote, 0, len(providers))
+// GetEligibleProviders is GetAvailableProviders narrowed to the providers
+// that reach network (empty for any) and accept sender. When sender is
+// non-nil, providers the sender isn't eligible for are left out and
+// returned in excluded with the reason.
+func (rh *RemittanceHub) GetEligibleProviders(fromCountry, toCountry string, fromCurrency, toCurrency Currency, network PayoutNetwork, sender *SenderProfile) (available []RemittanceProvider, excluded []*IneligibleError) {
+	now := time.Now()
 	
-	for _, provider := range providers {
-		quote, err := provider.GetQuote(ctx, req)
-		if err != nil {
-			log.Printf("Error getting quote from %s: %v", provider.GetName(), err)
+	for _, provider := range rh.snapshot() {
+		if !provider.SupportsCorridor(fromCurrency, toCurrency, toCountry) || !reachesNetwork(provider, toCountry, network) {
+			continue
+		}
+		if !servesCountry(provider.GetSendingCountries(), fromCountry) || !servesCountry(provider.GetReceivingCountries(), toCountry) {
+			continue
+		}
+		if err := checkEligibility(provider, toCountry, sender, now); err != nil {
+			excluded = append(excluded, err)
 			continue
 		}
-		quotes = append(quotes, quote)
+		available = append(available, provider)
+	}
+	
+	return available, excluded
+}
+
+// GetQuotes quotes req with every eligible provider at once. If ctx is
+// cancelled before all have answered, it returns straight away with the
+// quotes gathered so far and ctx's error, so a non-nil error may come with
+// usable quotes: check len(quotes) rather than discarding them on error.
+func (rh *RemittanceHub) GetQuotes(ctx context.Context, req TransactionRequest) ([]*RemittanceQuote, error) {
+	quotes, _, err := rh.collectQuotes(ctx, req, QuoteOptions{})
+	if err != nil && len(quotes) == 0 {
+		return nil, err
+	}
+	
+	// Sort quotes by effective cost (best value first)
+	rh.sortQuotes(quotes, req, EffectiveCost)
+	
+	return quotes, err
+}
+
+// QuoteFilter narrows GetQuotesFiltered results. Zero-valued fields impose no
+// constraint, so an empty QuoteFilter returns the same quotes as GetQuotes.
+type QuoteFilter struct {
+	MaxFee float64
+	// MaxDeliveryTime is compared against the quote's worst-case delivery
+	// time; quotes with an unknown delivery time are excluded when set
+	MaxDeliveryTime   time.Duration
+	MinReceivedAmount float64
+	// AllowedProviders, if non-empty, keeps only the named providers
+	AllowedProviders  []string
+	ExcludedProviders []string
+}
+
+// Matches reports whether quote satisfies every constraint in the filter
+func (f QuoteFilter) Matches(quote *RemittanceQuote) bool {
+	if f.MaxFee > 0 && quote.Fee > f.MaxFee {
+		return false
+	}
+	if f.MinReceivedAmount > 0 && quote.ReceivedAmount < f.MinReceivedAmount {
+		return false
+	}
+	if f.MaxDeliveryTime > 0 {
+		if _, max, ok := quote.DeliveryWindow(); !ok || max > f.MaxDeliveryTime {
+			return false
+		}
+	}
+	if len(f.AllowedProviders) > 0 && !containsString(f.AllowedProviders, quote.Provider) {
+		return false
+	}
+	return !containsString(f.ExcludedProviders, quote.Provider)
+}
+
+func containsString(values []string, target string) bool {
+	for _, v := range values {
+		if v == target {
+			return true
+		}
+	}
+	return false
+}
+
+// GetQuotesFiltered is GetQuotes with filter applied to the fetched quotes
+// before sorting. An empty filter is equivalent to GetQuotes.
+func (rh *RemittanceHub) GetQuotesFiltered(ctx context.Context, req TransactionRequest, filter QuoteFilter) ([]*RemittanceQuote, error) {
+	quotes, _, err := rh.collectQuotes(ctx, req, QuoteOptions{})
+	if err != nil && len(quotes) == 0 {
+		return nil, err
+	}
+	
+	filtered := quotes[:0]
+	for _, quote := range quotes {
+		if filter.Matches(quote) {
+			filtered = append(filtered, quote)
+		}
+	}
+	
+	rh.sortQuotes(filtered, req, EffectiveCost)
+	return filtered, err
+}
+
+// collectQuotes fetches quotes from every eligible provider, unsorted,
+// within the limits in opts. Providers dropped for running out of time are
+// returned as timeouts. On cancellation it returns the quotes gathered so
+// far with ctx's error.
+func (rh *RemittanceHub) collectQuotes(ctx context.Context, req TransactionRequest, opts QuoteOptions) (quotes []*RemittanceQuote, timeouts []*QuoteTimeoutError, err error) {
+	ctx, span := rh.tracer.Start(ctx, spanGetQuotes, requestAttributes(req)...)
+	defer func() {
+		span.SetAttributes(Attr(attrQuoteCount, len(quotes)))
+		endSpan(span, err)
+	}()
+	
+	if err := req.Validate(); err != nil {
+		return nil, nil, err
+	}
+	
+	providers, excluded := rh.GetEligibleProviders(req.sendingCountry(), req.Recipient.Address.CountryCode, req.FromCurrency, req.ToCurrency, req.PayoutNetwork, req.Sender)
+	for _, e := range excluded {
+		log.Printf("Skipping %s: %s", e.Provider, e.Reason)
+	}
+	if len(providers) == 0 && len(excluded) > 0 {
+		return nil, nil, ineligibleSender(excluded)
+	}
+	if len(providers) == 0 {
+		if req.PayoutNetwork != "" {
+			return nil, nil, fmt.Errorf("%w: %s to %s via %s", ErrNoProvidersForCorridor, CurrencyPair{From: req.FromCurrency, To: req.ToCurrency}, req.Recipient.Address.CountryCode, req.PayoutNetwork)
+		}
+		return nil, nil, fmt.Errorf("%w: %s to %s", ErrNoProvidersForCorridor, CurrencyPair{From: req.FromCurrency, To: req.ToCurrency}, req.Recipient.Address.CountryCode)
+	}
+	// Quote all providers at once, each within its share of the budget.
+	// Every provider context derives from the caller's, so cancelling it
+	// aborts the calls still in flight.
+	callerCtx := ctx
+	batchCtx, cancelBatch := opts.batchContext(ctx)
+	defer cancelBatch()
+	ctx, cancel := rh.withQuoteBudget(batchCtx)
+	defer cancel()
+	
+	type quoteResult struct {
+		i       int
+		quote   *RemittanceQuote
+		timeout *QuoteTimeoutError
+	}
+	answers := make(chan quoteResult, len(providers))
+	for i, provider := range providers {
+		go func(i int, provider RemittanceProvider) {
+			providerCtx, cancel := opts.providerContext(ctx)
+			defer cancel()
+			
+			quote, err := rh.quoteEligible(providerCtx, provider, req)
+			var timeout *QuoteTimeoutError
+			if err != nil {
+				if errors.Is(providerCtx.Err(), context.DeadlineExceeded) {
+					timeout = &QuoteTimeoutError{Provider: provider.GetName(), Err: err}
+					err = timeout
+				}
+				if batchCtx.Err() == nil {
+					log.Printf("Error getting quote from %s: %v", provider.GetName(), err)
+				}
+			}
+			answers <- quoteResult{i, quote, timeout}
+		}(i, provider)
+	}
+	
+	// Stop waiting the moment the caller gives up, or the batch runs out of
+	// time, rather than for providers that are slow to notice; their late
+	// answers go to the buffered channel
+	results := make([]*RemittanceQuote, len(providers))
+	answered := make([]bool, len(providers))
+wait:
+	for range providers {
+		select {
+		case answer := <-answers:
+			results[answer.i], answered[answer.i] = answer.quote, true
+			if answer.timeout != nil {
+				timeouts = append(timeouts, answer.timeout)
+			}
+		case <-batchCtx.Done():
+			err = batchCtx.Err()
+			break wait
+		}
+	}
+	if err != nil && callerCtx.Err() == nil {
+		// Only OverallTimeout expired: the providers still out are dropped
+		// like any other that timed out, and the batch isn't a failure
+		for i, provider := range providers {
+			if !answered[i] {
+				timeouts = append(timeouts, &QuoteTimeoutError{Provider: provider.GetName(), Err: err})
+			}
+		}
+	}
+	
+	// Keep registration order; sorting happens in the callers
+	quotes = make([]*RemittanceQuote, 0, len(results))
+	for _, quote := range results {
+		if quote != nil {
+			quotes = append(quotes, quote)
+		}
+	}
+	
+	if err != nil {
+		// No time left to ask the reference source
+		annotateMargin(quotes, 0)
+		normalizeCosts(quotes, req.FromCurrency, 0)
+		annotateEffectiveCost(quotes, bestQuotedRate(quotes))
+		if callerCtx.Err() == nil {
+			err = nil
+		}
+		return quotes, timeouts, err
+	}
+	rh.annotateQuotes(ctx, quotes, req)
+	return quotes, timeouts, nil
+}
+
+// annotateQuotes fills in the hub's comparison fields on quotes for req:
+// NormalizedCost, the mid-market margin and EffectiveCost
+func (rh *RemittanceHub) annotateQuotes(ctx context.Context, quotes []*RemittanceQuote, req TransactionRequest) {
+	reference := bestQuotedRate(quotes)
+	normalizeCosts(quotes, req.FromCurrency, rh.fundingReferenceRate(ctx, req))
+	if midMarket := rh.annotateMidMarket(ctx, quotes, req); midMarket > 0 {
+		reference = midMarket
 	}
+	annotateEffectiveCost(quotes, reference)
+}
+
+// quoteEligible quotes provider unless the request is outside its corridor
+// limits or funding options, in which case it logs why and returns no quote
+func (rh *RemittanceHub) quoteEligible(ctx context.Context, provider RemittanceProvider, req TransactionRequest) (*RemittanceQuote, error) {
+	if !fundsFrom(provider, req) {
+		log.Printf("Skipping %s: cannot fund from a %s balance", provider.GetName(), req.FundingCurrency)
+		return nil, nil
+	}
+	if !paysWith(provider, req) {
+		log.Printf("Skipping %s: does not accept %s funding", provider.GetName(), req.FundingMethod)
+		return nil, nil
+	}
+	// Skip providers that would reject the amount upstream
+	min, max, err := provider.GetCorridorLimits(ctx, req.FromCurrency, req.ToCurrency, req.Recipient.Address.CountryCode)
+	if err != nil {
+		return nil, fmt.Errorf("corridor limits: %w", err)
+	}
+	if !(corridorLimit{min: min, max: max}).allows(req.Amount) {
+		log.Printf("Skipping %s: amount %.2f %s outside corridor limits (min %.2f, max %.2f)",
+			provider.GetName(), req.Amount, req.FromCurrency, min, max)
+		return nil, nil
+	}
+	return rh.quoteProvider(ctx, provider, req)
+}
+
+// quoteProvider calls GetQuote inside a span that nests under the caller's
+func (rh *RemittanceHub) quoteProvider(ctx context.Context, provider RemittanceProvider, req TransactionRequest) (quote *RemittanceQuote, err error) {
+	attrs := append(requestAttributes(req), Attr(attrProvider, provider.GetName()))
+	ctx, span := rh.tracer.Start(ctx, spanGetQuote, attrs...)
+	defer func() { endSpan(span, err) }()
 	
-	// Sort quotes by total cost (best value first)
-	sort.Slice(quotes, func(i, j int) bool {
-		return quotes[i].TotalCost < quotes[j].TotalCost
+	rh.metrics.QuoteRequested(provider.GetName())
+	// Identical concurrent requests share one upstream call
+	key := newQuoteFlightKey(provider.GetName(), req)
+	err = rh.retry(ctx, func(ctx context.Context) error {
+		quote, err = rh.quotes.do(ctx, key, func(ctx context.Context) (*RemittanceQuote, error) {
+			return provider.GetQuote(ctx, req)
+		})
+		return err
 	})
+	if err != nil {
+		rh.metrics.QuoteFailed(provider.GetName())
+		return nil, err
+	}
+	rh.metrics.QuoteSucceeded(provider.GetName())
+	rh.quoteTTL.apply(quote)
+	annotatePromo(provider, quote, req)
+	roundReceived(quote, req)
+	annotateArrival(quote, req.Recipient.Address.CountryCode, time.Now(), rh.holidays)
+	rh.fees.observe(quote, req)
+	return quote, nil
+}
+
+// sendWithProvider calls SendMoney inside a span recording the resulting status
+func (rh *RemittanceHub) sendWithProvider(ctx context.Context, provider RemittanceProvider, req TransactionRequest) (tx *TransactionResponse, err error) {
+	attrs := append(requestAttributes(req), Attr(attrProvider, provider.GetName()))
+	ctx, span := rh.tracer.Start(ctx, spanSendMoney, attrs...)
+	defer func() {
+		if tx != nil {
+			span.SetAttributes(Attr(attrTransactionID, tx.TransactionID), Attr(attrStatus, string(tx.Status)))
+		}
+		endSpan(span, err)
+	}()
 	
-	return quotes, nil
+	warning, err := rh.precheckWallet(ctx, provider, req)
+	if err != nil {
+		return nil, err
+	}
+	tx, err = provider.SendMoney(ctx, req)
+	if err != nil {
+		rh.metrics.TransferSent(provider.GetName(), StatusFailed)
+		return nil, err
+	}
+	tx.normalizeError()
+	tx.setTotalDebit(req, provider.GetCapabilities().FeeModels)
+	if warning != "" {
+		tx.Warnings = append(tx.Warnings, warning)
+	}
+	rh.metrics.TransferSent(provider.GetName(), tx.Status)
+	rh.recordSend(ctx, provider.GetName(), req, tx)
+	return tx, nil
 }
 
-func (rh *RemittanceHub) SendMoneyWithProvider(ctx context.Context, providerName string, req TransactionRequest) (*TransactionResponse, error) {
-	for _, provider := range rh.providers {
+// transactionStatus calls GetTransactionStatus inside a span recording the status
+func (rh *RemittanceHub) transactionStatus(ctx context.Context, provider RemittanceProvider, transactionID string) (tx *TransactionResponse, err error) {
+	ctx, span := rh.tracer.Start(ctx, spanGetTransactionStatus,
+		Attr(attrProvider, provider.GetName()), Attr(attrTransactionID, transactionID))
+	defer func() {
+		if tx != nil {
+			span.SetAttributes(Attr(attrStatus, string(tx.Status)))
+		}
+		endSpan(span, err)
+	}()
+	
+	err = rh.retry(ctx, func(ctx context.Context) error {
+		tx, err = provider.GetTransactionStatus(ctx, transactionID)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	tx.normalizeError()
+	tx.fillProgress()
+	rh.recordStatus(ctx, provider.GetName(), tx)
+	return tx, nil
+}
+
+func (rh *RemittanceHub) findProvider(providerName string) (RemittanceProvider, error) {
+	for _, provider := range rh.snapshot() {
 		if provider.GetName() == providerName {
-			return provider.SendMoney(ctx, req)
+			return provider, nil
+		}
+	}
+	return nil, fmt.Errorf("%w: %s", ErrProviderNotFound, providerName)
+}
+
+func (rh *RemittanceHub) SendMoneyWithProvider(ctx context.Context, providerName string, req TransactionRequest) (*TransactionResponse, error) {
+	provider, err := rh.findProvider(providerName)
+	if err != nil {
+		return nil, err
+	}
+	if err := rh.checkSend(ctx, provider, &req); err != nil {
+		return nil, err
+	}
+	return rh.sendWithProvider(ctx, provider, req)
+}
+
+// checkSend runs every local check a send must pass before the provider is
+// contacted. Validate may normalise req in place.
+func (rh *RemittanceHub) checkSend(ctx context.Context, provider RemittanceProvider, req *TransactionRequest) error {
+	if err := req.Validate(); err != nil {
+		return err
+	}
+	req.ensureIdempotencyKey()
+	// Compliance runs before anything reaches a provider
+	if err := rh.screen(ctx, req.Recipient); err != nil {
+		return err
+	}
+	if err := rh.checkPolicy(ctx, *req); err != nil {
+		return err
+	}
+	
+	if req.ScheduledFor != nil && !provider.GetCapabilities().ScheduledTransfers {
+		return &UnsupportedError{Provider: provider.GetName(), Operation: "scheduled transfers"}
+	}
+	if err := checkEligibility(provider, req.Recipient.Address.CountryCode, req.Sender, time.Now()); err != nil {
+		return err
+	}
+	if !fundsFrom(provider, *req) {
+		return &UnsupportedError{Provider: provider.GetName(), Operation: fmt.Sprintf("funding from a %s balance", req.FundingCurrency)}
+	}
+	if !paysWith(provider, *req) {
+		return &UnsupportedError{Provider: provider.GetName(), Operation: fmt.Sprintf("%s funding", req.FundingMethod)}
+	}
+	if !reachesNetwork(provider, req.Recipient.Address.CountryCode, req.PayoutNetwork) {
+		return &UnsupportedError{Provider: provider.GetName(), Operation: fmt.Sprintf("payout network %s in %s", req.PayoutNetwork, req.Recipient.Address.CountryCode)}
+	}
+	if err := checkPurposeCode(ctx, provider, req); err != nil {
+		return err
+	}
+	if req.LockedQuote != nil {
+		if err := checkLockedQuote(provider, req.LockedQuote); err != nil {
+			return err
+		}
+	}
+	// A valid rate lock outlives the quote's own expiry
+	if req.Quote != nil && req.LockedQuote == nil {
+		if err := checkQuote(provider, *req); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (rh *RemittanceHub) GetTransactionStatus(ctx context.Context, providerName, transactionID string) (*TransactionResponse, error) {
+	provider, err := rh.findProvider(providerName)
+	if err != nil {
+		return nil, err
+	}
+	return rh.transactionStatus(ctx, provider, transactionID)
+}
+
+// RefundTransaction asks the named provider to refund a transfer and
+// records the resulting status
+func (rh *RemittanceHub) RefundTransaction(ctx context.Context, providerName, transactionID, reason string) (*TransactionResponse, error) {
+	provider, err := rh.findProvider(providerName)
+	if err != nil {
+		return nil, err
+	}
+	tx, err := provider.RefundTransaction(ctx, transactionID, reason)
+	if err != nil {
+		return nil, err
+	}
+	tx.normalizeError()
+	tx.fillProgress()
+	rh.recordStatus(ctx, providerName, tx)
+	return tx, nil
+}
+
+func (rh *RemittanceHub) scheduler(providerName string) (TransferScheduler, error) {
+	provider, err := rh.findProvider(providerName)
+	if err != nil {
+		return nil, err
+	}
+	scheduler, ok := provider.(TransferScheduler)
+	if !ok || !provider.GetCapabilities().ScheduledTransfers {
+		return nil, &UnsupportedError{Provider: providerName, Operation: "scheduled transfers"}
+	}
+	return scheduler, nil
+}
+
+// GetScheduledTransfers lists a provider's transfers that have not executed yet
+func (rh *RemittanceHub) GetScheduledTransfers(ctx context.Context, providerName string) ([]*TransactionResponse, error) {
+	scheduler, err := rh.scheduler(providerName)
+	if err != nil {
+		return nil, err
+	}
+	return scheduler.GetScheduledTransfers(ctx)
+}
+
+// CancelScheduledTransfer cancels a scheduled transfer before it executes
+func (rh *RemittanceHub) CancelScheduledTransfer(ctx context.Context, providerName, transactionID string) error {
+	scheduler, err := rh.scheduler(providerName)
+	if err != nil {
+		return err
+	}
+	return scheduler.CancelScheduledTransfer(ctx, transactionID)
+}
+
+// PollOptions controls WaitForCompletion. Zero values fall back to defaults.
+type PollOptions struct {
+	Interval    time.Duration // first wait between polls (default 5s)
+	MaxInterval time.Duration // backoff ceiling (default 1m)
+	Multiplier  float64       // interval growth per poll (default 2)
+	// OnUpdate, if set, receives every status fetched, including the final one
+	OnUpdate func(*TransactionResponse)
+}
+
+func (p PollOptions) withDefaults() PollOptions {
+	if p.Interval <= 0 {
+		p.Interval = 5 * time.Second
+	}
+	if p.MaxInterval <= 0 {
+		p.MaxInterval = time.Minute
+	}
+	if p.MaxInterval < p.Interval {
+		p.MaxInterval = p.Interval
+	}
+	if p.Multiplier < 1 {
+		p.Multiplier = 2
+	}
+	return p
+}
+
+// WaitForCompletion polls a transaction with exponential backoff until its
+// status is terminal. If ctx ends first, the last status seen is returned
+// together with the context error.
+func (rh *RemittanceHub) WaitForCompletion(ctx context.Context, providerName, transactionID string, poll PollOptions) (*TransactionResponse, error) {
+	provider, err := rh.findProvider(providerName)
+	if err != nil {
+		return nil, err
+	}
+	
+	poll = poll.withDefaults()
+	interval := poll.Interval
+	for {
+		status, err := rh.transactionStatus(ctx, provider, transactionID)
+		if err != nil {
+			return nil, err
+		}
+		if poll.OnUpdate != nil {
+			poll.OnUpdate(status)
+		}
+		if status.Status.IsTerminal() {
+			return status, nil
+		}
+		
+		timer := time.NewTimer(interval)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return status, ctx.Err()
+		case <-timer.C:
+		}
+		
+		interval = time.Duration(float64(interval) * poll.Multiplier)
+		if interval > poll.MaxInterval {
+			interval = poll.MaxInterval
 		}
 	}
-	return nil, fmt.Errorf("provider %s not found", providerName)
 }
 
 func (rh *RemittanceHub) GetBestQuote(ctx context.Context, req TransactionRequest) (*RemittanceQuote, error) {
+	return rh.GetBestQuoteBy(ctx, req, EffectiveCost)
+}
+
+// SortStrategy decides which quote counts as "best"
+type SortStrategy int
+
+const (
+	// LowestCost prefers the smallest cost to the sender (NormalizedCost)
+	LowestCost SortStrategy = iota
+	// HighestReceived prefers the largest ReceivedAmount for the recipient
+	HighestReceived
+	// FastestDelivery prefers the shortest worst-case delivery time
+	FastestDelivery
+	// EffectiveCost prefers the smallest fee plus exchange-rate margin; it
+	// is the default because it can't be gamed by a zero fee and a poor rate
+	EffectiveCost
+)
+
+func (s SortStrategy) String() string {
+	switch s {
+	case LowestCost:
+		return "LowestCost"
+	case HighestReceived:
+		return "HighestReceived"
+	case FastestDelivery:
+		return "FastestDelivery"
+	case EffectiveCost:
+		return "EffectiveCost"
+	}
+	return fmt.Sprintf("SortStrategy(%d)", int(s))
+}
+
+// sortQuotes orders quotes best-first according to strategy. Ties fall back
+// to the sender's cost so the order is deterministic across strategies.
+func sortQuotes(quotes []*RemittanceQuote, strategy SortStrategy) {
+	sortQuotesWeighted(quotes, strategy, nil)
+}
+
+// sortQuotesWeighted is sortQuotes with each provider's cost or received
+// amount scaled by weight (see ProviderWeights). A nil weight is neutral.
+func sortQuotesWeighted(quotes []*RemittanceQuote, strategy SortStrategy, weight func(provider string) float64) {
+	if weight == nil {
+		weight = func(string) float64 { return 1 }
+	}
+	sort.SliceStable(quotes, func(i, j int) bool {
+		a, b := quotes[i], quotes[j]
+		wa, wb := weight(a.Provider), weight(b.Provider)
+		switch strategy {
+		case LowestCost:
+			if ca, cb := weighCost(a.cost(), wa), weighCost(b.cost(), wb); ca != cb {
+				return ca < cb
+			}
+		case HighestReceived:
+			if ra, rb := a.ReceivedAmount*wa, b.ReceivedAmount*wb; ra != rb {
+				return ra > rb
+			}
+		case FastestDelivery:
+			_, aMax, aOK := a.DeliveryWindow()
+			_, bMax, bOK := b.DeliveryWindow()
+			if aOK != bOK {
+				return aOK // unknown delivery times sort last
+			}
+			if aMax != bMax {
+				return aMax < bMax
+			}
+		case EffectiveCost:
+			if ca, cb := weighCost(a.EffectiveCost, wa), weighCost(b.EffectiveCost, wb); ca != cb {
+				return ca < cb
+			}
+		}
+		return a.cost() < b.cost()
+	})
+}
+
+// GetBestQuoteBy returns the best available quote under the given strategy
+func (rh *RemittanceHub) GetBestQuoteBy(ctx context.Context, req TransactionRequest, strategy SortStrategy) (*RemittanceQuote, error) {
 	quotes, err := rh.GetQuotes(ctx, req)
 	if err != nil {
 		return nil, err
 	}
 	
 	if len(quotes) == 0 {
-		return nil, errors.New("no quotes available")
+		return nil, ErrNoQuotes
 	}
 	
+	rh.sortQuotes(quotes, req, strategy)
+	if rh.balancer != nil {
+		return rh.balancer.pick(quotes, strategy), nil
+	}
 	return quotes[0], nil // First quote is best due to sorting
 }
 
@@ -592,21 +2329,105 @@ type WalletRemittanceService struct {
 	hub *RemittanceHub
 }
 
-func NewWalletRemittanceService() *WalletRemittanceService {
+// Provider credentials, embedded in the per-provider Config sections
+type WiseCredentials struct {
+	APIKey    string `json:"api_key"`
+	ProfileID string `json:"profile_id"`
+}
+
+type RemitlyCredentials struct {
+	APIKey string `json:"api_key"`
+}
+
+type WorldRemitCredentials struct {
+	APIKey    string `json:"api_key"`
+	APISecret string `json:"api_secret"`
+}
+
+type XoomCredentials struct {
+	ClientID     string `json:"client_id"`
+	ClientSecret string `json:"client_secret"`
+}
+
+func NewWalletRemittanceService(cfg Config) (*WalletRemittanceService, error) {
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+	
 	hub := NewRemittanceHub()
+	var transport http.RoundTripper
+	if cfg.Transport != nil {
+		transport = NewTransport(*cfg.Transport)
+	}
 	
-	// Add providers
-	hub.AddProvider(NewWiseProvider("wise-api-key", "wise-profile-id"))
-	hub.AddProvider(NewRemitlyProvider("remitly-api-key"))
-	hub.AddProvider(NewWorldRemitProvider("worldremit-api-key", "worldremit-secret"))
+	// Add enabled providers
+	for _, name := range cfg.EnabledProviders() {
+		var provider RemittanceProvider
+		switch name {
+		case ProviderWise:
+			p := NewWiseProvider(cfg.Wise.APIKey, cfg.Wise.ProfileID)
+			p.AutoCreateRecipients = cfg.Wise.AutoCreateRecipients
+			p.Identity = cfg.Wise.Identity
+			applyEndpointConfig(&p.BaseURL, p.client, cfg.Wise.BaseURL, cfg.Wise.Timeout, transport)
+			provider = p
+		case ProviderRemitly:
+			if cfg.Remitly.Simulate {
+				provider = newSimulatedRemitly()
+				break
+			}
+			p := NewRemitlyProvider(cfg.Remitly.APIKey)
+			p.Identity = cfg.Remitly.Identity
+			applyEndpointConfig(&p.BaseURL, p.client, cfg.Remitly.BaseURL, cfg.Remitly.Timeout, transport)
+			provider = p
+		case ProviderWorldRemit:
+			if cfg.WorldRemit.Simulate {
+				provider = newSimulatedWorldRemit()
+				break
+			}
+			p := NewWorldRemitProvider(cfg.WorldRemit.APIKey, cfg.WorldRemit.APISecret)
+			p.Identity = cfg.WorldRemit.Identity
+			applyEndpointConfig(&p.BaseURL, p.client, cfg.WorldRemit.BaseURL, cfg.WorldRemit.Timeout, transport)
+			provider = p
+		case ProviderXoom:
+			p := NewXoomProvider(cfg.Xoom.ClientID, cfg.Xoom.ClientSecret)
+			p.Identity = cfg.Xoom.Identity
+			applyEndpointConfig(&p.BaseURL, p.client, cfg.Xoom.BaseURL, cfg.Xoom.Timeout, transport)
+			provider = p
+		}
+		if err := hub.AddProvider(provider); err != nil {
+			return nil, fmt.Errorf("config: %w", err)
+		}
+	}
 	
-	return &WalletRemittanceService{hub: hub}
+	if len(hub.snapshot()) == 0 {
+		return nil, errors.New("no remittance providers configured: supply credentials for at least one provider")
+	}
+	
+	return &WalletRemittanceService{hub: hub}, nil
+}
+
+// applyEndpointConfig overrides a provider's base URL, client timeout and
+// transport when the config sets them
+func applyEndpointConfig(baseURL *string, client *http.Client, configURL string, timeout Duration, transport http.RoundTripper) {
+	if configURL != "" {
+		*baseURL = strings.TrimRight(configURL, "/")
+	}
+	if timeout > 0 {
+		client.Timeout = time.Duration(timeout)
+	}
+	if transport != nil {
+		client.Transport = transport
+	}
 }
 
 func (wrs *WalletRemittanceService) GetRemittanceOptions(ctx context.Context, req TransactionRequest) ([]*RemittanceQuote, error) {
 	return wrs.hub.GetQuotes(ctx, req)
 }
 
+func (wrs *WalletRemittanceService) GetRemittanceOptionsFiltered(ctx context.Context, req TransactionRequest, filter QuoteFilter) ([]*RemittanceQuote, error) {
+	return wrs.hub.GetQuotesFiltered(ctx, req, filter)
+}
+
 func (wrs *WalletRemittanceService) SendRemittance(ctx context.Context, providerName string, req TransactionRequest) (*TransactionResponse, error) {
 	return wrs.hub.SendMoneyWithProvider(ctx, providerName, req)
 }
@@ -615,12 +2436,24 @@ func (wrs *WalletRemittanceService) GetBestOption(ctx context.Context, req Trans
 	return wrs.hub.GetBestQuote(ctx, req)
 }
 
+func (wrs *WalletRemittanceService) GetBestOptionBy(ctx context.Context, req TransactionRequest, strategy SortStrategy) (*RemittanceQuote, error) {
+	return wrs.hub.GetBestQuoteBy(ctx, req, strategy)
+}
+
 // Example usage and demo
 func main() {
 	ctx := context.Background()
 	
-	// Create wallet remittance service
-	service := NewWalletRemittanceService()
+	// Create wallet remittance service (demo credentials)
+	service, err := NewWalletRemittanceService(Config{
+		Wise:       WiseConfig{WiseCredentials: WiseCredentials{APIKey: "wise-api-key", ProfileID: "wise-profile-id"}},
+		Remitly:    RemitlyConfig{RemitlyCredentials: RemitlyCredentials{APIKey: "remitly-api-key"}, Simulate: true},
+		WorldRemit: WorldRemitConfig{WorldRemitCredentials: WorldRemitCredentials{APIKey: "worldremit-api-key", APISecret: "worldremit-secret"}, Simulate: true},
+		Xoom:       XoomConfig{XoomCredentials: XoomCredentials{ClientID: "xoom-client-id", ClientSecret: "xoom-client-secret"}},
+	})
+	if err != nil {
+		log.Fatal("Error creating service:", err)
+	}
 	
 	// Create sample transaction request
 	recipient := Recipient{
@@ -660,8 +2493,11 @@ func main() {
 		fmt.Printf("  Fee: $%.2f\n", quote.Fee)
 		fmt.Printf("  Exchange Rate: %.4f\n", quote.ExchangeRate)
 		fmt.Printf("  Total Cost: $%.2f\n", quote.TotalCost)
-		fmt.Printf("  Recipient Gets: %.2f %s\n", quote.ReceivedAmount, request.ToCurrency)
+		fmt.Printf("  Recipient Gets: %s %s\n", FormatAmount(quote.ReceivedAmount, request.ToCurrency), request.ToCurrency)
 		fmt.Printf("  Estimated Time: %s\n", quote.EstimatedTime)
+		if !quote.EstimatedArrival.IsZero() {
+			fmt.Printf("  Arrives By: %s\n", quote.EstimatedArrival.Format("Monday, Jan 2"))
+		}
 		fmt.Printf("  Valid Until: %s\n", quote.ValidUntil.Format("2006-01-02 15:04:05"))
 	}
 	
@@ -674,7 +2510,7 @@ func main() {
 	
 	fmt.Printf("Best Provider: %s\n", bestQuote.Provider)
 	fmt.Printf("Total Cost: $%.2f\n", bestQuote.TotalCost)
-	fmt.Printf("Recipient Gets: %.2f %s\n", bestQuote.ReceivedAmount, request.ToCurrency)
+	fmt.Printf("Recipient Gets: %s %s\n", FormatAmount(bestQuote.ReceivedAmount, request.ToCurrency), request.ToCurrency)
 	
 	// Send money with best provider
 	fmt.Println("\n=== Sending Money ===")
@@ -0,0 +1,83 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestGetQuotesReturnsPromptlyOnCancel(t *testing.T) {
+	blocking := newBlockingProvider(t)
+	hub := NewRemittanceHub()
+	hub.AddProvider(newSimulatedRemitly())
+	hub.AddProvider(blocking)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	go func() {
+		<-blocking.started
+		cancel()
+	}()
+
+	start := time.Now()
+	quotes, err := hub.GetQuotes(ctx, testRequest())
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Fatalf("GetQuotes took %v after cancel; it waited on the blocked provider", elapsed)
+	}
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("err = %v, want context.Canceled", err)
+	}
+	for _, quote := range quotes {
+		if quote.Provider == blocking.GetName() {
+			t.Errorf("got a quote from the blocked provider")
+		}
+	}
+}
+
+// TestHubConcurrentUse is meant for -race: quotes, sends and status checks
+// run while providers are registered, replaced and removed
+func TestHubConcurrentUse(t *testing.T) {
+	ctx := context.Background()
+	hub := NewRemittanceHub()
+	hub.AddProvider(newSimulatedRemitly())
+	hub.AddProvider(newSimulatedWorldRemit())
+
+	var wg sync.WaitGroup
+	errs := make(chan error, 64)
+	for i := 0; i < 8; i++ {
+		wg.Add(3)
+		go func() {
+			defer wg.Done()
+			if _, err := hub.GetQuotes(ctx, testRequest()); err != nil {
+				errs <- fmt.Errorf("quotes: %w", err)
+			}
+		}()
+		go func(i int) {
+			defer wg.Done()
+			req := testRequest()
+			req.Reference = fmt.Sprintf("REF-CONCURRENT-%d", i)
+			tx, err := hub.SendMoneyWithProvider(ctx, "Remitly", req)
+			if err != nil {
+				errs <- fmt.Errorf("send: %w", err)
+				return
+			}
+			if _, err := hub.GetTransactionStatus(ctx, "Remitly", tx.TransactionID); err != nil {
+				errs <- fmt.Errorf("status: %w", err)
+			}
+		}(i)
+		go func() {
+			defer wg.Done()
+			extra := NewSimulatedProvider("Extra", remitlyRoutes, FixedPricing(1.1, 1, 0, "Minutes"))
+			hub.AddProvider(extra)
+			hub.ReplaceProvider(extra)
+			hub.RemoveProvider(extra.GetName())
+		}()
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		t.Error(err)
+	}
+}
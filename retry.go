@@ -0,0 +1,126 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+)
+
+// RetryPolicy controls how the hub retries provider reads (quotes and
+// status checks) that fail transiently. Sends are never retried here: use
+// IdempotencyKey and FindTransactionByIdempotencyKey for those.
+type RetryPolicy struct {
+	// MaxAttempts counts the first call; 1 or less disables retries
+	MaxAttempts int
+	// BaseDelay is the first backoff when the provider gives no
+	// Retry-After; it doubles on each attempt (default 200ms)
+	BaseDelay time.Duration
+	// MaxDelay caps any single wait, Retry-After included (default 30s)
+	MaxDelay time.Duration
+}
+
+func (p RetryPolicy) withDefaults() RetryPolicy {
+	if p.BaseDelay <= 0 {
+		p.BaseDelay = 200 * time.Millisecond
+	}
+	if p.MaxDelay <= 0 {
+		p.MaxDelay = 30 * time.Second
+	}
+	return p
+}
+
+// ParseRetryAfter reads a Retry-After value, either delay-seconds or an
+// HTTP-date. A date in the past yields zero.
+func ParseRetryAfter(value string, now time.Time) (time.Duration, bool) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return 0, false
+	}
+	if seconds, err := strconv.Atoi(value); err == nil {
+		if seconds < 0 {
+			return 0, false
+		}
+		return time.Duration(seconds) * time.Second, true
+	}
+	at, err := http.ParseTime(value)
+	if err != nil {
+		return 0, false
+	}
+	if wait := at.Sub(now); wait > 0 {
+		return wait, true
+	}
+	return 0, true
+}
+
+// RetryAfter returns the wait the provider asked for, if any
+func (e *APIError) RetryAfter() (time.Duration, bool) {
+	return ParseRetryAfter(e.Header.Get("Retry-After"), time.Now())
+}
+
+// SetRetryPolicy enables retries of transient quote and status failures.
+// Call it before serving traffic.
+func (rh *RemittanceHub) SetRetryPolicy(policy RetryPolicy) {
+	rh.retryPolicy = policy.withDefaults()
+}
+
+// retry runs op until it succeeds, fails permanently, runs out of attempts
+// or the next wait would overrun ctx's deadline. Rate-limit responses wait
+// for Retry-After (capped at MaxDelay); other transient failures back off
+// exponentially.
+func (rh *RemittanceHub) retry(ctx context.Context, op func(ctx context.Context) error) error {
+	policy := rh.retryPolicy
+	var err error
+	for attempt := 1; ; attempt++ {
+		if err = op(ctx); err == nil {
+			return nil
+		}
+		if attempt >= policy.MaxAttempts || !retryable(err) {
+			return err
+		}
+
+		wait := policy.BaseDelay << (attempt - 1)
+		var apiErr *APIError
+		if errors.As(err, &apiErr) {
+			if after, ok := apiErr.RetryAfter(); ok {
+				wait = after
+			}
+		}
+		if wait > policy.MaxDelay {
+			wait = policy.MaxDelay
+		}
+		if deadline, ok := ctx.Deadline(); ok && time.Now().Add(wait).After(deadline) {
+			return err
+		}
+
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return err
+		case <-timer.C:
+		}
+	}
+}
+
+// retryable reports whether err is worth another attempt: an API error
+// the provider marks retryable, or a transport failure such as a timeout
+// or a connection dropped or reset before the response was read. Anything
+// else (a body that won't decode, a validation or unsupported error, a
+// transfer that doesn't exist) would fail the same way again.
+func retryable(err error) bool {
+	var apiErr *APIError
+	if errors.As(err, &apiErr) {
+		return apiErr.Retryable()
+	}
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+	var netErr net.Error
+	return errors.As(err, &netErr) || errors.Is(err, io.ErrUnexpectedEOF) || errors.Is(err, syscall.ECONNRESET)
+}
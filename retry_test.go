@@ -0,0 +1,36 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"syscall"
+	"testing"
+)
+
+func TestRetryable(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{"transport failure", &url.Error{Op: "Post", URL: "https://api.example", Err: io.EOF}, true},
+		{"truncated body", fmt.Errorf("decode: %w", io.ErrUnexpectedEOF), true},
+		{"connection reset", fmt.Errorf("read: %w", syscall.ECONNRESET), true},
+		{"server error", &APIError{StatusCode: 503}, true},
+		{"client error", &APIError{StatusCode: 400}, false},
+		{"malformed body", json.Unmarshal([]byte("{]"), new(map[string]interface{})), false},
+		{"validation", &ValidationError{Field: "amount", Err: errors.New("must be positive")}, false},
+		{"unsupported", &UnsupportedError{Provider: "p", Operation: "refunds"}, false},
+		{"not found", fmt.Errorf("transfer 1: %w", ErrTransactionNotFound), false},
+		{"cancelled", &url.Error{Op: "Post", URL: "https://api.example", Err: context.Canceled}, false},
+	}
+	for _, tt := range tests {
+		if got := retryable(tt.err); got != tt.want {
+			t.Errorf("%s: retryable(%v) = %v, want %v", tt.name, tt.err, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,69 @@
+package main
+
+import (
+	"math"
+	"strconv"
+)
+
+// RoundingMode controls how a quote's ReceivedAmount is rounded to what
+// the recipient is actually paid
+type RoundingMode int
+
+const (
+	// RoundingAuto floors to whole units for cash pickup, since agents pay
+	// out notes and coins only, and rounds to the nearest minor unit of
+	// the destination currency for everything else
+	RoundingAuto RoundingMode = iota
+	// RoundingNearest rounds half away from zero at the currency's precision
+	RoundingNearest
+	// RoundingFloor truncates at the currency's precision, never promising
+	// more than is paid out
+	RoundingFloor
+	// RoundingFloorWhole truncates to whole currency units
+	RoundingFloorWhole
+)
+
+// RoundReceivedAmount rounds amount in currency to, paid out via the payout
+// method (never the funding method), according to mode. A tolerance absorbs
+// float noise, so 57499.99999999 floors to 57500, not 57499.
+func RoundReceivedAmount(amount float64, to Currency, method PaymentMethod, mode RoundingMode) float64 {
+	places := to.DecimalPlaces()
+	if mode == RoundingAuto {
+		mode = RoundingNearest
+		if method == PaymentCash {
+			mode = RoundingFloorWhole
+		}
+	}
+	if mode == RoundingFloorWhole {
+		places = 0
+	}
+
+	scale := math.Pow10(places)
+	scaled := amount * scale
+	switch mode {
+	case RoundingFloor, RoundingFloorWhole:
+		scaled = math.Floor(scaled + precisionEpsilon)
+	default:
+		scaled = math.Round(scaled)
+	}
+	return scaled / scale
+}
+
+// roundReceived applies the request's rounding to a quote from any provider
+func roundReceived(quote *RemittanceQuote, req TransactionRequest) {
+	quote.ReceivedAmount = RoundReceivedAmount(quote.ReceivedAmount, req.ToCurrency, req.payoutMethod(), req.RoundingMode)
+}
+
+// roundCost rounds Fee and TotalCost to the minor unit of the send
+// currency, so float noise from percentage fees (1010.0000000001) neither
+// shows in totals nor perturbs the quote sort
+func roundCost(quote *RemittanceQuote, from Currency) {
+	quote.Fee = RoundReceivedAmount(quote.Fee, from, "", RoundingNearest)
+	quote.TotalCost = RoundReceivedAmount(quote.TotalCost, from, "", RoundingNearest)
+}
+
+// FormatAmount renders amount with exactly the currency's decimal places,
+// for display alongside rounded amounts
+func FormatAmount(amount float64, c Currency) string {
+	return strconv.FormatFloat(amount, 'f', c.DecimalPlaces(), 64)
+}
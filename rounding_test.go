@@ -0,0 +1,39 @@
+package main
+
+import "testing"
+
+func TestRoundCostRemovesFloatArtifacts(t *testing.T) {
+	tests := []struct {
+		name      string
+		from      Currency
+		fee       float64
+		total     float64
+		wantFee   float64
+		wantTotal float64
+	}{
+		{"tenths", USD, 0.1 + 0.2, 100 + 0.1 + 0.2, 0.3, 100.3},
+		{"percentage fee", USD, 1000 * 0.0101, 1000 + 1000*0.0101, 10.1, 1010.1},
+		{"half cent", USD, 2.675, 502.675, 2.68, 502.68},
+		{"JPY zero decimals", JPY, 150.6, 10150.6, 151, 10151},
+		{"JPY float noise", JPY, 0.1 + 0.2 + 99.7, 10000 + 0.1 + 0.2 + 99.7, 100, 10100},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			quote := &RemittanceQuote{Fee: tt.fee, TotalCost: tt.total}
+			roundCost(quote, tt.from)
+			if quote.Fee != tt.wantFee || quote.TotalCost != tt.wantTotal {
+				t.Errorf("roundCost(%v, %v) = %v, %v; want %v, %v",
+					tt.fee, tt.total, quote.Fee, quote.TotalCost, tt.wantFee, tt.wantTotal)
+			}
+		})
+	}
+}
+
+func TestRoundReceivedAmountFloorsThroughNoise(t *testing.T) {
+	if got := RoundReceivedAmount(57499.99999999, INR, PaymentCash, RoundingAuto); got != 57500 {
+		t.Errorf("cash payout = %v, want 57500", got)
+	}
+	if got := RoundReceivedAmount(1234.567, INR, PaymentBankTransfer, RoundingFloor); got != 1234.56 {
+		t.Errorf("floor = %v, want 1234.56", got)
+	}
+}
@@ -0,0 +1,429 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sync"
+	"time"
+)
+
+// SimulatedPrice is what a PricingFunc quotes for one request
+type SimulatedPrice struct {
+	Rate float64
+	Fee  float64
+	// EstimatedTime is a human-readable delivery estimate understood by
+	// ParseEstimatedTime ("Minutes", "1-2 business days")
+	EstimatedTime string
+	// ValidFor is how long the quote stays valid; zero means 15 minutes
+	ValidFor time.Duration
+}
+
+// PricingFunc prices a request for a SimulatedProvider. GetExchangeRates
+// calls it with a zero Amount.
+type PricingFunc func(req TransactionRequest) (SimulatedPrice, error)
+
+// FixedPricing returns a PricingFunc with a constant rate and a fee of
+// flatFee plus feePercent (0.02 = 2%) of the send amount.
+func FixedPricing(rate, flatFee, feePercent float64, estimate string) PricingFunc {
+	return func(req TransactionRequest) (SimulatedPrice, error) {
+		return SimulatedPrice{
+			Rate:          rate,
+			Fee:           flatFee + req.Amount*feePercent,
+			EstimatedTime: estimate,
+		}, nil
+	}
+}
+
+// StatusStep moves a simulated transfer to Status once After has elapsed
+// since it was sent. Tracking is the progress step reported meanwhile;
+// empty derives it from Status.
+type StatusStep struct {
+	After    time.Duration
+	Status   TransactionStatus
+	Tracking TrackingStep
+}
+
+// DefaultSimulatedTimeline completes a transfer a minute after it is sent,
+// passing through each tracking step on the way
+var DefaultSimulatedTimeline = []StatusStep{
+	{After: 0, Status: StatusPending, Tracking: StepSubmitted},
+	{After: 20 * time.Second, Status: StatusPending, Tracking: StepFundsReceived},
+	{After: 40 * time.Second, Status: StatusPending, Tracking: StepProcessing},
+	{After: time.Minute, Status: StatusCompleted, Tracking: StepPaidOut},
+}
+
+// SimulatedProvider is a RemittanceProvider that never leaves the process.
+// Prices come from Pricing and sent transfers advance through Timeline as
+// time passes, which makes it suitable for demos and load tests. It must
+// never be configured for production traffic.
+type SimulatedProvider struct {
+	Name    string
+	Routes  []Route
+	Pricing PricingFunc
+	// SendingCountries is advertised by GetSendingCountries; nil accepts
+	// senders from anywhere
+	SendingCountries []string
+	// Timeline must be ordered by After; nil uses DefaultSimulatedTimeline
+	Timeline []StatusStep
+	// Now is the clock used for status transitions; nil uses time.Now
+	Now func() time.Time
+	// Networks is advertised as Capabilities.PayoutNetworks
+	Networks map[string][]PayoutNetwork
+	// Eligibility is advertised as Capabilities.Eligibility
+	Eligibility []EligibilityRule
+	// FundingMethods is advertised as Capabilities.FundingMethods
+	FundingMethods []PaymentMethod
+	// FundingFees adds a fraction of the send amount to the fee for a
+	// funding method (PaymentCard: 0.03 = 3% card surcharge)
+	FundingFees map[PaymentMethod]float64
+	// Wallets maps phone numbers to registered names for wallet
+	// validation; nil leaves wallet validation unsupported
+	Wallets map[string]string
+	// DocumentsAbove holds sends of at least this amount for a proof of
+	// funds document; the timeline starts once it is uploaded. Zero
+	// requires none.
+	DocumentsAbove float64
+
+	limits    map[Route]corridorLimit
+	mu        sync.Mutex
+	seq       int
+	transfers map[string]*simulatedTransfer
+}
+
+type simulatedTransfer struct {
+	response TransactionResponse
+	sentAt   time.Time
+}
+
+// NewSimulatedProvider creates a SimulatedProvider called name serving
+// routes, priced by pricing
+func NewSimulatedProvider(name string, routes []Route, pricing PricingFunc) *SimulatedProvider {
+	return &SimulatedProvider{
+		Name:      name,
+		Routes:    routes,
+		Pricing:   pricing,
+		transfers: make(map[string]*simulatedTransfer),
+	}
+}
+
+func (s *SimulatedProvider) now() time.Time {
+	if s.Now != nil {
+		return s.Now()
+	}
+	return time.Now()
+}
+
+func (s *SimulatedProvider) GetName() string {
+	return s.Name
+}
+
+func (s *SimulatedProvider) GetSupportedCurrencies() []Currency {
+	seen := make(map[Currency]bool)
+	var currencies []Currency
+	for _, route := range s.Routes {
+		for _, c := range []Currency{route.From, route.To} {
+			if !seen[c] {
+				seen[c] = true
+				currencies = append(currencies, c)
+			}
+		}
+	}
+	return currencies
+}
+
+// GetSendingCountries returns SendingCountries
+func (s *SimulatedProvider) GetSendingCountries() []string {
+	return s.SendingCountries
+}
+
+// GetReceivingCountries returns the countries of Routes
+func (s *SimulatedProvider) GetReceivingCountries() []string {
+	seen := make(map[string]bool)
+	var countries []string
+	for _, route := range s.Routes {
+		if !seen[route.Country] {
+			seen[route.Country] = true
+			countries = append(countries, route.Country)
+		}
+	}
+	return countries
+}
+
+func (s *SimulatedProvider) GetSupportedRoutes() []Route {
+	return s.Routes
+}
+
+func (s *SimulatedProvider) SupportsCorridor(from, to Currency, country string) bool {
+	return hasRoute(s.Routes, from, to, country)
+}
+
+func (s *SimulatedProvider) GetCapabilities() Capabilities {
+	return Capabilities{FeeModels: allFeeModels, PayoutNetworks: s.Networks, FundingMethods: s.FundingMethods, Eligibility: s.Eligibility}
+}
+
+func (s *SimulatedProvider) GetQuote(ctx context.Context, req TransactionRequest) (*RemittanceQuote, error) {
+	price, err := s.Pricing(req)
+	if err != nil {
+		return nil, err
+	}
+
+	quote := &RemittanceQuote{
+		Provider:      s.Name,
+		Amount:        req.Amount,
+		Fee:           price.Fee,
+		ExchangeRate:  price.Rate,
+		EstimatedTime: price.EstimatedTime,
+		ValidUntil:    s.now().Add(price.validFor()),
+	}
+	quote.EstimatedMin, quote.EstimatedMax, _ = ParseEstimatedTime(price.EstimatedTime)
+	quote.FundingFee = req.Amount * s.FundingFees[req.fundingMethod()]
+	quote.Fee += quote.FundingFee
+	applyFeeModel(quote, req.FeePaidBy)
+	roundCost(quote, req.FromCurrency)
+	return quote, nil
+}
+
+func (p SimulatedPrice) validFor() time.Duration {
+	if p.ValidFor > 0 {
+		return p.ValidFor
+	}
+	return 15 * time.Minute
+}
+
+func (s *SimulatedProvider) SendMoney(ctx context.Context, req TransactionRequest) (*TransactionResponse, error) {
+	price, err := s.Pricing(req)
+	if err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.seq++
+	transactionID := s.transactionID(s.seq)
+	transfer := &simulatedTransfer{
+		response: TransactionResponse{
+			TransactionID: transactionID,
+			Status:        s.statusAt(0),
+			Amount:        req.Amount,
+			Fee:           price.Fee,
+			ExchangeRate:  price.Rate,
+			EstimatedTime: price.EstimatedTime,
+		},
+		sentAt: s.now(),
+	}
+	transfer.response.FundingFee = req.Amount * s.FundingFees[req.fundingMethod()]
+	transfer.response.Fee += transfer.response.FundingFee
+	if s.DocumentsAbove > 0 && req.Amount >= s.DocumentsAbove {
+		transfer.response.requireDocuments([]DocumentType{DocumentProofOfFunds})
+	}
+	if s.transfers == nil {
+		s.transfers = make(map[string]*simulatedTransfer)
+	}
+	s.transfers[transactionID] = transfer
+
+	response := transfer.response
+	return &response, nil
+}
+
+func (s *SimulatedProvider) transactionID(seq int) string {
+	return fmt.Sprintf("SIM_%s_%d", s.Name, seq)
+}
+
+// statusAt returns the Timeline status reached after elapsed
+func (s *SimulatedProvider) statusAt(elapsed time.Duration) TransactionStatus {
+	return s.stepAt(elapsed).Status
+}
+
+// stepAt returns the Timeline step reached after elapsed
+func (s *SimulatedProvider) stepAt(elapsed time.Duration) StatusStep {
+	timeline := s.Timeline
+	if timeline == nil {
+		timeline = DefaultSimulatedTimeline
+	}
+	reached := StatusStep{Status: StatusPending}
+	for _, step := range timeline {
+		if step.After > elapsed {
+			break
+		}
+		reached = step
+	}
+	return reached
+}
+
+func (s *SimulatedProvider) GetTransactionStatus(ctx context.Context, transactionID string) (*TransactionResponse, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	transfer, ok := s.transfers[transactionID]
+	if !ok {
+		return nil, fmt.Errorf("%s transfer %s: %w", s.Name, transactionID, ErrTransactionNotFound)
+	}
+
+	response := transfer.response
+	if len(response.RequiredDocuments) > 0 {
+		return &response, nil
+	}
+	step := s.stepAt(s.now().Sub(transfer.sentAt))
+	response.Status, response.Progress = step.Status, progressAt(step.Tracking)
+	return &response, nil
+}
+
+// UploadComplianceDocument clears a document the transfer is held for.
+// The timeline restarts once none are outstanding.
+func (s *SimulatedProvider) UploadComplianceDocument(ctx context.Context, transactionID string, doc Document) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	transfer, ok := s.transfers[transactionID]
+	if !ok {
+		return fmt.Errorf("%s transfer %s: %w", s.Name, transactionID, ErrTransactionNotFound)
+	}
+	if _, err := io.Copy(io.Discard, doc.Content); err != nil {
+		return fmt.Errorf("read document: %w", err)
+	}
+
+	response := &transfer.response
+	if !containsDocument(response.RequiredDocuments, doc.Type) {
+		return nil
+	}
+	var documents []DocumentType
+	for _, t := range response.RequiredDocuments {
+		if t != doc.Type {
+			documents = append(documents, t)
+		}
+	}
+	var actions []string
+	for _, action := range response.RequiredActions {
+		if action != documentAction(doc.Type) {
+			actions = append(actions, action)
+		}
+	}
+	response.RequiredDocuments, response.RequiredActions = documents, actions
+	if len(documents) == 0 {
+		transfer.sentAt = s.now()
+	}
+	return nil
+}
+
+// GetTransactionEvents replays the Timeline steps the transfer has reached
+func (s *SimulatedProvider) GetTransactionEvents(ctx context.Context, transactionID string) ([]TransactionEvent, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	transfer, ok := s.transfers[transactionID]
+	if !ok {
+		return nil, fmt.Errorf("%s transfer %s: %w", s.Name, transactionID, ErrTransactionNotFound)
+	}
+
+	timeline := s.Timeline
+	if timeline == nil {
+		timeline = DefaultSimulatedTimeline
+	}
+	events := []TransactionEvent{{Type: EventCreated, Timestamp: transfer.sentAt, ProviderCode: string(StatusPending)}}
+	elapsed := s.now().Sub(transfer.sentAt)
+	if len(transfer.response.RequiredDocuments) > 0 {
+		elapsed = 0
+	}
+	for _, step := range timeline {
+		if step.After == 0 {
+			continue
+		}
+		if step.After > elapsed {
+			break
+		}
+		events = append(events, TransactionEvent{
+			Type:         simulatedEventTypes[step.Status],
+			Timestamp:    transfer.sentAt.Add(step.After),
+			ProviderCode: string(step.Status),
+		})
+	}
+	return events, nil
+}
+
+var simulatedEventTypes = map[TransactionStatus]TransactionEventType{
+	StatusPending:       EventProcessing,
+	StatusCompleted:     EventPaidOut,
+	StatusFailed:        EventFailed,
+	StatusCancelled:     EventCancelled,
+	StatusRefundPending: EventUpdated,
+	StatusRefunded:      EventRefunded,
+}
+
+// RefundTransaction: simulated transfers have no refund flow, so this
+// returns the current status
+func (s *SimulatedProvider) RefundTransaction(ctx context.Context, transactionID, reason string) (*TransactionResponse, error) {
+	return s.GetTransactionStatus(ctx, transactionID)
+}
+
+func (s *SimulatedProvider) GetExchangeRates(ctx context.Context, from, to Currency) (*ExchangeRate, error) {
+	price, err := s.Pricing(TransactionRequest{FromCurrency: from, ToCurrency: to})
+	if err != nil {
+		return nil, err
+	}
+	return &ExchangeRate{
+		From:       from,
+		To:         to,
+		Rate:       price.Rate,
+		Fee:        price.Fee,
+		ValidUntil: s.now().Add(price.validFor()),
+	}, nil
+}
+
+func (s *SimulatedProvider) GetExchangeRatesBatch(ctx context.Context, pairs []CurrencyPair) (map[CurrencyPair]*ExchangeRate, error) {
+	return fetchRatesConcurrently(ctx, s, pairs)
+}
+
+// GetTransactionHistory lists transfers sent through this provider, newest
+// first. Since and Until are ignored.
+func (s *SimulatedProvider) GetTransactionHistory(ctx context.Context, opts HistoryOptions) (*HistoryPage, error) {
+	offset, err := decodeOffsetCursor(opts.Cursor)
+	if err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	page := &HistoryPage{}
+	// IDs are numbered in send order, so walk the sequence backwards
+	for seq := s.seq - offset; seq > 0 && len(page.Transactions) < opts.limit(); seq-- {
+		transfer := s.transfers[s.transactionID(seq)]
+		response := transfer.response
+		response.Status = s.statusAt(s.now().Sub(transfer.sentAt))
+		page.Transactions = append(page.Transactions, &response)
+	}
+	if offset+len(page.Transactions) < s.seq {
+		page.NextCursor = encodeOffsetCursor(offset + len(page.Transactions))
+	}
+	return page, nil
+}
+
+// GetCorridorLimits reports the limits of the provider being impersonated,
+// if any; a plain SimulatedProvider accepts any amount
+func (s *SimulatedProvider) GetCorridorLimits(ctx context.Context, from, to Currency, country string) (min, max float64, err error) {
+	if s.limits == nil {
+		return 0, 0, nil
+	}
+	return lookupCorridorLimit(s.Name, s.limits, from, to, country)
+}
+
+// newSimulatedRemitly stands in for Remitly using its published fee and
+// an example rate
+func newSimulatedRemitly() *SimulatedProvider {
+	sim := NewSimulatedProvider("Remitly", remitlyRoutes, FixedPricing(1.15, 0, remitlyFeeRate, "Minutes to hours"))
+	sim.limits = remitlyCorridorLimits
+	sim.SendingCountries = remitlySendingCountries
+	sim.Networks = remitlyNetworks
+	sim.FundingMethods = cardOrBank
+	sim.Eligibility = adultsOnly
+	return sim
+}
+
+// newSimulatedWorldRemit stands in for WorldRemit using its published flat
+// fee and an example rate
+func newSimulatedWorldRemit() *SimulatedProvider {
+	sim := NewSimulatedProvider("WorldRemit", worldRemitRoutes, FixedPricing(1.18, worldRemitFlatFee, 0, "Minutes"))
+	sim.limits = worldRemitCorridorLimits
+	sim.SendingCountries = worldRemitSendingCountries
+	sim.Networks = worldRemitNetworks
+	sim.FundingMethods = cardOrBank
+	sim.Eligibility = adultsOnly
+	return sim
+}
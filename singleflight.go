@@ -0,0 +1,126 @@
+package main
+
+import (
+	"context"
+	"sync"
+)
+
+// quoteFlightKey identifies quote requests that would get the same answer
+// from a provider
+type quoteFlightKey struct {
+	provider  string
+	from      Currency
+	to        Currency
+	country   string
+	amount    float64
+	method    PaymentMethod
+	payIn     PaymentMethod
+	feePaidBy FeePaidBy
+	promoCode string
+	network   PayoutNetwork
+	funding   Currency
+}
+
+func newQuoteFlightKey(provider string, req TransactionRequest) quoteFlightKey {
+	return quoteFlightKey{
+		provider:  provider,
+		from:      req.FromCurrency,
+		to:        req.ToCurrency,
+		country:   req.Recipient.Address.CountryCode,
+		amount:    req.Amount,
+		method:    req.PaymentMethod,
+		payIn:     req.fundingMethod(),
+		feePaidBy: req.FeePaidBy,
+		promoCode: req.PromoCode,
+		network:   req.PayoutNetwork,
+		funding:   req.FundingCurrency,
+	}
+}
+
+type quoteCall struct {
+	done  chan struct{}
+	quote *RemittanceQuote
+	err   error
+	// waiters counts the callers still waiting; when the last one gives
+	// up, cancel aborts the upstream call
+	waiters int
+	cancel  context.CancelFunc
+}
+
+// quoteFlight collapses concurrent identical GetQuote calls into one
+// upstream call whose result every caller shares (the same idea as
+// golang.org/x/sync/singleflight). Nothing is kept once the call returns,
+// so it is not a cache: a request arriving afterwards calls upstream again.
+type quoteFlight struct {
+	mu    sync.Mutex
+	calls map[quoteFlightKey]*quoteCall
+}
+
+func newQuoteFlight() *quoteFlight {
+	return &quoteFlight{calls: make(map[quoteFlightKey]*quoteCall)}
+}
+
+// do runs fn unless an identical call is already in flight, in which case
+// it waits for that call's result. The call runs on a context that keeps
+// the first caller's values but not its cancellation, so one caller giving
+// up doesn't fail the rest; each returns early on its own ctx, and the
+// call is cancelled only once no caller is left waiting. Every caller gets
+// its own copy of the quote, since callers annotate quotes in place.
+func (f *quoteFlight) do(ctx context.Context, key quoteFlightKey, fn func(context.Context) (*RemittanceQuote, error)) (*RemittanceQuote, error) {
+	f.mu.Lock()
+	call, ok := f.calls[key]
+	if !ok {
+		callCtx, cancel := context.WithCancel(context.WithoutCancel(ctx))
+		call = &quoteCall{done: make(chan struct{}), cancel: cancel}
+		f.calls[key] = call
+		go f.run(callCtx, key, call, fn)
+	}
+	call.waiters++
+	f.mu.Unlock()
+
+	select {
+	case <-call.done:
+		return copyQuote(call.quote), call.err
+	case <-ctx.Done():
+		f.mu.Lock()
+		call.waiters--
+		if call.waiters == 0 {
+			// Nobody wants the answer; later callers start afresh rather
+			// than joining a cancelled call
+			call.cancel()
+			if f.calls[key] == call {
+				delete(f.calls, key)
+			}
+		}
+		f.mu.Unlock()
+		return nil, ctx.Err()
+	}
+}
+
+func (f *quoteFlight) run(ctx context.Context, key quoteFlightKey, call *quoteCall, fn func(context.Context) (*RemittanceQuote, error)) {
+	quote, err := fn(ctx)
+	f.mu.Lock()
+	if f.calls[key] == call {
+		delete(f.calls, key)
+	}
+	f.mu.Unlock()
+	call.quote, call.err = quote, err
+	call.cancel()
+	close(call.done)
+}
+
+// copyQuote copies quote deeply enough that callers can append to its
+// slices and edit its funding leg without affecting each other
+func copyQuote(quote *RemittanceQuote) *RemittanceQuote {
+	if quote == nil {
+		return nil
+	}
+	c := *quote
+	c.Warnings = append([]string(nil), quote.Warnings...)
+	c.RequiredActions = append([]string(nil), quote.RequiredActions...)
+	if quote.FundingLeg != nil {
+		leg := *quote.FundingLeg
+		c.FundingLeg = &leg
+	}
+	return &c
+}
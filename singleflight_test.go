@@ -0,0 +1,84 @@
+package main
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestQuoteFlightLeaderCancelDoesNotFailWaiters(t *testing.T) {
+	f := newQuoteFlight()
+	key := quoteFlightKey{provider: "p"}
+	release := make(chan struct{})
+	started := make(chan struct{})
+	fn := func(ctx context.Context) (*RemittanceQuote, error) {
+		close(started)
+		select {
+		case <-release:
+			return &RemittanceQuote{Provider: "p", Warnings: []string{"shared"}}, nil
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+
+	leaderCtx, cancelLeader := context.WithCancel(context.Background())
+	leaderErr := make(chan error, 1)
+	go func() {
+		_, err := f.do(leaderCtx, key, fn)
+		leaderErr <- err
+	}()
+	<-started
+
+	type result struct {
+		quote *RemittanceQuote
+		err   error
+	}
+	waiter := make(chan result, 1)
+	go func() {
+		quote, err := f.do(context.Background(), key, fn)
+		waiter <- result{quote, err}
+	}()
+	// Let the waiter join before the leader leaves
+	for {
+		f.mu.Lock()
+		waiters := f.calls[key].waiters
+		f.mu.Unlock()
+		if waiters == 2 {
+			break
+		}
+		time.Sleep(time.Millisecond)
+	}
+
+	cancelLeader()
+	if err := <-leaderErr; err != context.Canceled {
+		t.Fatalf("leader err = %v, want context.Canceled", err)
+	}
+	close(release)
+	got := <-waiter
+	if got.err != nil || got.quote == nil {
+		t.Fatalf("waiter got %v, %v; want the shared quote", got.quote, got.err)
+	}
+}
+
+func TestQuoteFlightCopiesAreIndependent(t *testing.T) {
+	quote := &RemittanceQuote{
+		Warnings:        make([]string, 1, 4),
+		RequiredActions: []string{"verify"},
+		FundingLeg:      &ConversionLeg{Amount: 10},
+	}
+	a, b := copyQuote(quote), copyQuote(quote)
+	a.Warnings = append(a.Warnings, "promo for a")
+	b.Warnings = append(b.Warnings, "promo for b")
+	a.RequiredActions[0] = "changed"
+	a.FundingLeg.Amount = 20
+
+	if a.Warnings[1] != "promo for a" || b.Warnings[1] != "promo for b" {
+		t.Errorf("appends leaked between copies: %q, %q", a.Warnings, b.Warnings)
+	}
+	if b.RequiredActions[0] != "verify" || quote.RequiredActions[0] != "verify" {
+		t.Errorf("RequiredActions shared between copies")
+	}
+	if b.FundingLeg.Amount != 10 {
+		t.Errorf("FundingLeg shared between copies")
+	}
+}
@@ -0,0 +1,221 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"math"
+	"sort"
+	"sync"
+)
+
+// SplitStrategy chooses which providers SplitTransfer fills first
+type SplitStrategy int
+
+const (
+	// SplitCheapest fills the providers with the lowest effective cost per
+	// unit sent first
+	SplitCheapest SplitStrategy = iota
+	// SplitFastest fills the providers with the shortest worst-case
+	// delivery first, breaking ties on cost
+	SplitFastest
+)
+
+// PlannedTransfer is one leg of a split transfer: the request to send
+// through Provider and the quote it was priced with
+type PlannedTransfer struct {
+	Provider string
+	Request  TransactionRequest
+	Quote    *RemittanceQuote
+}
+
+// splitCandidate is a provider that can take part of a split, with its
+// corridor limits and a quote used for ranking
+type splitCandidate struct {
+	provider RemittanceProvider
+	limit    corridorLimit
+	quote    *RemittanceQuote
+	amount   float64
+}
+
+// SplitTransfer proposes how to divide req.Amount across the providers
+// serving its corridor when no single provider's per-transfer limit covers
+// it. Each provider is used at most once and is filled up to its limit in
+// the order strategy ranks them. Nothing is sent: pass the plan to
+// ExecuteSplit. ErrSplitUncoverable is returned when the providers'
+// combined limits can't cover the amount.
+func (rh *RemittanceHub) SplitTransfer(ctx context.Context, req TransactionRequest, strategy SplitStrategy) ([]PlannedTransfer, error) {
+	if err := req.Validate(); err != nil {
+		return nil, err
+	}
+	country := req.Recipient.Address.CountryCode
+
+	var candidates []*splitCandidate
+	providers, excluded := rh.GetEligibleProviders(req.sendingCountry(), country, req.FromCurrency, req.ToCurrency, req.PayoutNetwork, req.Sender)
+	if len(providers) == 0 && len(excluded) > 0 {
+		return nil, ineligibleSender(excluded)
+	}
+	for _, provider := range providers {
+		if !fundsFrom(provider, req) || !paysWith(provider, req) {
+			continue
+		}
+		min, max, err := provider.GetCorridorLimits(ctx, req.FromCurrency, req.ToCurrency, country)
+		if err != nil {
+			log.Printf("Skipping %s: %v", provider.GetName(), err)
+			continue
+		}
+		limit := corridorLimit{min: min, max: max}
+		if limit.max == 0 || limit.max > req.Amount {
+			limit.max = req.Amount
+		}
+		if limit.max < limit.min {
+			continue
+		}
+		// Rank on a quote for the most this provider could take
+		candidates = append(candidates, &splitCandidate{provider: provider, limit: limit, amount: limit.max})
+	}
+	if len(candidates) == 0 {
+		return nil, fmt.Errorf("%w: %s to %s", ErrNoProvidersForCorridor, CurrencyPair{From: req.FromCurrency, To: req.ToCurrency}, country)
+	}
+
+	candidates = rh.quoteSplit(ctx, req, candidates)
+	rankSplit(candidates, strategy)
+
+	legs, ok := allocateSplit(candidates, req.Amount)
+	if !ok {
+		return nil, fmt.Errorf("%w: %.2f %s", ErrSplitUncoverable, req.Amount, req.FromCurrency)
+	}
+
+	// Re-quote each leg at its actual amount so the plan shows real prices
+	if quoted := rh.quoteSplit(ctx, req, legs); len(quoted) < len(legs) {
+		return nil, fmt.Errorf("%w: %d of %d split legs could not be quoted", ErrNoQuotes, len(legs)-len(quoted), len(legs))
+	}
+
+	plan := make([]PlannedTransfer, len(legs))
+	for i, leg := range legs {
+		legReq := req
+		legReq.Amount = leg.amount
+		legReq.Quote = leg.quote
+		if key := req.idempotencyKey(); key != "" {
+			legReq.IdempotencyKey = fmt.Sprintf("%s-%d", key, i+1)
+		}
+		plan[i] = PlannedTransfer{Provider: leg.provider.GetName(), Request: legReq, Quote: leg.quote}
+	}
+	return plan, nil
+}
+
+// quoteSplit quotes every candidate at its amount concurrently, dropping
+// those whose quote fails, and fills in effective costs
+func (rh *RemittanceHub) quoteSplit(ctx context.Context, req TransactionRequest, candidates []*splitCandidate) []*splitCandidate {
+	ctx, cancel := rh.withQuoteBudget(ctx)
+	defer cancel()
+
+	var wg sync.WaitGroup
+	for _, c := range candidates {
+		wg.Add(1)
+		go func(c *splitCandidate) {
+			defer wg.Done()
+			providerReq := req
+			providerReq.Amount = c.amount
+			providerCtx, cancel := providerQuoteContext(ctx)
+			defer cancel()
+			quote, err := rh.quoteProvider(providerCtx, c.provider, providerReq)
+			if err != nil {
+				log.Printf("Error getting quote from %s: %v", c.provider.GetName(), err)
+				c.quote = nil
+				return
+			}
+			c.quote = quote
+		}(c)
+	}
+	wg.Wait()
+
+	quoted := make([]*splitCandidate, 0, len(candidates))
+	quotes := make([]*RemittanceQuote, 0, len(candidates))
+	for _, c := range candidates {
+		if c.quote != nil {
+			quoted = append(quoted, c)
+			quotes = append(quotes, c.quote)
+		}
+	}
+	reference := bestQuotedRate(quotes)
+	if midMarket := rh.referenceRate(ctx, req); midMarket > 0 {
+		reference = midMarket
+	}
+	annotateEffectiveCost(quotes, reference)
+	return quoted
+}
+
+func rankSplit(candidates []*splitCandidate, strategy SplitStrategy) {
+	costPerUnit := func(c *splitCandidate) float64 {
+		return c.quote.EffectiveCost / c.quote.Amount
+	}
+	sort.SliceStable(candidates, func(i, j int) bool {
+		a, b := candidates[i], candidates[j]
+		if strategy == SplitFastest {
+			_, aMax, _ := a.quote.DeliveryWindow()
+			_, bMax, _ := b.quote.DeliveryWindow()
+			if aMax != bMax {
+				return aMax < bMax
+			}
+		}
+		return costPerUnit(a) < costPerUnit(b)
+	})
+}
+
+// allocateSplit fills candidates in order up to their limits until amount
+// is covered, setting each leg's amount and returning the legs used. A
+// remainder below the next provider's minimum is made up by taking the
+// shortfall from earlier legs that have room above their own minimum.
+func allocateSplit(candidates []*splitCandidate, amount float64) ([]*splitCandidate, bool) {
+	var legs []*splitCandidate
+	remaining := amount
+	for _, c := range candidates {
+		if remaining <= precisionEpsilon {
+			break
+		}
+		take := c.limit.max
+		if take > remaining {
+			take = remaining
+		}
+		if take < c.limit.min {
+			shortfall := c.limit.min - take
+			var spare float64
+			for _, leg := range legs {
+				spare += leg.amount - leg.limit.min
+			}
+			if spare < shortfall-precisionEpsilon {
+				continue
+			}
+			for _, leg := range legs {
+				give := math.Min(leg.amount-leg.limit.min, shortfall)
+				leg.amount -= give
+				shortfall -= give
+			}
+			take = c.limit.min
+		}
+		c.amount = take
+		legs = append(legs, c)
+		remaining = amount
+		for _, leg := range legs {
+			remaining -= leg.amount
+		}
+	}
+	return legs, remaining <= precisionEpsilon
+}
+
+// ExecuteSplit sends a plan from SplitTransfer leg by leg, stopping at the
+// first failure. The returned slice lines up with plan; legs before the
+// failure were sent and are not rolled back, so the caller must reconcile
+// them (e.g. re-plan the remainder).
+func (rh *RemittanceHub) ExecuteSplit(ctx context.Context, plan []PlannedTransfer) ([]*TransactionResponse, error) {
+	responses := make([]*TransactionResponse, len(plan))
+	for i, leg := range plan {
+		tx, err := rh.SendMoneyWithProvider(ctx, leg.Provider, leg.Request)
+		if err != nil {
+			return responses, fmt.Errorf("split leg %d of %d via %s: %w", i+1, len(plan), leg.Provider, err)
+		}
+		responses[i] = tx
+	}
+	return responses, nil
+}
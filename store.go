@@ -0,0 +1,193 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"log"
+	"sort"
+	"sync"
+	"time"
+)
+
+// ErrTransactionNotFound is returned by TransactionStore lookups that miss
+var ErrTransactionNotFound = errors.New("transaction not found")
+
+// TransactionRecord is what the hub persists for each send: the request,
+// which provider took it, and the latest known provider response.
+type TransactionRecord struct {
+	TransactionID string              `json:"transaction_id"`
+	Provider      string              `json:"provider"`
+	Reference     string              `json:"reference"`
+	Request       TransactionRequest  `json:"request"`
+	Response      TransactionResponse `json:"response"`
+	CreatedAt     time.Time           `json:"created_at"`
+	UpdatedAt     time.Time           `json:"updated_at"`
+}
+
+// TransactionFilter narrows List; zero-valued fields match everything
+type TransactionFilter struct {
+	Provider string
+	Status   TransactionStatus
+}
+
+func (f TransactionFilter) matches(r *TransactionRecord) bool {
+	return (f.Provider == "" || r.Provider == f.Provider) &&
+		(f.Status == "" || r.Response.Status == f.Status)
+}
+
+// TransactionStore persists transaction records so the mapping from our
+// Reference to the provider's TransactionID survives restarts.
+//
+// A SQL-backed store needs one table keyed by transaction_id with an index
+// on reference, e.g.
+//
+//	CREATE TABLE remittance_transactions (
+//	    transaction_id TEXT PRIMARY KEY,
+//	    provider       TEXT NOT NULL,
+//	    reference      TEXT NOT NULL,
+//	    status         TEXT NOT NULL,
+//	    request        JSONB NOT NULL,
+//	    response       JSONB NOT NULL,
+//	    created_at     TIMESTAMPTZ NOT NULL,
+//	    updated_at     TIMESTAMPTZ NOT NULL
+//	);
+//	CREATE INDEX ON remittance_transactions (reference);
+//
+// with Save as an upsert (INSERT ... ON CONFLICT (transaction_id) DO UPDATE)
+// and status/provider as real columns so List can filter in SQL.
+type TransactionStore interface {
+	// Save inserts or replaces the record with the same TransactionID
+	Save(ctx context.Context, record *TransactionRecord) error
+	GetByID(ctx context.Context, transactionID string) (*TransactionRecord, error)
+	GetByReference(ctx context.Context, reference string) (*TransactionRecord, error)
+	// List returns matching records, oldest first
+	List(ctx context.Context, filter TransactionFilter) ([]*TransactionRecord, error)
+}
+
+// InMemoryTransactionStore is a TransactionStore for tests and single-process
+// deployments. Records are copied in and out so callers can't mutate them.
+type InMemoryTransactionStore struct {
+	mu      sync.RWMutex
+	records map[string]*TransactionRecord
+}
+
+func NewInMemoryTransactionStore() *InMemoryTransactionStore {
+	return &InMemoryTransactionStore{records: make(map[string]*TransactionRecord)}
+}
+
+func (s *InMemoryTransactionStore) Save(ctx context.Context, record *TransactionRecord) error {
+	if record == nil || record.TransactionID == "" {
+		return errors.New("transaction record needs a TransactionID")
+	}
+	copied := *record
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.records[record.TransactionID] = &copied
+	return nil
+}
+
+func (s *InMemoryTransactionStore) GetByID(ctx context.Context, transactionID string) (*TransactionRecord, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	record, ok := s.records[transactionID]
+	if !ok {
+		return nil, ErrTransactionNotFound
+	}
+	copied := *record
+	return &copied, nil
+}
+
+func (s *InMemoryTransactionStore) GetByReference(ctx context.Context, reference string) (*TransactionRecord, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	for _, record := range s.records {
+		if record.Reference == reference {
+			copied := *record
+			return &copied, nil
+		}
+	}
+	return nil, ErrTransactionNotFound
+}
+
+func (s *InMemoryTransactionStore) List(ctx context.Context, filter TransactionFilter) ([]*TransactionRecord, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var records []*TransactionRecord
+	for _, record := range s.records {
+		if filter.matches(record) {
+			copied := *record
+			records = append(records, &copied)
+		}
+	}
+	sort.Slice(records, func(i, j int) bool {
+		return records[i].CreatedAt.Before(records[j].CreatedAt)
+	})
+	return records, nil
+}
+
+// SetStore makes the hub persist every successful send and status update.
+// Call it before serving traffic; nil disables persistence.
+func (rh *RemittanceHub) SetStore(store TransactionStore) {
+	rh.store = store
+}
+
+// recordSend persists a new transfer. A store failure is logged rather than
+// returned: the provider has already accepted the transfer, and surfacing an
+// error would invite a retry and a duplicate payment.
+func (rh *RemittanceHub) recordSend(ctx context.Context, providerName string, req TransactionRequest, tx *TransactionResponse) {
+	if rh.store == nil || tx == nil {
+		return
+	}
+	now := time.Now()
+	record := &TransactionRecord{
+		TransactionID: tx.TransactionID,
+		Provider:      providerName,
+		Reference:     req.Reference,
+		Request:       req,
+		Response:      *tx,
+		CreatedAt:     now,
+		UpdatedAt:     now,
+	}
+	if err := rh.store.Save(ctx, record); err != nil {
+		log.Printf("Error recording %s transaction %s: %v", providerName, tx.TransactionID, err)
+	}
+}
+
+// recordStatus updates the stored response after a status check, keeping
+// the original request and creation time.
+func (rh *RemittanceHub) recordStatus(ctx context.Context, providerName string, tx *TransactionResponse) {
+	if rh.store == nil || tx == nil {
+		return
+	}
+	now := time.Now()
+	record, err := rh.store.GetByID(ctx, tx.TransactionID)
+	if err != nil {
+		if !errors.Is(err, ErrTransactionNotFound) {
+			log.Printf("Error loading %s transaction %s: %v", providerName, tx.TransactionID, err)
+			return
+		}
+		record = &TransactionRecord{TransactionID: tx.TransactionID, Provider: providerName, CreatedAt: now}
+	}
+
+	record.applyStatus(tx, now)
+	if err := rh.store.Save(ctx, record); err != nil {
+		log.Printf("Error recording %s transaction %s: %v", providerName, tx.TransactionID, err)
+	}
+}
+
+// applyStatus replaces the stored response with a fresh status lookup.
+// Status lookups return a sparse response, so known amounts are kept.
+func (r *TransactionRecord) applyStatus(tx *TransactionResponse, now time.Time) {
+	updated := *tx
+	if updated.Amount == 0 {
+		updated.Amount = r.Response.Amount
+		updated.Fee = r.Response.Fee
+		updated.ExchangeRate = r.Response.ExchangeRate
+	}
+	r.Response = updated
+	r.UpdatedAt = now
+}
@@ -0,0 +1,120 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// SetRateSource designates the provider SubscribeRates polls. Empty (the
+// default) picks whichever provider offers the best rate at subscription
+// time. Call it before serving traffic.
+func (rh *RemittanceHub) SetRateSource(providerName string) {
+	rh.rateSource = providerName
+}
+
+// RateUpdate is one delivery from SubscribeRates: either a fresh Rate or
+// the Err from a poll that failed
+type RateUpdate struct {
+	Rate *ExchangeRate
+	Err  error
+}
+
+// SubscribeRates polls one provider's rate for the pair every interval and
+// delivers each result on the channel, starting immediately. A failed poll
+// is delivered as an update with Err set and polling continues. The channel
+// closes when ctx is done or the hub is closed.
+//
+// Errors travel on the same channel as the rates rather than a separate
+// error channel. A consumer ranging over one channel sees each failure in
+// order with the rates around it, can't stall the poller by leaving a
+// second channel unread, and loses nothing to a full error buffer; it also
+// keeps the call to a (channel, error) pair, where the error is only for a
+// subscription that couldn't start.
+func (rh *RemittanceHub) SubscribeRates(ctx context.Context, from, to Currency, interval time.Duration) (<-chan RateUpdate, error) {
+	if interval <= 0 {
+		return nil, errors.New("rate subscription interval must be positive")
+	}
+	provider, first, err := rh.rateSubscriptionSource(ctx, from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	updates := make(chan RateUpdate, 1)
+	updates <- RateUpdate{Rate: first}
+
+	go func() {
+		defer close(updates)
+
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-rh.done:
+				return
+			case <-ticker.C:
+			}
+
+			var update RateUpdate
+			update.Rate, update.Err = provider.GetExchangeRates(ctx, from, to)
+			if update.Err != nil {
+				if ctx.Err() != nil {
+					return
+				}
+				update.Rate = nil
+				update.Err = fmt.Errorf("%s rate %s: %w", provider.GetName(), CurrencyPair{From: from, To: to}, update.Err)
+			}
+			select {
+			case updates <- update:
+			case <-ctx.Done():
+				return
+			case <-rh.done:
+				return
+			}
+		}
+	}()
+	return updates, nil
+}
+
+// rateSubscriptionSource returns the provider to poll and its current rate:
+// the designated source if set, else the provider with the best rate now
+func (rh *RemittanceHub) rateSubscriptionSource(ctx context.Context, from, to Currency) (RemittanceProvider, *ExchangeRate, error) {
+	if rh.rateSource != "" {
+		provider, err := rh.findProvider(rh.rateSource)
+		if err != nil {
+			return nil, nil, err
+		}
+		rate, err := provider.GetExchangeRates(ctx, from, to)
+		if err != nil {
+			return nil, nil, err
+		}
+		return provider, rate, nil
+	}
+
+	var (
+		best     RemittanceProvider
+		bestRate *ExchangeRate
+		errs     []error
+	)
+	providers := rh.GetAvailableProviders("", "", from, to)
+	for _, provider := range providers {
+		rate, err := provider.GetExchangeRates(ctx, from, to)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", provider.GetName(), err))
+			continue
+		}
+		if bestRate == nil || rate.Rate > bestRate.Rate {
+			best, bestRate = provider, rate
+		}
+	}
+	if best == nil {
+		if len(errs) == 0 {
+			return nil, nil, fmt.Errorf("%w: %s", ErrNoProvidersForCorridor, CurrencyPair{From: from, To: to})
+		}
+		return nil, nil, fmt.Errorf("no provider returned a rate for %s: %w", CurrencyPair{From: from, To: to}, errors.Join(errs...))
+	}
+	return best, bestRate, nil
+}
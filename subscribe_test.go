@@ -0,0 +1,85 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// flakyRates fails every other rate poll after the first
+type flakyRates struct {
+	*SimulatedProvider
+	calls atomic.Int32
+}
+
+func (f *flakyRates) GetExchangeRates(ctx context.Context, from, to Currency) (*ExchangeRate, error) {
+	if f.calls.Add(1)%2 == 0 {
+		return nil, errors.New("rate feed unavailable")
+	}
+	return f.SimulatedProvider.GetExchangeRates(ctx, from, to)
+}
+
+func TestSubscribeRatesDeliversErrorsInBand(t *testing.T) {
+	flaky := &flakyRates{SimulatedProvider: newSimulatedRemitly()}
+	hub := NewRemittanceHub()
+	hub.AddProvider(flaky)
+	hub.SetRateSource(flaky.GetName())
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	updates, err := hub.SubscribeRates(ctx, USD, INR, time.Millisecond)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var rates, failures int
+	for rates < 3 || failures < 1 {
+		select {
+		case update, ok := <-updates:
+			if !ok {
+				t.Fatal("channel closed while polling")
+			}
+			if (update.Rate == nil) == (update.Err == nil) {
+				t.Fatalf("update %+v: want exactly one of Rate and Err", update)
+			}
+			if update.Err != nil {
+				failures++
+			} else {
+				rates++
+			}
+		case <-time.After(time.Second):
+			t.Fatalf("stalled after %d rates and %d errors", rates, failures)
+		}
+	}
+
+	cancel()
+	for range updates {
+	}
+}
+
+func TestSubscribeRatesClosesWithHub(t *testing.T) {
+	hub := NewRemittanceHub()
+	hub.AddProvider(newSimulatedRemitly())
+	updates, err := hub.SubscribeRates(context.Background(), USD, INR, time.Millisecond)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if first := <-updates; first.Rate == nil {
+		t.Fatalf("first update = %+v, want the current rate", first)
+	}
+	hub.Close()
+
+	timeout := time.After(time.Second)
+	for {
+		select {
+		case _, ok := <-updates:
+			if !ok {
+				return
+			}
+		case <-timeout:
+			t.Fatal("channel still open after Close")
+		}
+	}
+}
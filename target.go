@@ -0,0 +1,146 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"math"
+	"sync"
+)
+
+// targetQuoteAttempts caps the re-quotes spent per provider converging on
+// a target amount. Fees linear in the amount converge in one or two.
+const targetQuoteAttempts = 4
+
+// GetBestQuoteForTarget finds the provider that delivers req.TargetAmount
+// to the recipient for the least cost to the sender. req.Amount is ignored:
+// each provider is quoted at the send amount its own rate and fees need to
+// pay out at least TargetAmount, and the cheapest result wins. This differs
+// from GetBestQuoteBy(LowestCost), which compares costs for a fixed send
+// amount that leaves each recipient with a different sum.
+func (rh *RemittanceHub) GetBestQuoteForTarget(ctx context.Context, req TransactionRequest) (*RemittanceQuote, error) {
+	if req.TargetAmount <= 0 {
+		return nil, &ValidationError{Field: "target_amount", Err: errors.New("must be positive")}
+	}
+	pair := CurrencyPair{From: req.FromCurrency, To: req.ToCurrency}
+
+	// Probe every provider at the send amount an indicative rate suggests,
+	// which also runs the usual eligibility and corridor limit checks
+	rate := rh.referenceRate(ctx, req)
+	if rate <= 0 {
+		rates, err := rh.CompareRates(ctx, req.FromCurrency, req.ToCurrency)
+		if err != nil {
+			return nil, err
+		}
+		rate = rates[0].Rate
+	}
+	probe := req
+	probe.Amount = ceilToMinorUnit(req.TargetAmount/rate, req.FromCurrency)
+	first, _, err := rh.collectQuotes(ctx, probe, QuoteOptions{})
+	if len(first) == 0 {
+		if err == nil {
+			err = fmt.Errorf("%w: %s", ErrNoQuotes, pair)
+		}
+		return nil, err
+	}
+
+	ctx, cancel := rh.withQuoteBudget(ctx)
+	defer cancel()
+
+	quotes := make([]*RemittanceQuote, len(first))
+	var wg sync.WaitGroup
+	for i, quote := range first {
+		provider, err := rh.findProvider(quote.Provider)
+		if err != nil {
+			continue
+		}
+		wg.Add(1)
+		go func(i int, provider RemittanceProvider, quote *RemittanceQuote) {
+			defer wg.Done()
+			solved, err := rh.quoteForTarget(ctx, provider, req, quote)
+			if err != nil {
+				log.Printf("Error quoting %s for %.2f %s received: %v", provider.GetName(), req.TargetAmount, req.ToCurrency, err)
+				return
+			}
+			quotes[i] = solved
+		}(i, provider, quote)
+	}
+	wg.Wait()
+
+	solved := quotes[:0]
+	for _, quote := range quotes {
+		if quote != nil {
+			solved = append(solved, quote)
+		}
+	}
+	if len(solved) == 0 {
+		return nil, fmt.Errorf("%w: no provider could deliver %.2f %s", ErrNoQuotes, req.TargetAmount, req.ToCurrency)
+	}
+
+	// Costs are only comparable once every quote is for the target, so the
+	// hub's annotations are recomputed on the solved quotes
+	rh.annotateQuotes(ctx, solved, req)
+	rh.sortQuotes(solved, req, LowestCost)
+	return solved[0], nil
+}
+
+// quoteForTarget re-quotes provider until its quote pays out at least
+// req.TargetAmount for close to the smallest send amount that does. Each
+// step moves the send amount along the line through the last two quotes,
+// starting from sending nothing for nothing, so flat and percentage fees
+// are both accounted for. Each re-quote is checked against the provider's
+// funding options and corridor limits as GetQuotes does.
+func (rh *RemittanceHub) quoteForTarget(ctx context.Context, provider RemittanceProvider, req TransactionRequest, quote *RemittanceQuote) (*RemittanceQuote, error) {
+	target := req.TargetAmount
+	// Overshooting by less than one minor unit of send amount buys, plus
+	// the payout rounding step, is as close as a quote can get
+	step := math.Pow10(-req.ToCurrency.DecimalPlaces())
+	if req.payoutMethod() == PaymentCash || req.RoundingMode == RoundingFloorWhole {
+		step = 1
+	}
+	minor := math.Pow10(-req.FromCurrency.DecimalPlaces())
+
+	var prevAmount, prevReceived float64
+	for attempt := 0; ; attempt++ {
+		slope := quote.ExchangeRate
+		if quote.Amount != prevAmount && quote.ReceivedAmount != prevReceived {
+			slope = (quote.ReceivedAmount - prevReceived) / (quote.Amount - prevAmount)
+		}
+		shortfall := target - quote.ReceivedAmount
+		if shortfall <= 0 && -shortfall <= slope*minor+step {
+			return quote, nil
+		}
+		if attempt == targetQuoteAttempts || slope <= 0 {
+			if shortfall <= 0 {
+				return quote, nil
+			}
+			return nil, fmt.Errorf("quote for %.2f %s pays out only %.2f %s", quote.Amount, req.FromCurrency, quote.ReceivedAmount, req.ToCurrency)
+		}
+
+		prevAmount, prevReceived = quote.Amount, quote.ReceivedAmount
+		next := req
+		next.Amount = ceilToMinorUnit(quote.Amount+shortfall/slope, req.FromCurrency)
+		if next.Amount == quote.Amount {
+			next.Amount += minor
+		}
+		// Re-quotes face the same funding and corridor limit checks as the
+		// probe, so a solved amount above the provider's maximum is refused
+		// here rather than by the provider on send
+		var err error
+		quote, err = rh.quoteEligible(ctx, provider, next)
+		if err != nil {
+			return nil, err
+		}
+		if quote == nil {
+			return nil, fmt.Errorf("send amount %.2f %s is outside %s's limits", next.Amount, req.FromCurrency, provider.GetName())
+		}
+	}
+}
+
+// ceilToMinorUnit rounds amount up to the currency's precision, so a
+// solved send amount never falls short by a fraction of a cent
+func ceilToMinorUnit(amount float64, c Currency) float64 {
+	scale := math.Pow10(c.DecimalPlaces())
+	return math.Ceil(amount*scale-precisionEpsilon) / scale
+}
@@ -0,0 +1,75 @@
+package main
+
+import "context"
+
+// Attribute is a key/value pair recorded on a span
+type Attribute struct {
+	Key   string
+	Value interface{}
+}
+
+// Attr is shorthand for building an Attribute
+func Attr(key string, value interface{}) Attribute {
+	return Attribute{Key: key, Value: value}
+}
+
+// Tracer is the slice of an OpenTelemetry tracer the hub relies on. To export
+// to OTel, wrap otel.Tracer("xchngpassport") so Start calls tracer.Start with
+// the attributes converted to attribute.KeyValue; the returned context then
+// carries the OTel span and child spans nest under it automatically.
+//
+// When no Tracer is configured the hub uses a no-op implementation.
+type Tracer interface {
+	Start(ctx context.Context, spanName string, attrs ...Attribute) (context.Context, Span)
+}
+
+// Span is the slice of an OpenTelemetry span the hub relies on
+type Span interface {
+	SetAttributes(attrs ...Attribute)
+	RecordError(err error)
+	End()
+}
+
+type noopTracer struct{}
+
+func (noopTracer) Start(ctx context.Context, spanName string, attrs ...Attribute) (context.Context, Span) {
+	return ctx, noopSpan{}
+}
+
+type noopSpan struct{}
+
+func (noopSpan) SetAttributes(attrs ...Attribute) {}
+func (noopSpan) RecordError(err error)            {}
+func (noopSpan) End()                             {}
+
+// Span and attribute names used by the hub
+const (
+	spanGetQuotes            = "remittance.GetQuotes"
+	spanGetQuote             = "remittance.GetQuote"
+	spanSendMoney            = "remittance.SendMoney"
+	spanGetTransactionStatus = "remittance.GetTransactionStatus"
+
+	attrProvider      = "remittance.provider"
+	attrFromCurrency  = "remittance.from_currency"
+	attrToCurrency    = "remittance.to_currency"
+	attrAmount        = "remittance.amount"
+	attrStatus        = "remittance.status"
+	attrTransactionID = "remittance.transaction_id"
+	attrQuoteCount    = "remittance.quote_count"
+)
+
+func requestAttributes(req TransactionRequest) []Attribute {
+	return []Attribute{
+		Attr(attrFromCurrency, string(req.FromCurrency)),
+		Attr(attrToCurrency, string(req.ToCurrency)),
+		Attr(attrAmount, req.Amount),
+	}
+}
+
+// endSpan records err (if any) and ends the span
+func endSpan(span Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+	}
+	span.End()
+}
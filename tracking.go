@@ -0,0 +1,118 @@
+package main
+
+import "strings"
+
+// TrackingStep is a provider-agnostic stage of a transfer on its way to the
+// recipient, for in-app progress trackers. Each provider maps its own
+// states onto these.
+type TrackingStep string
+
+const (
+	// StepSubmitted: the provider has the transfer but not the money yet
+	StepSubmitted TrackingStep = "SUBMITTED"
+	// StepFundsReceived: the sender's payment has arrived
+	StepFundsReceived TrackingStep = "FUNDS_RECEIVED"
+	// StepProcessing: converting and paying out, or awaiting collection
+	StepProcessing TrackingStep = "PROCESSING"
+	// StepPaidOut: the recipient has the money
+	StepPaidOut TrackingStep = "PAID_OUT"
+)
+
+var trackingSteps = map[TrackingStep]struct {
+	percent int
+	label   string
+}{
+	StepSubmitted:     {10, "Transfer submitted"},
+	StepFundsReceived: {40, "Funds received"},
+	StepProcessing:    {70, "Processing"},
+	StepPaidOut:       {100, "Paid out"},
+}
+
+// Percent is how far along the transfer is at s, 0 for an unknown step
+func (s TrackingStep) Percent() int {
+	return trackingSteps[s].percent
+}
+
+// Label is a short description of s to show the sender
+func (s TrackingStep) Label() string {
+	return trackingSteps[s].label
+}
+
+// TrackingProgress is a normalized progress indicator for a transfer
+type TrackingProgress struct {
+	Step    TrackingStep `json:"step"`
+	Percent int          `json:"percent"`
+	Label   string       `json:"label"`
+}
+
+func progressAt(step TrackingStep) *TrackingProgress {
+	if _, ok := trackingSteps[step]; !ok {
+		return nil
+	}
+	return &TrackingProgress{Step: step, Percent: step.Percent(), Label: step.Label()}
+}
+
+// trackingProgress maps a provider's raw state through its steps table,
+// matching case-insensitively; states missing from the table yield nil
+func trackingProgress(steps map[string]TrackingStep, state string) *TrackingProgress {
+	return progressAt(steps[strings.ToLower(state)])
+}
+
+// fillProgress makes Progress agree with Status. Transfers that failed,
+// were cancelled or are being refunded have no progress, since they won't
+// reach the recipient. Completed transfers are paid out whatever the
+// provider's state said, and pending ones from providers with no mapping
+// for their state are shown as submitted.
+func (tx *TransactionResponse) fillProgress() {
+	switch tx.Status {
+	case StatusCompleted:
+		tx.Progress = progressAt(StepPaidOut)
+	case StatusPending:
+		if tx.Progress == nil || tx.Progress.Step == StepPaidOut {
+			tx.Progress = progressAt(StepSubmitted)
+		}
+	default:
+		tx.Progress = nil
+	}
+}
+
+// Provider state-to-step tables, keyed by lower-cased state. States that
+// map to a terminal TransactionStatus other than completed are left out.
+var (
+	wiseTrackingSteps = map[string]TrackingStep{
+		"incoming_payment_waiting":   StepSubmitted,
+		"incoming_payment_initiated": StepSubmitted,
+		"processing":                 StepFundsReceived,
+		"funds_converted":            StepProcessing,
+		"outgoing_payment_sent":      StepPaidOut,
+	}
+	remitlyTrackingSteps = map[string]TrackingStep{
+		"created":    StepSubmitted,
+		"on_hold":    StepSubmitted,
+		"funded":     StepFundsReceived,
+		"processing": StepProcessing,
+		"in_transit": StepProcessing,
+		"delivered":  StepPaidOut,
+		"completed":  StepPaidOut,
+	}
+	worldRemitTrackingSteps = map[string]TrackingStep{
+		"created":              StepSubmitted,
+		"funded":               StepFundsReceived,
+		"processing":           StepProcessing,
+		"ready_for_collection": StepProcessing,
+		"paid":                 StepPaidOut,
+		"collected":            StepPaidOut,
+		"completed":            StepPaidOut,
+	}
+	xoomTrackingSteps = map[string]TrackingStep{
+		"scheduled":        StepSubmitted,
+		"created":          StepSubmitted,
+		"pending_funding":  StepSubmitted,
+		"funded":           StepFundsReceived,
+		"processing":       StepProcessing,
+		"in_transit":       StepProcessing,
+		"ready_for_pickup": StepProcessing,
+		"completed":        StepPaidOut,
+		"paid_out":         StepPaidOut,
+	}
+)
@@ -0,0 +1,70 @@
+package main
+
+import (
+	"net"
+	"net/http"
+	"time"
+)
+
+// TransportOptions tunes connection reuse for provider HTTP calls. Zero
+// fields take the defaults below, which suit a few hosts called at high
+// concurrency; net/http's own default keeps only 2 idle connections per
+// host, so bursts of quotes re-dial and re-handshake on almost every call.
+type TransportOptions struct {
+	// MaxIdleConns caps idle connections across all hosts (default 100)
+	MaxIdleConns int `json:"max_idle_conns,omitempty"`
+	// MaxIdleConnsPerHost is how many idle connections each provider keeps
+	// for reuse (default 32)
+	MaxIdleConnsPerHost int `json:"max_idle_conns_per_host,omitempty"`
+	// MaxConnsPerHost caps all connections to one provider; 0 is no limit
+	MaxConnsPerHost int `json:"max_conns_per_host,omitempty"`
+	// IdleConnTimeout closes connections idle this long (default 90s)
+	IdleConnTimeout Duration `json:"idle_conn_timeout,omitempty"`
+	// KeepAlive is the TCP keep-alive probe interval (default 30s)
+	KeepAlive Duration `json:"keep_alive,omitempty"`
+	// DisableKeepAlives opens a new connection for every request
+	DisableKeepAlives bool `json:"disable_keep_alives,omitempty"`
+}
+
+func (o TransportOptions) withDefaults() TransportOptions {
+	if o.MaxIdleConns <= 0 {
+		o.MaxIdleConns = 100
+	}
+	if o.MaxIdleConnsPerHost <= 0 {
+		o.MaxIdleConnsPerHost = 32
+	}
+	if o.IdleConnTimeout <= 0 {
+		o.IdleConnTimeout = Duration(90 * time.Second)
+	}
+	if o.KeepAlive <= 0 {
+		o.KeepAlive = Duration(30 * time.Second)
+	}
+	return o
+}
+
+// NewTransport builds an http.Transport from opts, keeping net/http's
+// defaults for proxies, TLS and HTTP/2
+func NewTransport(opts TransportOptions) *http.Transport {
+	opts = opts.withDefaults()
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.DialContext = (&net.Dialer{
+		Timeout:   10 * time.Second,
+		KeepAlive: time.Duration(opts.KeepAlive),
+	}).DialContext
+	transport.MaxIdleConns = opts.MaxIdleConns
+	transport.MaxIdleConnsPerHost = opts.MaxIdleConnsPerHost
+	transport.MaxConnsPerHost = opts.MaxConnsPerHost
+	transport.IdleConnTimeout = time.Duration(opts.IdleConnTimeout)
+	transport.DisableKeepAlives = opts.DisableKeepAlives
+	return transport
+}
+
+// sharedTransport is the default transport of every provider client, so
+// connection pools are shared instead of each provider dialing its own
+var sharedTransport = NewTransport(TransportOptions{})
+
+// newProviderClient returns a client on sharedTransport with timeout as
+// the whole-request limit
+func newProviderClient(timeout time.Duration) *http.Client {
+	return &http.Client{Timeout: timeout, Transport: sharedTransport}
+}
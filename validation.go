@@ -0,0 +1,335 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+	"sync"
+	"time"
+)
+
+// CurrencyInfo holds per-currency metadata needed to validate amounts
+type CurrencyInfo struct {
+	Code          Currency
+	DecimalPlaces int
+}
+
+// currencyMu guards currencyInfo, which RegisterCurrency may change while
+// requests read it
+var currencyMu sync.RWMutex
+
+var currencyInfo = map[Currency]CurrencyInfo{
+	USD: {Code: USD, DecimalPlaces: 2},
+	EUR: {Code: EUR, DecimalPlaces: 2},
+	GBP: {Code: GBP, DecimalPlaces: 2},
+	INR: {Code: INR, DecimalPlaces: 2},
+	PHP: {Code: PHP, DecimalPlaces: 2},
+	MXN: {Code: MXN, DecimalPlaces: 2},
+	JPY: {Code: JPY, DecimalPlaces: 0},
+}
+
+// Info returns the metadata for c, if it is a currency we know about
+func (c Currency) Info() (CurrencyInfo, bool) {
+	currencyMu.RLock()
+	defer currencyMu.RUnlock()
+	info, ok := currencyInfo[c]
+	return info, ok
+}
+
+// DecimalPlaces returns the number of minor-unit digits c allows,
+// defaulting to 2 for currencies missing from the table.
+func (c Currency) DecimalPlaces() int {
+	if info, ok := c.Info(); ok {
+		return info.DecimalPlaces
+	}
+	return 2
+}
+
+// PrecisionPolicy decides what happens to an amount with more fractional
+// digits than its currency allows.
+type PrecisionPolicy int
+
+const (
+	// PrecisionReject fails validation (e.g. 1000.50 JPY)
+	PrecisionReject PrecisionPolicy = iota
+	// PrecisionRound rounds half away from zero to the allowed precision
+	PrecisionRound
+)
+
+// DefaultPrecisionPolicy is applied by TransactionRequest.Validate
+var DefaultPrecisionPolicy = PrecisionReject
+
+// precisionEpsilon absorbs binary float noise such as 0.1+0.2 when deciding
+// whether an amount already sits on a minor-unit boundary.
+const precisionEpsilon = 1e-6
+
+// NormalizeAmount checks amount against the precision of currency and
+// either rounds it or returns an error, according to policy.
+func NormalizeAmount(amount float64, currency Currency, policy PrecisionPolicy) (float64, error) {
+	places := currency.DecimalPlaces()
+	scale := math.Pow10(places)
+	scaled := amount * scale
+	rounded := math.Round(scaled)
+
+	if math.Abs(scaled-rounded) < precisionEpsilon || policy == PrecisionRound {
+		return rounded / scale, nil
+	}
+	return 0, fmt.Errorf("amount %v has more than %d decimal places allowed for %s", amount, places, currency)
+}
+
+// Validate checks the request before it reaches any provider. With
+// DefaultPrecisionPolicy set to PrecisionRound, Amount is rounded in place.
+// The recipient's CountryCode is upper-cased, or derived from Country when
+// empty, and must agree with Country. Bank details are checked unless the
+// payout is cash pickup or a wallet.
+func (r *TransactionRequest) Validate() error {
+	if r.Amount <= 0 {
+		return &ValidationError{Field: "amount", Err: errors.New("must be positive")}
+	}
+	if r.FromCurrency == "" || r.ToCurrency == "" {
+		return &ValidationError{Field: "currency", Err: errors.New("from and to currencies are required")}
+	}
+
+	if r.ScheduledFor != nil && !r.ScheduledFor.After(time.Now()) {
+		return &ValidationError{Field: "scheduled_for", Err: errors.New("must be in the future")}
+	}
+
+	amount, err := NormalizeAmount(r.Amount, r.FromCurrency, DefaultPrecisionPolicy)
+	if err != nil {
+		return &ValidationError{Field: "amount", Err: err}
+	}
+	r.Amount = amount
+
+	if err := r.Recipient.Address.validateCountry(); err != nil {
+		return err
+	}
+
+	if r.FundingMethod != "" && r.FundingMethod != PaymentBankTransfer && r.FundingMethod != PaymentCard {
+		return &ValidationError{Field: "funding_method", Err: fmt.Errorf("%s is a payout method; fund by %s or %s", r.FundingMethod, PaymentBankTransfer, PaymentCard)}
+	}
+
+	// Only bank deposits pay into the account the bank details name
+	if r.payoutMethod() == PaymentBankTransfer {
+		if err := ValidateBankDetails(r.Recipient, r.ToCurrency); err != nil {
+			return &ValidationError{Field: "recipient.bank_details", Err: err}
+		}
+	}
+
+	return nil
+}
+
+// validateCountry checks CountryCode, which routes the transfer, is an ISO
+// 3166-1 alpha-2 code naming the same country as Country. Country names it
+// doesn't recognise are let through; an empty CountryCode is filled in from
+// Country when it can be.
+func (a *Address) validateCountry() error {
+	a.CountryCode = strings.ToUpper(strings.TrimSpace(a.CountryCode))
+	named, known := CountryCodeFromName(a.Country)
+	if a.CountryCode == "" {
+		if known {
+			a.CountryCode = named
+		}
+		return nil
+	}
+	if !IsCountryCode(a.CountryCode) {
+		return &ValidationError{Field: "recipient.address.country_code", Err: fmt.Errorf("%q is not an ISO 3166-1 alpha-2 code", a.CountryCode)}
+	}
+	if known && named != a.CountryCode {
+		return &ValidationError{Field: "recipient.address.country_code", Err: fmt.Errorf("%s is %s, but country is %q", a.CountryCode, CountryName(a.CountryCode), a.Country)}
+	}
+	return nil
+}
+
+// Recipient.BankDetails keys understood by the per-country validation
+const (
+	BankFieldAccountNumber = "account_number"
+	BankFieldRoutingNumber = "routing_number"
+	BankFieldIFSC          = "ifsc"
+	BankFieldIBAN          = "iban"
+	BankFieldBIC           = "bic"
+)
+
+// euroAreaCountries use IBAN+BIC for euro bank payouts
+var euroAreaCountries = map[string]bool{
+	"AT": true, "BE": true, "CY": true, "DE": true, "EE": true, "ES": true,
+	"FI": true, "FR": true, "GR": true, "HR": true, "IE": true, "IT": true,
+	"LT": true, "LU": true, "LV": true, "MT": true, "NL": true, "PT": true,
+	"SI": true, "SK": true,
+}
+
+// RequiredBankFields lists the BankDetails keys a bank payout needs for the
+// recipient's country. When country is empty the destination currency is
+// used instead (INR->India, EUR->euro area, USD->US). Corridors without
+// rules return nil.
+func RequiredBankFields(to Currency, country string) []string {
+	country = strings.ToUpper(country)
+	switch {
+	case country == "IN", country == "" && to == INR:
+		return []string{BankFieldIFSC, BankFieldAccountNumber}
+	case euroAreaCountries[country], country == "" && to == EUR:
+		return []string{BankFieldIBAN, BankFieldBIC}
+	case usPayout(to, country):
+		return []string{BankFieldRoutingNumber, BankFieldAccountNumber}
+	}
+	return nil
+}
+
+// usPayout reports whether a bank payout lands in the US, by country or,
+// when that is empty, by a USD destination currency
+func usPayout(to Currency, country string) bool {
+	country = strings.ToUpper(country)
+	return country == "US" || country == "" && to == USD
+}
+
+// MissingBankDetailsError reports which required BankDetails keys are absent
+type MissingBankDetailsError struct {
+	Country string
+	Missing []string
+}
+
+func (e *MissingBankDetailsError) Error() string {
+	return fmt.Sprintf("recipient bank details for %s missing: %s", e.Country, strings.Join(e.Missing, ", "))
+}
+
+// ValidateBankDetails checks the recipient has every bank field required
+// for a bank payout in their country (see RequiredBankFields) and that any
+// IBAN or BIC present is well formed. A routing number is only checked as
+// an ABA number for US payouts; other countries use the key for their own
+// bank codes.
+func ValidateBankDetails(recipient Recipient, to Currency) error {
+	country := recipient.Address.CountryCode
+	var missing []string
+	for _, key := range RequiredBankFields(to, country) {
+		if strings.TrimSpace(recipient.BankDetails[key]) == "" {
+			missing = append(missing, key)
+		}
+	}
+	if len(missing) > 0 {
+		if country == "" {
+			country = string(to)
+		}
+		return &MissingBankDetailsError{Country: country, Missing: missing}
+	}
+
+	for key, value := range recipient.BankDetails {
+		if key == BankFieldRoutingNumber && !usPayout(to, country) {
+			continue
+		}
+		if validate, ok := bankFieldValidators[key]; ok && value != "" {
+			if err := validate(value); err != nil {
+				return fmt.Errorf("recipient bank details: %w", err)
+			}
+		}
+	}
+	return nil
+}
+
+// ibanLengths holds the fixed IBAN length for common countries; others are
+// only checked against the 15-34 character bounds.
+var ibanLengths = map[string]int{
+	"AT": 20, "BE": 16, "CH": 21, "DE": 22, "ES": 24, "FI": 18, "FR": 27,
+	"GB": 22, "GR": 27, "IE": 22, "IT": 27, "LU": 20, "NL": 18, "PT": 25,
+}
+
+// ValidateIBAN checks an IBAN's structure and its ISO 7064 mod-97 check
+// digits. Spaces are ignored and letters may be lower case.
+func ValidateIBAN(iban string) error {
+	s := strings.ToUpper(strings.ReplaceAll(iban, " ", ""))
+	if len(s) < 15 || len(s) > 34 {
+		return fmt.Errorf("invalid IBAN %q: length %d outside 15-34", iban, len(s))
+	}
+	if !isUpperAlpha(s[:2]) || !isDigits(s[2:4]) {
+		return fmt.Errorf("invalid IBAN %q: must start with country code and check digits", iban)
+	}
+	if want, ok := ibanLengths[s[:2]]; ok && len(s) != want {
+		return fmt.Errorf("invalid IBAN %q: %s IBANs have %d characters", iban, s[:2], want)
+	}
+
+	// Move the first four characters to the end, map A-Z to 10-35 and take
+	// the remainder digit by digit to stay within int range
+	rearranged := s[4:] + s[:4]
+	remainder := 0
+	for _, r := range rearranged {
+		switch {
+		case r >= '0' && r <= '9':
+			remainder = (remainder*10 + int(r-'0')) % 97
+		case r >= 'A' && r <= 'Z':
+			remainder = (remainder*100 + int(r-'A'+10)) % 97
+		default:
+			return fmt.Errorf("invalid IBAN %q: unexpected character %q", iban, r)
+		}
+	}
+	if remainder != 1 {
+		return fmt.Errorf("invalid IBAN %q: checksum mismatch", iban)
+	}
+	return nil
+}
+
+// ValidateBIC checks a SWIFT/BIC code: 4-letter bank code, 2-letter country,
+// 2-character location and an optional 3-character branch.
+func ValidateBIC(bic string) error {
+	s := strings.ToUpper(strings.TrimSpace(bic))
+	if len(s) != 8 && len(s) != 11 {
+		return fmt.Errorf("invalid BIC %q: must be 8 or 11 characters", bic)
+	}
+	if !isUpperAlpha(s[:6]) {
+		return fmt.Errorf("invalid BIC %q: bank and country codes must be letters", bic)
+	}
+	if !isUpperAlnum(s[6:]) {
+		return fmt.Errorf("invalid BIC %q: location and branch must be letters or digits", bic)
+	}
+	return nil
+}
+
+// ValidateUSRouting checks a 9-digit ABA routing number against its
+// weighted (3, 7, 1) checksum.
+func ValidateUSRouting(routing string) error {
+	s := strings.TrimSpace(routing)
+	if len(s) != 9 || !isDigits(s) {
+		return fmt.Errorf("invalid routing number %q: must be 9 digits", routing)
+	}
+	weights := [3]int{3, 7, 1}
+	sum := 0
+	for i := 0; i < 9; i++ {
+		sum += int(s[i]-'0') * weights[i%3]
+	}
+	if sum%10 != 0 {
+		return fmt.Errorf("invalid routing number %q: checksum mismatch", routing)
+	}
+	return nil
+}
+
+// bankFieldValidators format-check BankDetails values once presence is confirmed
+var bankFieldValidators = map[string]func(string) error{
+	BankFieldIBAN:          ValidateIBAN,
+	BankFieldBIC:           ValidateBIC,
+	BankFieldRoutingNumber: ValidateUSRouting,
+}
+
+func isDigits(s string) bool {
+	for _, r := range s {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return s != ""
+}
+
+func isUpperAlpha(s string) bool {
+	for _, r := range s {
+		if r < 'A' || r > 'Z' {
+			return false
+		}
+	}
+	return s != ""
+}
+
+func isUpperAlnum(s string) bool {
+	for _, r := range s {
+		if (r < 'A' || r > 'Z') && (r < '0' || r > '9') {
+			return false
+		}
+	}
+	return s != ""
+}
@@ -0,0 +1,58 @@
+package main
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRoutingNumberCheckedOnlyForUSPayouts(t *testing.T) {
+	tests := []struct {
+		name    string
+		to      Currency
+		country string
+		routing string
+		wantErr bool
+	}{
+		{"US valid", USD, "US", "021000021", false},
+		{"US bad checksum", USD, "US", "021000022", true},
+		{"USD without country", USD, "", "021000022", true},
+		{"Canadian transit number", USD, "CA", "000412345", false},
+		{"Philippine bank code", PHP, "PH", "BDO-0123", false},
+	}
+	for _, tt := range tests {
+		recipient := Recipient{
+			Address:     Address{CountryCode: tt.country},
+			BankDetails: map[string]string{BankFieldRoutingNumber: tt.routing, BankFieldAccountNumber: "123456789"},
+		}
+		if err := ValidateBankDetails(recipient, tt.to); (err != nil) != tt.wantErr {
+			t.Errorf("%s: err = %v, want error %v", tt.name, err, tt.wantErr)
+		}
+	}
+}
+
+func TestScheduledForOptional(t *testing.T) {
+	req := testRequest()
+	data, err := json.Marshal(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(data), "scheduled_for") {
+		t.Errorf("unscheduled request marshalled with scheduled_for: %s", data)
+	}
+	if err := req.Validate(); err != nil {
+		t.Fatalf("unscheduled request: %v", err)
+	}
+
+	past := time.Now().Add(-time.Hour)
+	req.ScheduledFor = &past
+	if err := req.Validate(); err == nil {
+		t.Error("a schedule in the past passed validation")
+	}
+	future := time.Now().Add(24 * time.Hour)
+	req.ScheduledFor = &future
+	if err := req.Validate(); err != nil {
+		t.Errorf("a schedule in the future: %v", err)
+	}
+}
@@ -0,0 +1,122 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+)
+
+// WalletValidation is a provider's lookup of a mobile-money wallet (M-Pesa,
+// GCash) before paying into it
+type WalletValidation struct {
+	Exists bool `json:"exists"`
+	// RegisteredName is the wallet holder's name as the operator has it;
+	// some operators withhold it, leaving it empty
+	RegisteredName string `json:"registered_name,omitempty"`
+	// NameMatches is set when the wallet exists and RegisteredName is
+	// either withheld or the recipient's name, ignoring case, punctuation
+	// and spacing
+	NameMatches bool `json:"name_matches"`
+}
+
+// WalletValidator is implemented by providers that can look up a mobile
+// wallet by the recipient's phone number before sending
+type WalletValidator interface {
+	ValidateRecipientWallet(ctx context.Context, recipient Recipient) (WalletValidation, error)
+}
+
+// ValidateRecipientWallet asks the named provider whether the recipient's
+// mobile wallet exists and who it is registered to. Providers without a
+// lookup return ErrUnsupported.
+func (rh *RemittanceHub) ValidateRecipientWallet(ctx context.Context, providerName string, recipient Recipient) (WalletValidation, error) {
+	provider, err := rh.findProvider(providerName)
+	if err != nil {
+		return WalletValidation{}, err
+	}
+	return validateWallet(ctx, provider, recipient)
+}
+
+func validateWallet(ctx context.Context, provider RemittanceProvider, recipient Recipient) (WalletValidation, error) {
+	validator, ok := provider.(WalletValidator)
+	if !ok {
+		return WalletValidation{}, &UnsupportedError{Provider: provider.GetName(), Operation: "wallet validation"}
+	}
+	if recipient.Phone == "" {
+		return WalletValidation{}, &ValidationError{Field: "recipient.phone", Err: errors.New("required to look up a wallet")}
+	}
+	result, err := validator.ValidateRecipientWallet(ctx, recipient)
+	if err != nil {
+		return WalletValidation{}, err
+	}
+	result.NameMatches = result.Exists && (result.RegisteredName == "" || normalizeName(result.RegisteredName) == normalizeName(recipient.Name))
+	return result, nil
+}
+
+// SetWalletPrecheck makes wallet payouts look up the recipient's wallet
+// with providers that support it before sending. A wallet that doesn't
+// exist fails the send with ErrWalletNotFound; a name mismatch only adds a
+// warning to the transaction. Call it before serving traffic.
+func (rh *RemittanceHub) SetWalletPrecheck(enabled bool) {
+	rh.walletPrecheck = enabled
+}
+
+// precheckWallet runs the wallet lookup for req if enabled and returns a
+// warning for the transaction, if any. Lookup failures other than a missing
+// wallet are logged and let the send proceed, since the provider still
+// rejects bad wallets itself.
+func (rh *RemittanceHub) precheckWallet(ctx context.Context, provider RemittanceProvider, req TransactionRequest) (warning string, err error) {
+	if !rh.walletPrecheck || req.PaymentMethod != PaymentWallet {
+		return "", nil
+	}
+	result, err := validateWallet(ctx, provider, req.Recipient)
+	if errors.Is(err, ErrUnsupported) {
+		return "", nil
+	}
+	if err != nil {
+		log.Printf("Wallet precheck with %s skipped: %v", provider.GetName(), err)
+		return "", nil
+	}
+	if !result.Exists {
+		return "", fmt.Errorf("%w: %s has no wallet for %s", ErrWalletNotFound, provider.GetName(), req.Recipient.Name)
+	}
+	if !result.NameMatches {
+		return fmt.Sprintf("Wallet is registered to %q, not %q", result.RegisteredName, req.Recipient.Name), nil
+	}
+	return "", nil
+}
+
+// WorldRemit looks wallets up by phone number in the recipient's country
+func (wr *WorldRemitProvider) ValidateRecipientWallet(ctx context.Context, recipient Recipient) (WalletValidation, error) {
+	payload := map[string]interface{}{
+		"phoneNumber": recipient.Phone,
+		"countryCode": recipient.Address.CountryCode,
+	}
+	resp, err := wr.makeRequest(ctx, "POST", "/v1/wallets/validate", payload)
+	if err != nil {
+		if isNotFound(err) {
+			return WalletValidation{}, nil
+		}
+		return WalletValidation{}, err
+	}
+	defer resp.Body.Close()
+
+	var lookup struct {
+		Exists      bool   `json:"exists"`
+		AccountName string `json:"accountName"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&lookup); err != nil {
+		return WalletValidation{}, fmt.Errorf("worldremit wallet validation: %w", err)
+	}
+	return WalletValidation{Exists: lookup.Exists, RegisteredName: lookup.AccountName}, nil
+}
+
+// ValidateRecipientWallet looks the recipient's phone up in Wallets
+func (s *SimulatedProvider) ValidateRecipientWallet(ctx context.Context, recipient Recipient) (WalletValidation, error) {
+	if s.Wallets == nil {
+		return WalletValidation{}, &UnsupportedError{Provider: s.Name, Operation: "wallet validation"}
+	}
+	name, ok := s.Wallets[recipient.Phone]
+	return WalletValidation{Exists: ok, RegisteredName: name}, nil
+}
@@ -0,0 +1,64 @@
+package main
+
+import "fmt"
+
+// ProviderWeights biases quote ranking per route, for preferences cost
+// alone doesn't capture such as reliability or payout speed. A weight above
+// 1 favours a provider and below 1 penalises it; providers and routes not
+// listed weigh 1, so an empty ProviderWeights ranks on cost only.
+//
+// Costs are divided by the weight and received amounts multiplied by it: a
+// weight of 1.1 lets a provider win while up to ~10% more expensive.
+// FastestDelivery ignores weights.
+type ProviderWeights map[Route]map[string]float64
+
+// Validate rejects non-positive weights, which would invert the ranking
+func (pw ProviderWeights) Validate() error {
+	for route, providers := range pw {
+		for provider, weight := range providers {
+			if weight <= 0 {
+				return fmt.Errorf("provider weight for %s on %s %s->%s must be positive, got %v",
+					provider, route.Country, route.From, route.To, weight)
+			}
+		}
+	}
+	return nil
+}
+
+func (pw ProviderWeights) weight(route Route, provider string) float64 {
+	if weight, ok := pw[route][provider]; ok {
+		return weight
+	}
+	return 1
+}
+
+// SetProviderWeights installs per-route provider preferences. Call it before
+// serving traffic; nil restores cost-only ranking.
+func (rh *RemittanceHub) SetProviderWeights(weights ProviderWeights) error {
+	if err := weights.Validate(); err != nil {
+		return err
+	}
+	rh.weights = weights
+	return nil
+}
+
+// sortQuotes ranks quotes for req under strategy and the hub's weights
+func (rh *RemittanceHub) sortQuotes(quotes []*RemittanceQuote, req TransactionRequest, strategy SortStrategy) {
+	if len(rh.weights) == 0 {
+		sortQuotes(quotes, strategy)
+		return
+	}
+	route := Route{From: req.FromCurrency, To: req.ToCurrency, Country: req.Recipient.Address.CountryCode}
+	sortQuotesWeighted(quotes, strategy, func(provider string) float64 {
+		return rh.weights.weight(route, provider)
+	})
+}
+
+// weighCost scales a cost so a higher weight always makes it look smaller,
+// including for negative effective costs
+func weighCost(cost, weight float64) float64 {
+	if cost < 0 {
+		return cost * weight
+	}
+	return cost / weight
+}
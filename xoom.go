@@ -0,0 +1,771 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Xoom (PayPal's remittance service) Provider. Authenticates with the
+// OAuth2 client-credentials grant; tokens are cached until near expiry.
+type XoomProvider struct {
+	ClientID     string
+	ClientSecret string
+	BaseURL      string
+	// TokenSource overrides the default client-credentials flow
+	TokenSource TokenSource
+	// Identity is sent with every request (User-Agent, client ID headers)
+	Identity   ClientIdentity
+	client     *http.Client
+	middleware []Middleware
+
+	tokenOnce sync.Once
+}
+
+func NewXoomProvider(clientID, clientSecret string) *XoomProvider {
+	return &XoomProvider{
+		ClientID:     clientID,
+		ClientSecret: clientSecret,
+		BaseURL:      "https://api.paypal.com",
+		client:       newProviderClient(30 * time.Second),
+	}
+}
+
+func (x *XoomProvider) GetName() string {
+	return "Xoom"
+}
+
+func (x *XoomProvider) GetSupportedCurrencies() []Currency {
+	return []Currency{USD, EUR, GBP, INR, PHP, MXN}
+}
+
+func (x *XoomProvider) GetSendingCountries() []string {
+	return []string{"US", "GB"}
+}
+
+func (x *XoomProvider) GetReceivingCountries() []string {
+	return []string{"IN", "PH", "MX"}
+}
+
+var xoomRoutes = routeTable(
+	[]Currency{USD, EUR, GBP},
+	map[string][]Currency{
+		"IN": {INR},
+		"PH": {PHP},
+		"MX": {MXN, USD},
+	},
+)
+
+func (x *XoomProvider) GetSupportedRoutes() []Route {
+	return xoomRoutes
+}
+
+func (x *XoomProvider) SupportsCorridor(from, to Currency, country string) bool {
+	return hasRoute(xoomRoutes, from, to, country)
+}
+
+func (x *XoomProvider) GetCapabilities() Capabilities {
+	return Capabilities{
+		ScheduledTransfers: true,
+		RecurringTransfers: true,
+		RateLock:           true,
+		FeeModels:          []FeePaidBy{FeePaidBySender, FeePaidByRecipient},
+		PromoCodes:         true,
+		PayoutNetworks:     xoomNetworks,
+		FundingMethods:     cardOrBank,
+		Eligibility:        xoomEligibility,
+	}
+}
+
+// tokens lazily builds the client-credentials source so a BaseURL changed
+// after construction (e.g. pointing at the sandbox) is honoured.
+func (x *XoomProvider) tokens() TokenSource {
+	x.tokenOnce.Do(func() {
+		if x.TokenSource == nil {
+			x.TokenSource = ReuseTokenSource(&ClientCredentialsTokenSource{
+				TokenURL:     x.BaseURL + "/v1/oauth2/token",
+				ClientID:     x.ClientID,
+				ClientSecret: x.ClientSecret,
+				HTTPClient:   x.client,
+				Identity:     x.Identity,
+			})
+		}
+	})
+	return x.TokenSource
+}
+
+// Use appends middleware run around every API call this provider makes
+func (x *XoomProvider) Use(middleware ...Middleware) {
+	x.middleware = append(x.middleware, middleware...)
+}
+
+func (x *XoomProvider) makeRequest(ctx context.Context, method, endpoint string, body interface{}) (*http.Response, error) {
+	var reqBody io.Reader
+	if body != nil {
+		jsonBody, err := json.Marshal(body)
+		if err != nil {
+			return nil, err
+		}
+		reqBody = bytes.NewBuffer(jsonBody)
+	}
+	return x.send(ctx, method, endpoint, reqBody, "application/json")
+}
+
+// send makes a request with a body already encoded as contentType
+func (x *XoomProvider) send(ctx context.Context, method, endpoint string, reqBody io.Reader, contentType string) (*http.Response, error) {
+	ctx = withProviderName(ctx, x.GetName())
+	token, err := x.tokens().Token(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("xoom token: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, x.BaseURL+endpoint, reqBody)
+	if err != nil {
+		return nil, err
+	}
+
+	x.Identity.apply(req)
+	req.Header.Set("Authorization", token.AuthorizationHeader())
+	req.Header.Set("Content-Type", contentType)
+	setCorrelationID(req)
+
+	resp, err := chainMiddleware(x.client.Do, x.middleware)(req)
+	if err != nil {
+		return nil, redactError(err)
+	}
+	// PayPal's {"name","message"} errors become *APIError, with the token
+	// and client secret masked should either be echoed back
+	if err := checkResponse(x.GetName(), resp, token.AccessToken, x.ClientSecret); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// decodeXoomResponse decodes a JSON body. send has already turned non-2xx
+// responses into *APIError.
+func decodeXoomResponse(resp *http.Response, v interface{}) error {
+	return json.NewDecoder(resp.Body).Decode(v)
+}
+
+type xoomQuote struct {
+	QuoteID         string     `json:"quote_id"`
+	SendAmount      float64    `json:"send_amount"`
+	Fee             float64    `json:"fee"`
+	FundingFee      float64    `json:"funding_fee"`
+	ExchangeRate    float64    `json:"exchange_rate"`
+	ReceiveAmount   float64    `json:"receive_amount"`
+	ExpiresAt       string     `json:"expires_at"`
+	Warnings        []string   `json:"warnings"`
+	RequiredActions []string   `json:"required_actions"`
+	Promotion       *promotion `json:"promotion"`
+}
+
+func (x *XoomProvider) GetQuote(ctx context.Context, req TransactionRequest) (*RemittanceQuote, error) {
+	quoteReq := map[string]interface{}{
+		"source_currency":      req.FromCurrency,
+		"destination_currency": req.ToCurrency,
+		"destination_country":  req.Recipient.Address.CountryCode,
+		"send_amount":          req.Amount,
+		"payment_method":       req.PaymentMethod,
+		"funding_source":       xoomFundingSource(req),
+		"fee_paid_by":          xoomFeePaidBy(req.FeePaidBy),
+	}
+	if req.PromoCode != "" {
+		quoteReq["promo_code"] = req.PromoCode
+	}
+	if req.PayoutNetwork != "" {
+		quoteReq["payout_network"] = req.PayoutNetwork
+	}
+
+	resp, err := x.makeRequest(ctx, "POST", "/v1/remittances/quotes", quoteReq)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	var quoteResp xoomQuote
+	if err := decodeXoomResponse(resp, &quoteResp); err != nil {
+		return nil, fmt.Errorf("xoom quote: %w", err)
+	}
+	quote := &RemittanceQuote{
+		Provider:        x.GetName(),
+		QuoteID:         quoteResp.QuoteID,
+		Amount:          req.Amount,
+		Fee:             quoteResp.Fee,
+		FundingFee:      quoteResp.FundingFee,
+		ExchangeRate:    quoteResp.ExchangeRate,
+		ReceivedAmount:  quoteResp.ReceiveAmount,
+		FeePaidBy:       FeePaidBySender,
+		EstimatedTime:   "Minutes to hours",
+		EstimatedMin:    time.Minute,
+		EstimatedMax:    24 * time.Hour,
+		Warnings:        quoteResp.Warnings,
+		RequiredActions: quoteResp.RequiredActions,
+	}
+	quote.setExpiry(parseExpiry(quoteResp.ExpiresAt), xoomQuoteValidity)
+	applyPromotion(quote, req.PromoCode, quoteResp.Promotion)
+
+	// Xoom deducts a recipient-paid fee before converting
+	quote.TotalCost = req.Amount + quote.Fee
+	if xoomFeePaidBy(req.FeePaidBy) == "RECIPIENT" {
+		quote.TotalCost, quote.FeePaidBy = req.Amount, FeePaidByRecipient
+	}
+	if quote.ExchangeRate == 0 && !quote.fillImpliedRate() {
+		return nil, fmt.Errorf("xoom quote: no exchange_rate, and none implied by receive_amount %v and fee %v", quoteResp.ReceiveAmount, quoteResp.Fee)
+	}
+	roundCost(quote, req.FromCurrency)
+	return quote, nil
+}
+
+// LockQuote extends a quote's validity so the rate shown is the rate sent
+func (x *XoomProvider) LockQuote(ctx context.Context, quote *RemittanceQuote) (LockedQuote, error) {
+	resp, err := x.makeRequest(ctx, "POST", "/v1/remittances/quotes/"+url.PathEscape(quote.QuoteID)+"/lock", nil)
+	if err != nil {
+		return LockedQuote{}, err
+	}
+	defer resp.Body.Close()
+
+	var lockResp struct {
+		LockToken   string `json:"lock_token"`
+		LockedUntil string `json:"locked_until"`
+	}
+	if err := decodeXoomResponse(resp, &lockResp); err != nil {
+		return LockedQuote{}, fmt.Errorf("xoom lock quote: %w", err)
+	}
+	lockedUntil, err := time.Parse(time.RFC3339, lockResp.LockedUntil)
+	if err != nil {
+		return LockedQuote{}, fmt.Errorf("xoom lock quote: bad locked_until %q", lockResp.LockedUntil)
+	}
+
+	return LockedQuote{Quote: quote, LockToken: lockResp.LockToken, LockedUntil: lockedUntil}, nil
+}
+
+type xoomTransfer struct {
+	ID              string   `json:"id"`
+	Status          string   `json:"status"`
+	SendAmount      float64  `json:"send_amount"`
+	Fee             float64  `json:"fee"`
+	FundingFee      float64  `json:"funding_fee"`
+	ExchangeRate    float64  `json:"exchange_rate"`
+	FailureReason   string   `json:"failure_reason"`
+	ScheduledDate   string   `json:"scheduled_date"`
+	IdempotencyKey  string   `json:"idempotency_key"`
+	Warnings        []string `json:"warnings"`
+	RequiredActions []string `json:"required_actions"`
+	// RequiredDocuments is set on transfers held with status
+	// DOCUMENTS_REQUIRED
+	RequiredDocuments []string `json:"required_documents"`
+}
+
+// toResponse maps a Xoom transfer onto the common response shape
+func (t xoomTransfer) toResponse() *TransactionResponse {
+	resp := &TransactionResponse{
+		TransactionID:   t.ID,
+		Status:          mapXoomStatus(t.Status),
+		Amount:          t.SendAmount,
+		Fee:             t.Fee,
+		FundingFee:      t.FundingFee,
+		ExchangeRate:    t.ExchangeRate,
+		EstimatedTime:   "Minutes to hours",
+		TrackingURL:     fmt.Sprintf("https://www.xoom.com/track/%s", t.ID),
+		Warnings:        t.Warnings,
+		RequiredActions: t.RequiredActions,
+		Progress:        trackingProgress(xoomTrackingSteps, t.Status),
+	}
+	resp.Error = failureReason(resp.Status, t.FailureReason)
+	resp.requireDocuments(documentTypes(t.RequiredDocuments))
+	if scheduled, err := time.Parse(time.RFC3339, t.ScheduledDate); err == nil {
+		resp.ScheduledFor = &scheduled
+	}
+	return resp
+}
+
+// xoomTransferBody builds the create-transfer payload shared by SendMoney
+// and SendMoneyBatch
+func xoomTransferBody(req TransactionRequest) map[string]interface{} {
+	transferReq := map[string]interface{}{
+		"recipient_id":         req.Recipient.ID,
+		"source_currency":      req.FromCurrency,
+		"destination_currency": req.ToCurrency,
+		"send_amount":          req.Amount,
+		"payment_method":       req.PaymentMethod,
+		"funding_source":       xoomFundingSource(req),
+		"purpose":              req.purpose(),
+		"reference":            req.Reference,
+		"idempotency_key":      req.idempotencyKey(),
+		"fee_paid_by":          xoomFeePaidBy(req.FeePaidBy),
+	}
+	if req.PromoCode != "" {
+		transferReq["promo_code"] = req.PromoCode
+	}
+	if req.PayoutNetwork != "" {
+		transferReq["payout_network"] = req.PayoutNetwork
+	}
+	if len(req.ComplianceInfo) > 0 {
+		transferReq["compliance_info"] = req.ComplianceInfo
+	}
+	if req.ScheduledFor != nil {
+		transferReq["scheduled_date"] = req.ScheduledFor.UTC().Format(time.RFC3339)
+	}
+	if lock := req.LockedQuote; lock != nil {
+		transferReq["quote_id"] = lock.Quote.QuoteID
+		transferReq["lock_token"] = lock.LockToken
+	} else if req.Quote != nil && req.Quote.QuoteID != "" {
+		transferReq["quote_id"] = req.Quote.QuoteID
+	}
+	return transferReq
+}
+
+func (x *XoomProvider) SendMoney(ctx context.Context, req TransactionRequest) (*TransactionResponse, error) {
+	resp, err := x.makeRequest(ctx, "POST", "/v1/remittances/transfers", xoomTransferBody(req))
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	var transfer xoomTransfer
+	if err := decodeXoomResponse(resp, &transfer); err != nil {
+		return nil, fmt.Errorf("xoom transfer: %w", err)
+	}
+
+	return transfer.toResponse(), nil
+}
+
+// SendMoneyBatch creates many transfers in one call to Xoom's batch
+// endpoint. Results line up with reqs; items Xoom rejected are nil and
+// listed in the returned *BatchError.
+func (x *XoomProvider) SendMoneyBatch(ctx context.Context, reqs []TransactionRequest) ([]*TransactionResponse, error) {
+	transfers := make([]map[string]interface{}, len(reqs))
+	for i, req := range reqs {
+		transfers[i] = xoomTransferBody(req)
+	}
+
+	resp, err := x.makeRequest(ctx, "POST", "/v1/remittances/transfers/batch", map[string]interface{}{"transfers": transfers})
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	var batch struct {
+		Results []struct {
+			Transfer *xoomTransfer `json:"transfer"`
+			Error    *struct {
+				Name    string `json:"name"`
+				Message string `json:"message"`
+			} `json:"error"`
+		} `json:"results"`
+	}
+	if err := decodeXoomResponse(resp, &batch); err != nil {
+		return nil, fmt.Errorf("xoom batch transfer: %w", err)
+	}
+	if len(batch.Results) != len(reqs) {
+		return nil, fmt.Errorf("xoom batch transfer: got %d results for %d transfers", len(batch.Results), len(reqs))
+	}
+
+	responses := make([]*TransactionResponse, len(reqs))
+	var failed []BatchItemError
+	for i, result := range batch.Results {
+		switch {
+		case result.Error != nil:
+			failed = append(failed, BatchItemError{
+				Index:     i,
+				Reference: reqs[i].Reference,
+				Err:       fmt.Errorf("xoom: %s: %s", result.Error.Name, result.Error.Message),
+			})
+		case result.Transfer != nil:
+			responses[i] = result.Transfer.toResponse()
+		default:
+			failed = append(failed, BatchItemError{Index: i, Reference: reqs[i].Reference, Err: errors.New("xoom: empty batch result")})
+		}
+	}
+	if len(failed) > 0 {
+		return responses, &BatchError{Total: len(reqs), Failed: failed}
+	}
+	return responses, nil
+}
+
+func (x *XoomProvider) GetTransactionStatus(ctx context.Context, transactionID string) (*TransactionResponse, error) {
+	resp, err := x.makeRequest(ctx, "GET", "/v1/remittances/transfers/"+url.PathEscape(transactionID), nil)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	var transfer xoomTransfer
+	if err := decodeXoomResponse(resp, &transfer); err != nil {
+		return nil, fmt.Errorf("xoom status: %w", err)
+	}
+	if transfer.ID == "" {
+		transfer.ID = transactionID
+	}
+
+	return transfer.toResponse(), nil
+}
+
+// GetScheduledTransfers lists transfers Xoom is holding for a future date
+func (x *XoomProvider) GetScheduledTransfers(ctx context.Context) ([]*TransactionResponse, error) {
+	resp, err := x.makeRequest(ctx, "GET", "/v1/remittances/transfers?status=SCHEDULED", nil)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	var list struct {
+		Transfers []xoomTransfer `json:"transfers"`
+	}
+	if err := decodeXoomResponse(resp, &list); err != nil {
+		return nil, fmt.Errorf("xoom scheduled transfers: %w", err)
+	}
+
+	scheduled := make([]*TransactionResponse, 0, len(list.Transfers))
+	for _, t := range list.Transfers {
+		scheduled = append(scheduled, t.toResponse())
+	}
+	return scheduled, nil
+}
+
+// CancelScheduledTransfer cancels a scheduled transfer; Xoom rejects the
+// call once the transfer has started executing.
+func (x *XoomProvider) CancelScheduledTransfer(ctx context.Context, transactionID string) error {
+	resp, err := x.makeRequest(ctx, "POST", "/v1/remittances/transfers/"+url.PathEscape(transactionID)+"/cancel", nil)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	var transfer xoomTransfer
+	if err := decodeXoomResponse(resp, &transfer); err != nil {
+		return fmt.Errorf("xoom cancel: %w", err)
+	}
+	return nil
+}
+
+// RefundTransaction starts a refund of a returned or failed transfer
+func (x *XoomProvider) RefundTransaction(ctx context.Context, transactionID, reason string) (*TransactionResponse, error) {
+	body := map[string]interface{}{"reason": reason}
+	resp, err := x.makeRequest(ctx, "POST", "/v1/remittances/transfers/"+url.PathEscape(transactionID)+"/refund", body)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	var transfer xoomTransfer
+	if err := decodeXoomResponse(resp, &transfer); err != nil {
+		return nil, fmt.Errorf("xoom refund: %w", err)
+	}
+	return transfer.toResponse(), nil
+}
+
+// FindTransactionByIdempotencyKey looks up a transfer by the key it was
+// created with
+func (x *XoomProvider) FindTransactionByIdempotencyKey(ctx context.Context, key string) (*TransactionResponse, error) {
+	if err := checkIdempotencyKey(key); err != nil {
+		return nil, err
+	}
+	resp, err := x.makeRequest(ctx, "GET", "/v1/remittances/transfers?idempotency_key="+url.QueryEscape(key), nil)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	var list struct {
+		Transfers []xoomTransfer `json:"transfers"`
+	}
+	if err := decodeXoomResponse(resp, &list); err != nil {
+		return nil, fmt.Errorf("xoom transfer lookup: %w", err)
+	}
+	// The filter is only trusted as far as the key each transfer carries:
+	// matching another transfer would skip a retry that never went out
+	for _, transfer := range list.Transfers {
+		if transfer.IdempotencyKey == key {
+			return transfer.toResponse(), nil
+		}
+	}
+	return nil, ErrTransactionNotFound
+}
+
+// GetTransactionHistory lists transfers using Xoom's cursor token, which
+// is passed through as the opaque cursor
+func (x *XoomProvider) GetTransactionHistory(ctx context.Context, opts HistoryOptions) (*HistoryPage, error) {
+	query := url.Values{"limit": {strconv.Itoa(opts.limit())}}
+	if opts.Cursor != "" {
+		query.Set("cursor", opts.Cursor)
+	}
+	if !opts.Since.IsZero() {
+		query.Set("created_after", opts.Since.UTC().Format(time.RFC3339))
+	}
+	if !opts.Until.IsZero() {
+		query.Set("created_before", opts.Until.UTC().Format(time.RFC3339))
+	}
+
+	resp, err := x.makeRequest(ctx, "GET", "/v1/remittances/transfers?"+query.Encode(), nil)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	var list struct {
+		Transfers  []xoomTransfer `json:"transfers"`
+		NextCursor string         `json:"next_cursor"`
+	}
+	if err := decodeXoomResponse(resp, &list); err != nil {
+		return nil, fmt.Errorf("xoom transfer history: %w", err)
+	}
+
+	page := &HistoryPage{NextCursor: list.NextCursor}
+	for _, t := range list.Transfers {
+		page.Transactions = append(page.Transactions, t.toResponse())
+	}
+	return page, nil
+}
+
+// xoomFeePaidBy maps a fee model onto Xoom's; it has no shared option, so
+// shared fees fall back to the sender
+func xoomFeePaidBy(paidBy FeePaidBy) string {
+	if paidBy == FeePaidByRecipient {
+		return "RECIPIENT"
+	}
+	return "SENDER"
+}
+
+var xoomEventCodes = map[string]TransactionEventType{
+	"created":         EventCreated,
+	"scheduled":       EventCreated,
+	"funds_captured":  EventFundsReceived,
+	"processing":      EventProcessing,
+	"ready_to_pickup": EventProcessing,
+	"paid_out":        EventPaidOut,
+	"completed":       EventPaidOut,
+	"failed":          EventFailed,
+	"denied":          EventFailed,
+	"cancelled":       EventCancelled,
+	"refunded":        EventRefunded,
+}
+
+func (x *XoomProvider) GetTransactionEvents(ctx context.Context, transactionID string) ([]TransactionEvent, error) {
+	resp, err := x.makeRequest(ctx, "GET", "/v1/remittances/transfers/"+url.PathEscape(transactionID)+"/events", nil)
+	if err != nil {
+		if isNotFound(err) {
+			return nil, fmt.Errorf("xoom transfer %s: %w", transactionID, ErrTransactionNotFound)
+		}
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	var feed struct {
+		Events []struct {
+			EventCode   string    `json:"event_code"`
+			EventTime   time.Time `json:"event_time"`
+			Description string    `json:"description"`
+		} `json:"events"`
+	}
+	if err := decodeXoomResponse(resp, &feed); err != nil {
+		return nil, fmt.Errorf("xoom events: %w", err)
+	}
+
+	events := make([]TransactionEvent, 0, len(feed.Events))
+	for _, e := range feed.Events {
+		events = append(events, newEvent(xoomEventCodes, e.EventCode, e.EventTime, e.Description))
+	}
+	return events, nil
+}
+
+// UploadComplianceDocument attaches doc to a transfer Xoom is holding with
+// status DOCUMENTS_REQUIRED
+func (x *XoomProvider) UploadComplianceDocument(ctx context.Context, transactionID string, doc Document) error {
+	body, contentType, err := documentForm(doc)
+	if err != nil {
+		return err
+	}
+	resp, err := x.send(ctx, "POST", "/v1/remittances/transfers/"+url.PathEscape(transactionID)+"/documents", body, contentType)
+	if err != nil {
+		if isNotFound(err) {
+			return fmt.Errorf("xoom transfer %s: %w", transactionID, ErrTransactionNotFound)
+		}
+		return err
+	}
+	defer resp.Body.Close()
+
+	// The body, if any, only echoes the document back
+	var uploaded struct{}
+	if err := decodeXoomResponse(resp, &uploaded); err != nil && !errors.Is(err, io.EOF) {
+		return fmt.Errorf("xoom document upload: %w", err)
+	}
+	return nil
+}
+
+func mapXoomStatus(status string) TransactionStatus {
+	// SCHEDULED and in-progress states all map to pending
+	switch strings.ToUpper(status) {
+	case "COMPLETED", "PAID_OUT":
+		return StatusCompleted
+	case "FAILED", "DENIED":
+		return StatusFailed
+	case "RETURNED", "REFUND_PENDING":
+		return StatusRefundPending
+	case "REFUNDED":
+		return StatusRefunded
+	case "CANCELLED", "CANCELED":
+		return StatusCancelled
+	}
+	return StatusPending
+}
+
+func (x *XoomProvider) GetExchangeRates(ctx context.Context, from, to Currency) (*ExchangeRate, error) {
+	endpoint := fmt.Sprintf("/v1/remittances/rates?source_currency=%s&destination_currency=%s", from, to)
+	resp, err := x.makeRequest(ctx, "GET", endpoint, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	var rateResp struct {
+		Rate      float64 `json:"rate"`
+		Fee       float64 `json:"fee"`
+		ExpiresAt string  `json:"expires_at"`
+	}
+	if err := decodeXoomResponse(resp, &rateResp); err != nil {
+		return nil, fmt.Errorf("xoom rates: %w", err)
+	}
+	if rateResp.Rate == 0 {
+		return nil, errNoExchangeRate
+	}
+
+	return &ExchangeRate{
+		From:       from,
+		To:         to,
+		Rate:       rateResp.Rate,
+		Fee:        rateResp.Fee,
+		ValidUntil: expiresAt(parseExpiry(rateResp.ExpiresAt), xoomQuoteValidity),
+	}, nil
+}
+
+func (x *XoomProvider) GetExchangeRatesBatch(ctx context.Context, pairs []CurrencyPair) (map[CurrencyPair]*ExchangeRate, error) {
+	return fetchRatesConcurrently(ctx, x, pairs)
+}
+
+var xoomCorridorLimits = map[Route]corridorLimit{
+	{From: USD, To: INR}: {min: 10, max: 50000},
+	{From: USD, To: PHP}: {min: 10, max: 25000},
+	{From: USD, To: MXN}: {min: 10, max: 10000},
+	{From: EUR, To: INR}: {min: 10, max: 10000},
+	{From: EUR, To: PHP}: {min: 10, max: 10000},
+	{From: EUR, To: MXN}: {min: 10, max: 5000},
+	{From: EUR, To: USD}: {min: 10, max: 5000},
+	{From: GBP, To: INR}: {min: 10, max: 8000},
+	{From: GBP, To: PHP}: {min: 10, max: 8000},
+	{From: GBP, To: MXN}: {min: 10, max: 5000},
+	{From: GBP, To: USD}: {min: 10, max: 5000},
+}
+
+func (x *XoomProvider) GetCorridorLimits(ctx context.Context, from, to Currency, country string) (min, max float64, err error) {
+	return lookupCorridorLimit(x.GetName(), xoomCorridorLimits, from, to, country)
+}
+
+type xoomRecurring struct {
+	ID            string  `json:"id"`
+	Frequency     string  `json:"frequency"`
+	StartDate     string  `json:"start_date"`
+	EndDate       string  `json:"end_date,omitempty"`
+	Occurrences   int     `json:"occurrences,omitempty"`
+	SendAmount    float64 `json:"send_amount"`
+	SourceCcy     string  `json:"source_currency"`
+	DestCcy       string  `json:"destination_currency"`
+	RecipientID   string  `json:"recipient_id"`
+	NextExecution string  `json:"next_execution_date"`
+	Status        string  `json:"status"`
+}
+
+func (r xoomRecurring) toRecurringTransfer() *RecurringTransfer {
+	rt := &RecurringTransfer{
+		ID:       r.ID,
+		Provider: "Xoom",
+		Schedule: RecurringSchedule{
+			Frequency:   Frequency(strings.ToUpper(r.Frequency)),
+			Occurrences: r.Occurrences,
+		},
+		Amount:       r.SendAmount,
+		FromCurrency: Currency(r.SourceCcy),
+		ToCurrency:   Currency(r.DestCcy),
+		RecipientID:  r.RecipientID,
+		Active:       strings.EqualFold(r.Status, "ACTIVE"),
+	}
+	rt.Schedule.StartDate, _ = time.Parse(time.RFC3339, r.StartDate)
+	rt.Schedule.EndDate, _ = time.Parse(time.RFC3339, r.EndDate)
+	rt.NextExecution, _ = time.Parse(time.RFC3339, r.NextExecution)
+	return rt
+}
+
+func (x *XoomProvider) CreateRecurringTransfer(ctx context.Context, req TransactionRequest, schedule RecurringSchedule) (*RecurringTransfer, error) {
+	body := xoomRecurring{
+		Frequency:   string(schedule.Frequency),
+		StartDate:   schedule.StartDate.UTC().Format(time.RFC3339),
+		Occurrences: schedule.Occurrences,
+		SendAmount:  req.Amount,
+		SourceCcy:   string(req.FromCurrency),
+		DestCcy:     string(req.ToCurrency),
+		RecipientID: req.Recipient.ID,
+	}
+	if !schedule.EndDate.IsZero() {
+		body.EndDate = schedule.EndDate.UTC().Format(time.RFC3339)
+	}
+
+	resp, err := x.makeRequest(ctx, "POST", "/v1/remittances/recurring-transfers", body)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	var created xoomRecurring
+	if err := decodeXoomResponse(resp, &created); err != nil {
+		return nil, fmt.Errorf("xoom recurring transfer: %w", err)
+	}
+	return created.toRecurringTransfer(), nil
+}
+
+func (x *XoomProvider) ListRecurringTransfers(ctx context.Context) ([]*RecurringTransfer, error) {
+	resp, err := x.makeRequest(ctx, "GET", "/v1/remittances/recurring-transfers", nil)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	var list struct {
+		RecurringTransfers []xoomRecurring `json:"recurring_transfers"`
+	}
+	if err := decodeXoomResponse(resp, &list); err != nil {
+		return nil, fmt.Errorf("xoom recurring transfers: %w", err)
+	}
+
+	transfers := make([]*RecurringTransfer, 0, len(list.RecurringTransfers))
+	for _, r := range list.RecurringTransfers {
+		transfers = append(transfers, r.toRecurringTransfer())
+	}
+	return transfers, nil
+}
+
+func (x *XoomProvider) CancelRecurringTransfer(ctx context.Context, recurringID string) error {
+	resp, err := x.makeRequest(ctx, "DELETE", "/v1/remittances/recurring-transfers/"+url.PathEscape(recurringID), nil)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNoContent {
+		return nil
+	}
+	var cancelled xoomRecurring
+	if err := decodeXoomResponse(resp, &cancelled); err != nil {
+		return fmt.Errorf("xoom cancel recurring transfer: %w", err)
+	}
+	return nil
+}